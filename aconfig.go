@@ -1,23 +1,94 @@
 package aconfig
 
 import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"reflect"
+	"sort"
 	"strings"
+	"sync"
+	"text/template"
+	"time"
 )
 
 // Loader of user configuration.
 type Loader struct {
 	config  Config
+	base    Config // base is config as it was given by the user.
 	dst     any
 	parser  *structParser
 	fields  []*fieldData
 	fsys    fs.FS
+	urls    *urlFS
+	stdin   *stdinFS
 	flagSet *flag.FlagSet
 	errInit error
+	dupls   []Duplicate
+
+	// versionFlag is set when -version flag is added. See Config.Version.
+	versionFlag *bool
+
+	// printConfigFlag is set when Config.PrintConfigFlag is added.
+	printConfigFlag *bool
+
+	// envIndex maps env names to the indexes of allFields. See indexEnvs.
+	envIndex map[string][]int
+	envDupl  string // first env name shared by several fields
+
+	// unknownKeys are keys from files that match no field. See Lint.
+	unknownKeys []fileKey
+
+	// inputs consumed by the last Load. See Inputs.
+	inputs Inputs
+
+	loadedFiles  []string
+	missingFiles []string
+
+	// fileFields are names of the fields found in the loaded files. See Drift.
+	fileFields map[string]struct{}
+
+	// factoryData is raw data for interface fields with factories. See applyFactories.
+	factoryData map[string]any
+
+	// added are sources added with AddSource.
+	added []addedSource
+
+	// leases are fields with values that expire. See Lease.
+	leases []leasedField
+
+	// holder gets configurations on loads and reloads. See Value.
+	holder valueHolder
+
+	// subtree is a prefix of the keys to load. See LoadInto.
+	subtree string
+
+	// timings and loadDuration of the last Load. See Report.
+	timings      []SourceTiming
+	loadDuration time.Duration
+
+	// mu guards dst during reloads.
+	mu sync.Mutex
+
+	// stateMu guards fields, flags and results of the last Load,
+	// so Load can run concurrently with Flags, WalkFields and other readers.
+	stateMu sync.RWMutex
+
+	// swapMu guards copying of a reloaded configuration into dst. See View.
+	swapMu sync.RWMutex
+}
+
+type fileKey struct {
+	file string
+	key  string
 }
 
 // Config to configure configuration loader.
@@ -25,6 +96,7 @@ type Config struct {
 	// NewParser set to true enables a new and better struct parser.
 	// Default is false because there might be bugs.
 	// In the future new parser will be enabled by default.
+	// Use CompareParsers to find differences before switching.
 	NewParser bool
 
 	SkipDefaults bool // SkipDefaults set to true will not load config from 'default' tag.
@@ -47,6 +119,9 @@ type Config struct {
 	AllFieldRequired bool
 
 	// AllowDuplicates set to true will not fail on duplicated names on fields (env, flag, etc...)
+	// Every field that shares a name receives the same value from env, flag or file.
+	// Flag is defined once with default value and usage of the first declared field.
+	// See Loader.Duplicates to find out which fields share names.
 	AllowDuplicates bool
 
 	// AllowUnknownFields set to true will not fail on unknown fields in files.
@@ -60,26 +135,187 @@ type Config struct {
 	// When false error is returned only when FlagPrefix isn't empty.
 	AllowUnknownFlags bool
 
+	// CheckRequiredFlags set to true checks required fields that only a flag can set
+	// (no env, file key or default) right after flags are parsed.
+	// When such flags are missing usage is printed and Load fails before other sources are loaded.
+	CheckRequiredFlags bool
+
+	// Version of the application. When set -version flag is added (unless a field has such flag),
+	// passing it prints Version and Load returns ErrHelp.
+	Version string
+
 	// DontGenerateTags disables tag generation for JSON, YAML, TOML file formats.
 	DontGenerateTags bool
 
+	// WordSplit configures how field names are split into words for generated names,
+	// like acronyms, digits and initialisms handling. Default splits by case changes and digits.
+	WordSplit WordSplit
+
+	// FlagStyle is a casing of generated flag names: snake_case (default), kebab-case or lowerCamel.
+	// Flag tags and Config.FlagDelimiter between nested names are used as is.
+	FlagStyle FlagStyle
+
 	// FailOnFileNotFound will stop Loader on a first not found file from Files field in this structure.
 	FailOnFileNotFound bool
 
+	// Factories create values for interface fields with `factory` tag, keyed by the interface type.
+	// Tag value is a name of a sibling field which value is passed as kind to the factory.
+	// Factory returns a pointer to a concrete value, the data loaded for the field is decoded into it.
+	// Example: for `Storage Storage` field with `factory:"Driver"` tag a factory for Storage type
+	// gets value of Driver field like "postgres" and data for Storage is decoded into the result.
+	Factories map[reflect.Type]func(kind string) (any, error)
+
+	// FieldValidator is called for every field after all the sources are applied.
+	// Value is the final value of the field, error is returned from Load.
+	FieldValidator func(f Field, value any) error
+
+	// OnFieldSet is called every time a field is assigned by a source.
+	// Source is "default", "file", "source", "env" or "flag", raw is the value as it came from the source.
+	// Called only for values that count as provided, see `aconfig:",allowempty"` tag.
+	OnFieldSet func(f Field, source string, raw any)
+
+	// FailOnUnexportedFields set to true will fail on init when an unexported field has tags
+	// used by the loader (default, usage, required, env, flag, aconfig or a file format).
+	// Such fields are never populated, so tags on them are most likely a mistake.
+	FailOnUnexportedFields bool
+
 	// FileSystem from which files will be loaded. Default is nil (OS file system).
 	FileSystem fs.FS
 
+	// EmbeddedDefaults is a file system with a default config shipped inside the binary (see go:embed).
+	// File EmbeddedDefaultsFile from it is always loaded after 'default' tags and before other files.
+	// Fields set from this file are treated as set by defaults: the source kind is "default",
+	// the file isn't in LoadedFiles and Drift. Skipped if SkipDefaults is set.
+	EmbeddedDefaults fs.FS
+
+	// EmbeddedDefaultsFile is a path to the file in EmbeddedDefaults.
+	EmbeddedDefaultsFile string
+
+	// EmbeddedDefaultsFirst set to true loads EmbeddedDefaultsFile before 'default' tags,
+	// so the tags overwrite values from the file.
+	EmbeddedDefaultsFirst bool
+
 	// MergeFiles set to true will collect all the entries from all the given files.
 	// Easy wat to cobine base.yaml with prod.yaml
+	//
+	// Map fields set by several files are merged recursively, so prod.yaml that overrides
+	// only one key of a map keeps other keys from base.yaml.
 	MergeFiles bool
 
+	// ExpandEnvInFiles set to true expands environment variables in string values of files:
+	// $VAR, ${VAR} and ${VAR:-default}, where default is used when VAR is unset or empty.
+	// Use $$ for a literal $. Variables are taken from Envs.
+	ExpandEnvInFiles bool
+
+	// Decrypt is called for string values in files and sources that start with "enc:v1:",
+	// like "enc:v1:c2VjcmV0". It gets the value without the prefix and returns the plaintext
+	// which is set into the field. Usually it calls a KMS. Encrypted values are left as is when nil.
+	// Loader.Inputs keep encrypted values.
+	Decrypt func(ciphertext string) (string, error)
+
+	// Profile selects a named profile in config files. A file can have a top-level "profiles" key
+	// with a subtree per profile, the selected subtree is merged over the rest of the file:
+	//
+	//	port: 8080
+	//	profiles:
+	//	  prod:
+	//	    port: 80
+	//
+	// The "profiles" key itself is never loaded into fields. When Profile is set,
+	// a file that has "profiles" without the selected one is an error.
+	//
+	// Also each of Files, FileEntries and a file from FileFlag has an optional overlay for the profile:
+	// config.prod.yaml is merged over config.yaml when it exists, even if MergeFiles isn't set.
+	Profile string
+
+	// ProfileEnv is the name of an env var that selects the profile, like "APP_PROFILE".
+	// Overrides Profile when the env var isn't empty.
+	ProfileEnv string
+
+	// ProfileFlag is the name of a flag that selects the profile, like "profile".
+	// Overrides ProfileEnv and Profile when the flag is passed.
+	ProfileFlag string
+
+	// Environment selects per-environment defaults: with Environment "prod"
+	// a field with `default:"10" default.prod:"100"` tags has 100 as the default value.
+	// Fields without `default.<env>` tag for the environment use `default` tag.
+	Environment string
+
+	// Vars are variables for conditions in config files. A file section (an object) with "when" key
+	// is loaded only when the condition is true. Top-level "overrides" list holds sections
+	// which are merged over the rest of the file in order when their condition is true:
+	//
+	//	log_level: info
+	//	overrides:
+	//	  - when: env == "prod" && region != "eu"
+	//	    log_level: warn
+	//
+	// Conditions compare variables with values using == and !=, combined with && and ||.
+	// Unknown variable is an error. Conditions are evaluated only if Vars isn't nil.
+	Vars map[string]string
+
 	// FileFlag the name of the flag that defines the path to the configuration file passed through the CLI.
 	// (To make it easier to transfer the config file via flags.)
 	FileFlag string
 
+	// SetFlag is the name of a repeated flag that sets any field, like "set":
+	// -set server.port=9090 -set db.user_name=admin. Keys are field paths (case-insensitive)
+	// or their snake_case form, slices and maps are set like with repeated flags: tags=a,b or labels=k=v.
+	// Values are applied after all the other sources.
+	SetFlag string
+
+	// PrintConfigFlag is the name of a bool flag that prints the loaded configuration and exits, like "print-config".
+	// Every field is printed with its value and source, values of fields with `secret:"true"` tag are redacted.
+	// Load returns ErrHelp after printing, so the application can exit with code 0.
+	PrintConfigFlag string
+
+	// StrictReport set to true fails Load when Loader.Report has warnings,
+	// like missing files, names shared by several fields or likely typos in env vars and file keys.
+	StrictReport bool
+
+	// OverrideFile is a file with overrides written by tooling, like a control plane or an operator.
+	// It's applied after all the other sources, flags included, so its values always win.
+	// The file is optional: a missing or an empty file is ignored, as well as keys that match no field.
+	// Loader.Watch reloads the configuration when the file changes.
+	OverrideFile string
+
 	// Files from which config should be loaded.
+	// File "-" (also as a FileFlag value) is read from Stdin, see StdinFormat.
 	Files []string
 
+	// StdinFormat is a format of the config read from stdin, like "yaml" or ".yaml".
+	// Decoder for it must be registered in FileDecoders. Default is JSON or detected with SniffFormat.
+	StdinFormat string
+
+	// SniffFormat set to true detects format of files without extension or with an unknown one
+	// by their content: JSON, YAML, TOML, INI, .properties or .env. Decoder for the format must be registered.
+	// Useful for files mounted without an extension like /etc/app/config.
+	SniffFormat bool
+
+	// Stdin is a reader for the "-" file. Default is os.Stdin.
+	Stdin io.Reader
+
+	// Dirs are directories with config files, like conf.d. Every file with a registered decoder
+	// is loaded in sorted order, nested directories included, and files are always merged.
+	// Hidden files and directories (starting with a dot) and backups (ending with ~) are skipped.
+	// Dirs are loaded after Files and FileEntries.
+	Dirs []string
+
+	// FileGroups are groups of files where only the first existing file of each group is loaded.
+	// Groups are loaded after Files, FileEntries and Dirs and are always merged with each other.
+	// Example: {{"config.local.yaml", "config.yaml"}, {"secrets.yaml"}}.
+	// With FailOnFileNotFound set the loader stops when no file in a group exists.
+	FileGroups [][]string
+
+	// FileEntries are files with their own file system, e.g. embed.FS with defaults and OS for overrides.
+	// Loaded after Files with the same rules (see MergeFiles and FailOnFileNotFound).
+	FileEntries []FileEntry
+
+	// Sources are custom configuration backends applied after files and before env vars.
+	// Sources are applied in order, so the last one wins.
+	// A source that implements FieldSource also sets fields referenced in its tag.
+	Sources []Source
+
 	// Envs hold the environment variable from which envs will be parsed.
 	// By default is nil and then os.Environ() will be used.
 	Envs []string
@@ -89,6 +325,60 @@ type Config struct {
 	// Unless loader.Flags() will be explicitly parsed by the user.
 	Args []string
 
+	// HTTPFiles configures loading of files from URLs: entries in Files (and a file from FileFlag)
+	// that start with http:// or https:// are downloaded on every load. 404 is treated as a missing file.
+	// Format is taken from the URL extension or from Content-Type of the response.
+	HTTPFiles HTTPFiles
+
+	// FileFetchers download files with URLs of other schemes in Files, keyed by the scheme:
+	// {"s3": aconfigs3.New(client)} loads s3://bucket/config.yaml. Format is taken from the URL extension
+	// or from the content type when the fetcher implements ContentTypeFetcher.
+	FileFetchers map[string]FileFetcher
+
+	// MIMEDecoders are decoders for files from URLs keyed by a media type, like "application/x-yaml".
+	// Used when the URL has no extension with a decoder in FileDecoders.
+	// Well-known JSON, YAML and TOML media types are decoded with FileDecoders by default.
+	MIMEDecoders map[string]FileDecoder
+
+	// Experimental enables experimental behaviors, like ExperimentDeepMerge|ExperimentTypedFlags.
+	// Default is none, so the loader behaves as before.
+	Experimental Experiment
+
+	// StreamFiles set to true decodes JSON files token by token without reading the whole file first.
+	// Decoded values are collected into a map before they are set to fields as usual,
+	// so memory for decoded values isn't saved. Only the built-in JSON decoder streams.
+	StreamFiles bool
+
+	// RecordInputs set to true keeps contents of loaded files for Loader.Inputs, so they can be replayed.
+	// Otherwise only hashes of the files are kept, they are enough for Loader.Fingerprint.
+	RecordInputs bool
+
+	// FileTemplates set to true executes files as text/template before decoding.
+	// Templates have "env" (like {{ env "HOME" }}) and "hostname" functions and TemplateFuncs.
+	// Template data is TemplateData or, when it's nil, a map with "Env" (map of Envs) and "Hostname".
+	// Missing keys in the data are errors. Loader.Inputs keep files as they are, before rendering.
+	FileTemplates bool
+
+	// TemplateFuncs are functions for file templates, they override the default ones. See FileTemplates.
+	TemplateFuncs template.FuncMap
+
+	// TemplateData is the data for file templates. See FileTemplates.
+	TemplateData any
+
+	// FileRoots confine file access to the given directories, files outside of them
+	// (also with "..", absolute paths or symbolic links) fail with ErrOutsideFileRoots.
+	// Applies to Config.FileSystem or OS files, not to FileEntry with its own FileSystem.
+	// Default is empty (no restrictions).
+	FileRoots []string
+
+	// MaxFileSize limits size of a config file in bytes, larger files fail the load.
+	// Applies to local files and URLs. Default is 0 (no limit).
+	MaxFileSize int64
+
+	// MaxDepth limits nesting of objects and arrays in a config file.
+	// Protects from maliciously deep documents in user-supplied files. Default is 0 (no limit).
+	MaxDepth int
+
 	// FileDecoders to enable other than JSON file formats and prevent additional dependencies.
 	// Add required submodules to the go.mod and register them in this field.
 	// Example:
@@ -101,15 +391,134 @@ type Config struct {
 
 	// SliceSeparator hold the separator for slice values. Default is ",".
 	SliceSeparator string
+
+	// SliceMerge is how slice fields are set when several sources have values for them:
+	// a later source replaces (default), appends to or unions with values of earlier sources,
+	// including the default. A field can have its own strategy with `merge:"append"` tag,
+	// `merge:"replace"` or `merge:"union"`.
+	SliceMerge SliceMerge
+
+	// Order of the source kinds, a later kind overrides values of an earlier one.
+	// Default is defaults, files, sources, env and flags (see SourceKind constants).
+	// Kinds that aren't listed are skipped like with Skip* params.
+	// Example: {SourceDefaults, SourceFiles, SourceFlags, SourceEnv} makes env win over flags.
+	Order []SourceKind
+
+	// WatchInterval is how often files are checked for changes by Loader.Watch. Default is 1 second.
+	WatchInterval time.Duration
+
+	// WatchBackoff is the delay before Loader.Watch watches a failed SourceWatcher again.
+	// The delay doubles after each failure in a row up to WatchMaxBackoff and is reset
+	// when the source notifies about a change. Retries stop when the context of Watch is done
+	// or its deadline is closer than the delay.
+	// Default is 0 and a failed source isn't watched anymore.
+	WatchBackoff time.Duration
+
+	// WatchMaxBackoff is the maximum delay between retries, see WatchBackoff. Default is 1 minute.
+	WatchMaxBackoff time.Duration
+
+	// OnWatchState is called by Loader.Watch when a watched source changes its state.
+	// err is set for WatchFailed state. Might be called concurrently for different sources.
+	OnWatchState func(source string, state WatchState, err error)
+
+	// OnRestartRequired is called after a reload by Loader.Watch or Loader.ReloadOn when fields with
+	// `reload:"false"` tag (or inside of a struct with it) have changed. New values of such fields aren't applied,
+	// they keep values from the previous load. Usually these are listen addresses, ports or storage paths.
+	OnRestartRequired func(fields []string)
+
+	// OnChange is called after a reload by Loader.Watch, Loader.ReloadOn or Loader.Reload
+	// and after a lease refresh with the fields that have changed, in the structure order.
+	// Values of fields with `secret:"true"` tag are redacted. Not called when nothing has changed.
+	OnChange func(changes []FieldChange)
+
+	// Comparers are equality functions for field types where reflect.DeepEqual is wrong or expensive,
+	// like *regexp.Regexp or *tls.Certificate. They're used to find changed fields on reloads.
+	// Both arguments have the key type, see Comparer for a typed helper.
+	Comparers map[reflect.Type]func(a, b any) bool
+
+	// Adapters parse and format values of types from other modules, like zapcore.Level.
+	// An adapter for T is also used for *T fields, a struct type with an adapter is set as a whole.
+	// See AdapterFor and submodules like aconfigzap.
+	Adapters map[reflect.Type]Adapter
+}
+
+// FileEntry is a file to load with a file system it should be loaded from. See Config.FileEntries.
+type FileEntry struct {
+	Path string
+
+	// FileSystem from which file will be loaded. Default is Config.FileSystem.
+	FileSystem fs.FS
 }
 
 // FileDecoder is used to read config from files. See aconfig submodules.
+// A decoder with `Init(fsys fs.FS)` method is copied before Init is called with a file system of the file,
+// so the same decoder can be used by several loaders concurrently.
 type FileDecoder interface {
 	Format() string
 	DecodeFile(filename string) (map[string]any, error)
 	// Init(fsys fs.FS)
 }
 
+// ReaderDecoder is an optional interface for FileDecoder to decode from a reader.
+// Loader prefers it over DecodeFile, so the decoder can be used for inputs that aren't files,
+// like HTTP bodies, stdin or embedded bytes.
+type ReaderDecoder interface {
+	DecodeReader(r io.Reader) (map[string]any, error)
+}
+
+// Source of configuration values like a database, an API or a secret store. See Config.Sources.
+// Load returns nested values like a file decoder does.
+// Keys are matched with the field names for JSON unless the source has a `Format() string` method
+// that returns a file format (like "yaml") which names should be used.
+// Source can have a `Name() string` method to be named in errors and in ValueSource.
+type Source interface {
+	Load(ctx context.Context) (map[string]any, error)
+}
+
+// FieldSource is implemented by sources that load values for single fields referenced in a tag,
+// like `secretsmanager:"name#key"`. LoadField is called after Load for every field with the tag.
+type FieldSource interface {
+	// FieldTag returns the name of the tag with references.
+	FieldTag() string
+
+	// LoadField returns the value for a reference from the tag.
+	LoadField(ctx context.Context, ref string) (any, error)
+}
+
+// SourceWatcher is implemented by sources that can notify about changes. See Loader.Watch.
+// Watch blocks until ctx is done or watching fails, notify is called on every change.
+type SourceWatcher interface {
+	Watch(ctx context.Context, notify func()) error
+}
+
+// WatchState is a state of a SourceWatcher watched by Loader.Watch. See Config.OnWatchState.
+type WatchState int
+
+const (
+	// WatchStarted means Watch of the source is called, first time or after a failure.
+	WatchStarted WatchState = iota + 1
+
+	// WatchFailed means Watch of the source returned an error.
+	// The source is watched again after Config.WatchBackoff if it's set.
+	WatchFailed
+
+	// WatchStopped means the source isn't watched anymore.
+	WatchStopped
+)
+
+func (s WatchState) String() string {
+	switch s {
+	case WatchStarted:
+		return "started"
+	case WatchFailed:
+		return "failed"
+	case WatchStopped:
+		return "stopped"
+	default:
+		return fmt.Sprintf("WatchState(%d)", int(s))
+	}
+}
+
 // Field of the user configuration structure.
 // Done as an interface to export less things in lib.
 type Field interface {
@@ -121,6 +530,74 @@ type Field interface {
 
 	// Parent of the current node.
 	Parent() (Field, bool)
+
+	// Source of the field value set by the last Load.
+	Source() ValueSource
+
+	// DefaultValue returns the value from `default` tag parsed into the field type,
+	// nil if the field has no default.
+	DefaultValue() any
+
+	// EnumValues returns allowed values from `oneof` tag, nil if there is no such tag.
+	EnumValues() []string
+
+	// AllTags returns a copy of all the tags of the field: struct tags and tags generated by the loader,
+	// like "env", "flag" and file formats names. Generated tags are the same as Tag returns.
+	AllTags() map[string]string
+}
+
+// ValueSource describes where the final value of a field came from.
+type ValueSource struct {
+	Kind string // Kind is "default", "file", "source", "env", "flag", "override" or empty if the field isn't set.
+	Name string // Name of the env var, flag or file key. Empty for `default` tags.
+	File string // File is a path of the file for "file" kind and embedded defaults or a name of the source for "source" kind.
+}
+
+// isFile reports whether the value came from a file, embedded defaults included.
+func (s ValueSource) isFile() bool {
+	return s.Kind == "file" || s.Kind == "default" && s.File != ""
+}
+
+// String returns a short description like `env APP_PORT` or `file config.json (key port)`.
+func (s ValueSource) String() string {
+	switch s.Kind {
+	case "":
+		return "not set"
+	case "default":
+		if s.File != "" {
+			return "default " + s.File + " (key " + s.Name + ")"
+		}
+		return "default"
+	case "file", "source", "override":
+		return s.Kind + " " + s.File + " (key " + s.Name + ")"
+	case "flag":
+		return "flag -" + s.Name
+	default:
+		return s.Kind + " " + s.Name
+	}
+}
+
+// Validator is implemented by configuration structures that check themselves.
+// Validate is called after all the sources are applied, nested structures first.
+type Validator interface {
+	Validate() error
+}
+
+// Duplicate describes a name shared by several fields. See Config.AllowDuplicates.
+type Duplicate struct {
+	Source string   // Source of the name: "env", "flag" or a file format like "json".
+	Name   string   // Name is the duplicated env var, flag or file key.
+	Fields []string // Fields that share the name, in the declaration order.
+}
+
+// FlagInfo describes a flag of a field. See Loader.FlagInfos.
+type FlagInfo struct {
+	Name    string // Name of the flag with FlagPrefix, without leading dash.
+	Field   string // Field is a path to the field in the structure like `Auth.User`.
+	Usage   string // Usage from `usage` tag.
+	Default string // Default value from `default` tag as shown in help, not a parsed value.
+	Value   string // Value passed in command-line args, empty when the flag isn't set.
+	IsSet   bool   // IsSet reports whether the flag was passed in command-line args.
 }
 
 // LoaderFor creates a new Loader based on a given configuration structure.
@@ -131,6 +608,7 @@ func LoaderFor(dst any, cfg Config) *Loader {
 	l := &Loader{
 		dst:    dst,
 		config: cfg,
+		base:   cfg,
 	}
 	l.init()
 	return l
@@ -138,10 +616,14 @@ func LoaderFor(dst any, cfg Config) *Loader {
 
 func (l *Loader) init() {
 	l.config.envDelimiter = "_"
+	l.skipUnordered()
 
 	if l.config.FlagDelimiter == "" {
 		l.config.FlagDelimiter = "."
 	}
+	if l.config.SliceSeparator == "" {
+		l.config.SliceSeparator = ","
+	}
 
 	if l.config.EnvPrefix != "" {
 		l.config.EnvPrefix += l.config.envDelimiter
@@ -151,20 +633,25 @@ func (l *Loader) init() {
 	}
 
 	l.fsys = &fsOrOS{l.config.FileSystem}
-
-	if _, ok := l.config.FileDecoders[".json"]; !ok {
-		if l.config.FileDecoders == nil {
-			l.config.FileDecoders = map[string]FileDecoder{}
-		}
-		l.config.FileDecoders[".json"] = &jsonDecoder{}
+	if len(l.config.FileRoots) != 0 {
+		l.fsys = newRootsFS(l.fsys, l.config.FileRoots, l.config.FileSystem == nil)
 	}
-	for _, dec := range l.config.FileDecoders {
-		dec, ok := dec.(interface{ Init(fs.FS) })
-		if !ok {
-			continue
-		}
-		dec.Init(l.fsys)
+	// decoders are copied, so loaders with the same Config don't share them.
+	decoders := make(map[string]FileDecoder, len(l.config.FileDecoders)+1)
+	for ext, dec := range l.config.FileDecoders {
+		decoders[ext] = dec
 	}
+	if _, ok := decoders[".json"]; !ok {
+		decoders[".json"] = &jsonDecoder{stream: l.config.StreamFiles}
+	}
+	l.config.FileDecoders = initDecoders(decoders, l.fsys)
+	l.config.MIMEDecoders = initDecoders(l.config.MIMEDecoders, l.fsys)
+	l.urls = newURLFS(l.config.HTTPFiles, l.config.FileFetchers, l.config.FileDecoders)
+	l.urls.maxSize = l.config.MaxFileSize
+	if l.config.Stdin == nil {
+		l.config.Stdin = os.Stdin
+	}
+	l.stdin = &stdinFS{r: l.config.Stdin}
 
 	if l.config.Envs == nil {
 		l.config.Envs = os.Environ()
@@ -179,9 +666,44 @@ func (l *Loader) init() {
 			l.errInit = err
 			return
 		}
-	} else {
+	} else if l.fields == nil {
 		l.fields = l.getFields(l.dst)
 	}
+	l.indexEnvs()
+
+	if l.config.FailOnUnexportedFields {
+		if err := l.checkUnexported(reflect.TypeOf(l.dst).Elem(), nil); err != nil {
+			l.errInit = err
+			return
+		}
+	}
+	if err := l.checkDefaults(); err != nil {
+		l.errInit = err
+		return
+	}
+	if !l.config.NewParser {
+		if err := l.expandUsages(); err != nil {
+			l.errInit = err
+			return
+		}
+	}
+	if err := l.checkEnvRemain(); err != nil {
+		l.errInit = err
+		return
+	}
+	if err := l.checkSliceMerge(); err != nil {
+		l.errInit = err
+		return
+	}
+	if err := l.checkOrder(); err != nil {
+		l.errInit = err
+		return
+	}
+	if err := l.checkOrderTags(); err != nil {
+		l.errInit = err
+		return
+	}
+	l.dupls = l.findDuplicates()
 
 	l.flagSet = flag.NewFlagSet(l.config.FlagPrefix, flag.ContinueOnError)
 	if !l.config.SkipFlags {
@@ -190,16 +712,28 @@ func (l *Loader) init() {
 			l.flagSet = l.parser.flagSet
 		} else {
 			for _, field := range l.fields {
-				flagName := l.fullTag(l.config.FlagPrefix, field, "flag")
+				flagName := l.fieldName(field, "flag")
 				if flagName == "" {
 					continue
 				}
-				if names[flagName] && !l.config.AllowDuplicates {
-					l.errInit = fmt.Errorf("duplicate flag %q", flagName)
-					return
+				if names[flagName] {
+					if !l.config.AllowDuplicates {
+						l.errInit = fmt.Errorf("duplicate flag %q", flagName)
+						return
+					}
+					// first declared field wins.
+					continue
 				}
 				names[flagName] = true
-				l.flagSet.String(flagName, field.Tag("default"), field.Tag("usage"))
+				value := repeatedFlag(field.field, field.Tag("default"), l.config.SliceSeparator)
+				switch {
+				case value != nil:
+					l.flagSet.Var(value, flagName, field.Tag("usage"))
+				case l.config.Experimental.Has(ExperimentTypedFlags):
+					l.flagSet.Var(newTypedFlag(field.field, field.Tag("default")), flagName, field.Tag("usage"))
+				default:
+					l.flagSet.String(flagName, field.Tag("default"), field.Tag("usage"))
+				}
 			}
 		}
 	}
@@ -208,83 +742,302 @@ func (l *Loader) init() {
 		// TODO: should be prefixed ?
 		l.flagSet.String(l.config.FileFlag, "", "config file param")
 	}
-
-	if l.config.SliceSeparator == "" {
-		l.config.SliceSeparator = ","
+	if l.config.ProfileFlag != "" {
+		l.flagSet.String(l.config.ProfileFlag, "", "config profile")
+	}
+	if l.config.SetFlag != "" {
+		l.flagSet.Var(&setFlag{}, l.config.SetFlag, "set a field, like -"+l.config.SetFlag+" server.port=9090 (repeatable)")
 	}
+	if l.config.PrintConfigFlag != "" {
+		l.printConfigFlag = l.flagSet.Bool(l.config.PrintConfigFlag, false, "print config and exit")
+	}
+	// a field with the same flag wins.
+	if l.config.Version != "" && l.flagSet.Lookup(versionFlag) == nil {
+		l.versionFlag = l.flagSet.Bool(versionFlag, false, "print version and exit")
+	}
+	l.flagSet.Usage = l.printUsage
 }
 
 // Flags returngs flag.FlagSet to create your own flags.
 // FlagSet name is Config.FlagPrefix and error handling is set to ContinueOnError.
+// Flags can be called concurrently with Load, but the returned FlagSet itself isn't synchronized.
 func (l *Loader) Flags() *flag.FlagSet {
+	l.stateMu.RLock()
+	defer l.stateMu.RUnlock()
 	return l.flagSet
 }
 
-// WalkFields iterates over configuration fields.
+// FlagInfos returns flags of the fields in the structure order.
+// Unlike flag.Flag.Value it keeps the default and the parsed value apart,
+// so a flag that was passed with the default value is still reported as set.
+func (l *Loader) FlagInfos() []FlagInfo {
+	l.stateMu.RLock()
+	defer l.stateMu.RUnlock()
+
+	actualFlags := getFlags(l.flagSet)
+
+	var res []FlagInfo
+	for _, field := range l.allFields() {
+		name := l.sourceName(field, "flag")
+		if name == "" {
+			continue
+		}
+		f := l.flagSet.Lookup(name)
+		if f == nil {
+			continue
+		}
+
+		value, isSet := actualFlags[name]
+		info := FlagInfo{
+			Name:    name,
+			Field:   field.Name(),
+			Usage:   f.Usage,
+			Default: f.DefValue,
+			IsSet:   isSet,
+		}
+		if isSet {
+			info.Value = value.(string)
+		}
+		res = append(res, info)
+	}
+	return res
+}
+
+// Duplicates returns names that are shared by several fields, sources skipped by Config aren't checked.
+// Sorted by source and name. See Config.AllowDuplicates.
+func (l *Loader) Duplicates() []Duplicate {
+	return l.dupls
+}
+
+// LoadedFiles returns files loaded by the last Load in the load order.
+// Includes embedded defaults and a file passed via Config.FileFlag.
+func (l *Loader) LoadedFiles() []string {
+	l.stateMu.RLock()
+	defer l.stateMu.RUnlock()
+	return l.loadedFiles
+}
+
+// MissingFiles returns files that were skipped by the last Load because they do not exist.
+func (l *Loader) MissingFiles() []string {
+	l.stateMu.RLock()
+	defer l.stateMu.RUnlock()
+	return l.missingFiles
+}
+
+// Explain returns where the value of the field came from during the last Load.
+// Name is a path to the field in the structure like `Auth.User`.
+func (l *Loader) Explain(name string) string {
+	l.stateMu.RLock()
+	defer l.stateMu.RUnlock()
+
+	for _, field := range l.allFields() {
+		if field.Name() == name {
+			return name + ": " + field.Source().String()
+		}
+	}
+	return name + ": unknown field"
+}
+
+// WalkFields iterates over configuration fields in the declaration order or by `order` tag.
 // Easy way to create documentation or user-friendly help.
+// Fields are copies taken before fn is called, so fn can call other methods of the loader.
 func (l *Loader) WalkFields(fn func(f Field) bool) {
-	for _, f := range l.fields {
+	l.stateMu.RLock()
+	fields := l.allFields()
+	for i, field := range fields {
+		fields[i] = snapshotField(field)
+	}
+	l.stateMu.RUnlock()
+	sortByOrderTag(fields)
+
+	for _, f := range fields {
 		if !fn(f) {
 			return
 		}
 	}
 }
 
+// snapshotField returns a copy of the field, so it can be read while the loader loads again.
+func snapshotField(f Field) Field {
+	switch f := f.(type) {
+	case *fieldData:
+		c := *f
+		return &c
+	case *parsedField:
+		c := *f
+		return &c
+	default:
+		return f
+	}
+}
+
 // Load configuration into a given param.
 func (l *Loader) Load() error {
+	return l.LoadContext(context.Background())
+}
+
+// LoadContext is Load that stops when ctx is done.
+// ctx is passed to sources, field sources and file fetchers, HTTP files are requested with it.
+// Loading is also stopped between files and source kinds, the error wraps ctx.Err().
+//
+// Load is safe to call concurrently with Flags, WalkFields, Explain, FlagInfos, Report
+// and other readers like Lint, Drift, Inputs or Dump, they see the state before or after the load.
+// Callbacks from Config are called during the load and must not call these methods.
+func (l *Loader) LoadContext(ctx context.Context) error {
+	l.stateMu.Lock()
+	defer l.stateMu.Unlock()
+
 	if l.errInit != nil {
 		return fmt.Errorf("init loader: %w", l.errInit)
 	}
-	if err := l.loadConfig(); err != nil {
+	start := time.Now()
+	if err := l.loadConfig(ctx); err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+	l.loadDuration = time.Since(start)
+	if err := l.checkReport(); err != nil {
 		return fmt.Errorf("load config: %w", err)
 	}
+	l.publish(reflect.ValueOf(l.dst))
 	return nil
 }
 
-func (l *Loader) loadConfig() error {
+func (l *Loader) loadConfig(ctx context.Context) error {
+	l.inputs = Inputs{Files: map[string][]byte{}, Hashes: map[string]string{}}
+	l.unknownKeys = nil
+	l.loadedFiles, l.missingFiles = nil, nil
+	l.factoryData = map[string]any{}
+	l.fileFields = map[string]struct{}{}
+	l.leases = nil
+	l.timings = nil
+	for _, field := range l.fields {
+		field.isSet = false
+		field.source = ValueSource{}
+	}
+	if l.parser != nil {
+		l.parser.reset()
+	}
+
 	if err := l.parseFlags(); err != nil {
 		return err
 	}
-	if err := l.loadSources(); err != nil {
+	if err := l.loadSources(ctx); err != nil {
 		return err
 	}
+	if err := l.applyFactories(); err != nil {
+		return err
+	}
+	l.recordInputs()
+
+	if l.printConfigFlag != nil && *l.printConfigFlag {
+		l.printConfig(l.flagSet.Output())
+		return ErrHelp
+	}
+
 	if err := l.checkRequired(); err != nil {
 		return err
 	}
+	if err := l.validateFields(); err != nil {
+		return err
+	}
+	if err := validateStruct(reflect.ValueOf(l.dst).Elem(), "", true); err != nil {
+		return err
+	}
 	return nil
 }
 
 func (l *Loader) parseFlags() error {
-	// TODO: too simple?
-	if l.flagSet.Parsed() || l.config.SkipFlags {
+	if l.config.SkipFlags {
 		return nil
 	}
-	return l.flagSet.Parse(l.config.Args)
+	// TODO: too simple?
+	if !l.flagSet.Parsed() {
+		if err := l.flagSet.Parse(l.config.Args); err != nil {
+			return err
+		}
+	}
+	if l.versionFlag != nil && *l.versionFlag {
+		fmt.Fprintln(l.flagSet.Output(), l.config.Version)
+		return ErrHelp
+	}
+	if l.config.CheckRequiredFlags {
+		return l.checkRequiredFlags()
+	}
+	return nil
 }
 
-func (l *Loader) loadSources() error {
-	if !l.config.SkipDefaults {
-		if err := l.loadDefaults(); err != nil {
-			return fmt.Errorf("load defaults: %w", err)
+// checkRequiredFlags prints usage and returns an error if a required field
+// that can be set only by a flag wasn't passed in command-line args.
+func (l *Loader) checkRequiredFlags() error {
+	actualFlags := getFlags(l.flagSet)
+
+	var missed []string
+	for _, field := range l.allFields() {
+		if !isFieldRequired(field) || !l.isFlagOnly(field) {
+			continue
+		}
+		name := l.sourceName(field, "flag")
+		if _, ok := actualFlags[name]; !ok {
+			missed = append(missed, "-"+name)
 		}
 	}
+
+	if len(missed) == 0 {
+		return nil
+	}
+	l.flagSet.Usage()
+	return fmt.Errorf("required flags are not set: %s", strings.Join(missed, ", "))
+}
+
+// isFlagOnly reports whether the field has a flag and no other source can set it.
+func (l *Loader) isFlagOnly(field Field) bool {
+	if l.sourceName(field, "flag") == "" || len(l.config.Sources) > 0 {
+		return false
+	}
+	if !l.config.SkipDefaults && (field.Tag("default") != "" || l.config.EmbeddedDefaults != nil) {
+		return false
+	}
+	if !l.config.SkipEnv && l.sourceName(field, "env") != "" {
+		return false
+	}
 	if !l.config.SkipFiles {
-		if err := l.loadFiles(); err != nil {
-			return fmt.Errorf("load files: %w", err)
+		for _, dec := range l.config.FileDecoders {
+			if l.sourceName(field, dec.Format()) != "" {
+				return false
+			}
 		}
 	}
-	if !l.config.SkipEnv {
-		if err := l.loadEnvironment(); err != nil {
-			return fmt.Errorf("load environment: %w", err)
+	return true
+}
+
+func (l *Loader) loadSources(ctx context.Context) error {
+	for _, kind := range l.loadOrder() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		err := l.timeSource(string(kind), func() error {
+			if err := l.loadSource(ctx, kind); err != nil {
+				return err
+			}
+			return l.loadAdded(kind)
+		})
+		if err != nil {
+			return err
 		}
 	}
-	if !l.config.SkipFlags {
-		if err := l.loadFlags(); err != nil {
+	if l.config.SetFlag != "" {
+		if err := l.timeSource("set", l.loadSetFlag); err != nil {
 			return fmt.Errorf("load flags: %w", err)
 		}
 	}
+	if l.config.OverrideFile != "" {
+		if err := l.timeSource("override", l.loadOverrideFile); err != nil {
+			return fmt.Errorf("load override file: %w", err)
+		}
+	}
 
 	if l.config.NewParser {
+		l.parser.takeFactoryData(l.factoryData)
 		if err := l.parser.apply(l.dst); err != nil {
 			return fmt.Errorf("apply: %w", err)
 		}
@@ -294,85 +1047,586 @@ func (l *Loader) loadSources() error {
 
 func (l *Loader) checkRequired() error {
 	missedFields := []string{}
-	for _, field := range l.fields {
-		if field.isSet {
+	for _, f := range l.allFields() {
+		if isFieldSet(f) {
+			continue
+		}
+		if isFieldRequired(f) || l.config.AllFieldRequired {
+			missedFields = append(missedFields, l.fieldGuidance(f))
+		}
+	}
+
+	if len(missedFields) != 0 {
+		return fmt.Errorf("fields required but not set: %s", strings.Join(missedFields, "; "))
+	}
+	return l.checkConstraints()
+}
+
+// checkConstraints validates set fields against `min`, `max`, `oneof`, `keys`, `minitems` and `maxitems` tags.
+func (l *Loader) checkConstraints() error {
+	root := reflect.ValueOf(l.dst).Elem()
+	invalidFields := []string{}
+	for _, f := range l.allFields() {
+		if !isFieldSet(f) {
+			continue
+		}
+		minTag, maxTag, oneofTag, keysTag := f.Tag("min"), f.Tag("max"), f.Tag("oneof"), f.Tag("keys")
+		minItemsTag, maxItemsTag := f.Tag("minitems"), f.Tag("maxitems")
+		if minTag == "" && maxTag == "" && oneofTag == "" && keysTag == "" && minItemsTag == "" && maxItemsTag == "" {
 			continue
 		}
-		if field.isRequired || l.config.AllFieldRequired {
-			missedFields = append(missedFields, field.name)
+
+		_, index := fieldInfo(f)
+		value := reflect.ValueOf(valueByIndex(root, index))
+		for value.Kind() == reflect.Ptr && !value.IsNil() {
+			value = value.Elem()
+		}
+		if !value.IsValid() || value.Kind() == reflect.Ptr {
+			continue
+		}
+
+		if err := checkConstraint(value, minTag, maxTag, oneofTag); err != nil {
+			invalidFields = append(invalidFields, f.Name()+" "+err.Error())
+			continue
+		}
+		if minItemsTag != "" || maxItemsTag != "" {
+			if err := checkItems(value, minItemsTag, maxItemsTag); err != nil {
+				invalidFields = append(invalidFields, f.Name()+" "+err.Error())
+				continue
+			}
+		}
+		if keysTag != "" {
+			if err := checkKeys(value, keysTag); err != nil {
+				invalidFields = append(invalidFields, f.Name()+" "+err.Error())
+			}
 		}
 	}
 
-	if len(missedFields) == 0 {
+	if len(invalidFields) == 0 {
 		return nil
 	}
-	return fmt.Errorf("fields required but not set: %s", strings.Join(missedFields, ","))
+	return fmt.Errorf("fields have invalid values: %s", strings.Join(invalidFields, "; "))
+}
+
+// fieldGuidance returns field name with all the ways to set it.
+// Example: "Auth.User (env APP_AUTH_USER, flag -app.auth.user, json auth.user)".
+func (l *Loader) fieldGuidance(field Field) string {
+	var ways []string
+	if !l.config.SkipEnv {
+		if name := l.sourceName(field, "env"); name != "" {
+			ways = append(ways, "env "+name)
+		}
+	}
+	if !l.config.SkipFlags {
+		if name := l.sourceName(field, "flag"); name != "" {
+			ways = append(ways, "flag -"+name)
+		}
+	}
+	if !l.config.SkipFiles {
+		formats := make([]string, 0, len(l.config.FileDecoders))
+		for _, dec := range l.config.FileDecoders {
+			formats = append(formats, dec.Format())
+		}
+		sort.Strings(formats)
+
+		for i, format := range formats {
+			if i > 0 && format == formats[i-1] {
+				continue
+			}
+			if name := l.sourceName(field, format); name != "" {
+				ways = append(ways, format+" "+name)
+			}
+		}
+	}
+
+	if len(ways) == 0 {
+		return field.Name()
+	}
+	return field.Name() + " (" + strings.Join(ways, ", ") + ")"
 }
 
 func (l *Loader) loadDefaults() error {
 	if l.config.NewParser {
+		// values are set on parse, only mark fields as set.
+		l.parser.applyDefaults()
 		return nil
 	}
 
 	for _, field := range l.fields {
 		defaultValue := field.Tag("default")
+		if defaultValue == "" && field.isSet {
+			// set by a source loaded before defaults, see Config.Order.
+			continue
+		}
 		if err := l.setFieldData(field, defaultValue); err != nil {
 			return err
 		}
-		field.isSet = (defaultValue != "")
+		if defaultValue != "" {
+			l.markSet(field, ValueSource{Kind: "default"}, defaultValue)
+		}
 	}
 	return nil
 }
 
-func (l *Loader) loadFiles() error {
-	if l.config.FileFlag != "" {
-		if err := l.loadFileFlag(); err != nil {
+func (l *Loader) loadCustomSources(ctx context.Context) error {
+	for _, src := range l.config.Sources {
+		name := sourceName(src)
+		values, err := src.Load(ctx)
+		if err != nil {
+			return fmt.Errorf("source %s: %w", name, err)
+		}
+		values, err = l.decryptValues(values)
+		if err != nil {
+			return fmt.Errorf("source %s: %w", name, err)
+		}
+
+		format := "json"
+		if f, ok := src.(interface{ Format() string }); ok {
+			format = f.Format()
+		}
+		if err := l.applyValues(ValueSource{Kind: "source", File: name}, format, l.applySubtree(values)); err != nil {
 			return err
 		}
+
+		if fsrc, ok := src.(FieldSource); ok {
+			if err := l.loadFieldSource(ctx, fsrc, name); err != nil {
+				return fmt.Errorf("source %s: %w", name, err)
+			}
+		}
 	}
+	return nil
+}
 
-	for _, file := range l.config.Files {
-		if _, err := fs.Stat(l.fsys, file); os.IsNotExist(err) {
+// loadFieldSource sets fields which have a reference in the source tag.
+func (l *Loader) loadFieldSource(ctx context.Context, src FieldSource, name string) error {
+	tag := src.FieldTag()
+	for _, field := range l.allFields() {
+		ref := field.Tag(tag)
+		if ref == "" {
+			continue
+		}
+
+		value, err := src.LoadField(ctx, ref)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", field.Name(), err)
+		}
+		value = l.unwrapLease(value, field, src, name, ref)
+		if l.config.Decrypt != nil {
+			if value, err = l.decryptValue(ref, value); err != nil {
+				return fmt.Errorf("field %s: %w", field.Name(), err)
+			}
+		}
+		source := ValueSource{Kind: "source", Name: ref, File: name}
+		if err := l.setFieldValue(field, value, source); err != nil {
+			return fmt.Errorf("field %s: %w", field.Name(), err)
+		}
+	}
+	return nil
+}
+
+func sourceName(src Source) string {
+	if n, ok := src.(interface{ Name() string }); ok {
+		return n.Name()
+	}
+	return fmt.Sprintf("%T", src)
+}
+
+func (l *Loader) loadEmbeddedDefaults() error {
+	if l.config.EmbeddedDefaults == nil {
+		return nil
+	}
+	file := FileEntry{
+		Path:       l.config.EmbeddedDefaultsFile,
+		FileSystem: l.config.EmbeddedDefaults,
+	}
+	actualFields, tag, err := l.fileValues(file)
+	if err != nil {
+		return err
+	}
+	// fields are set by defaults, so the file isn't in LoadedFiles and Drift.
+	return l.applyValues(ValueSource{Kind: "default", File: file.Path}, tag, l.applySubtree(actualFields))
+}
+
+// checkUnexported returns an error for the first unexported field with loader tags.
+func (l *Loader) checkUnexported(typ reflect.Type, seen map[reflect.Type]bool) error {
+	if seen == nil {
+		seen = map[reflect.Type]bool{}
+	}
+	if seen[typ] {
+		return nil
+	}
+	seen[typ] = true
+
+	tags := []string{"default", "usage", "required", "env", "flag", "aconfig"}
+	for _, dec := range l.config.FileDecoders {
+		tags = append(tags, dec.Format())
+	}
+
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if isIgnored(field) {
+			continue
+		}
+
+		if field.PkgPath != "" && !field.Anonymous {
+			for _, tag := range tags {
+				if _, ok := field.Tag.Lookup(tag); ok {
+					return fmt.Errorf("unexported field %s.%s has %q tag (see FailOnUnexportedFields config param)", typ.Name(), field.Name, tag)
+				}
+			}
+			continue
+		}
+
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		if fieldType.Kind() == reflect.Struct {
+			if err := l.checkUnexported(fieldType, seen); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkDefaults validates every 'default' tag against its field type.
+// Done even when SkipDefaults is set to catch typos as early as possible.
+func (l *Loader) checkDefaults() error {
+	fields := l.fields
+	if l.config.NewParser {
+		// do not touch dst, new parser doesn't fill fields.
+		fields = l.getFields(reflect.New(reflect.TypeOf(l.dst).Elem()).Interface())
+	}
+
+	var parsed map[string]*parsedField
+	if l.config.NewParser {
+		parsed = make(map[string]*parsedField, len(l.parser.order))
+		for _, pfield := range l.parser.order {
+			parsed[pfield.Name()] = pfield
+		}
+	}
+
+	for _, field := range fields {
+		defaultValue := field.Tag("default")
+		if defaultValue == "" {
+			continue
+		}
+
+		value := reflect.New(field.field.Type).Elem()
+		fd := &fieldData{
+			name:  field.name,
+			field: field.field,
+			value: value,
+			tags:  field.tags,
+		}
+		if err := l.setFieldData(fd, defaultValue); err != nil {
+			return fmt.Errorf("incorrect default value for field %q: %w", field.name, err)
+		}
+
+		// keep the parsed value for Field.DefaultValue.
+		if pfield, ok := parsed[field.name]; ok {
+			pfield.typedDefault = value.Interface()
+		} else {
+			field.typedDefault = value.Interface()
+		}
+	}
+	return nil
+}
+
+func (l *Loader) loadFiles(ctx context.Context) error {
+	files, err := l.fileEntries()
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if urls, ok := file.FileSystem.(*urlFS); ok {
+			// download once, so the file is the same for decoding and Inputs.
+			f, err := urls.fetch(ctx, file.Path)
+			switch {
+			case errors.Is(err, fs.ErrNotExist) && !l.config.FailOnFileNotFound:
+				l.missingFiles = append(l.missingFiles, file.Path)
+				continue
+			case err != nil:
+				return err
+			}
+			file.FileSystem = f
+		}
+
+		if _, err := fs.Stat(file.FileSystem, file.Path); os.IsNotExist(err) {
 			if l.config.FailOnFileNotFound {
 				return err
 			}
+			l.missingFiles = append(l.missingFiles, file.Path)
 			continue
 		}
 
 		if err := l.loadFile(file); err != nil {
 			return err
 		}
+		if err := l.loadProfileFile(file); err != nil {
+			return err
+		}
 
 		if !l.config.MergeFiles {
 			break
 		}
 	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := l.loadDirs(); err != nil {
+		return err
+	}
+
+	for _, group := range l.config.FileGroups {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := l.loadFileGroup(group); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-func (l *Loader) loadFile(file string) error {
-	ext := strings.ToLower(filepath.Ext(file))
+// loadFileGroup loads only the first existing file from the group.
+func (l *Loader) loadFileGroup(group []string) error {
+	for _, file := range group {
+		if _, err := fs.Stat(l.fsys, file); os.IsNotExist(err) {
+			l.missingFiles = append(l.missingFiles, file)
+			continue
+		}
+		return l.loadFile(FileEntry{Path: file, FileSystem: l.fsys})
+	}
+
+	if l.config.FailOnFileNotFound && len(group) > 0 {
+		return fmt.Errorf("none of the files exist: %s", strings.Join(group, ", "))
+	}
+	return nil
+}
+
+// fileEntries returns files to load in order: Files, FileEntries and a file from FileFlag.
+func (l *Loader) fileEntries() ([]FileEntry, error) {
+	files := make([]FileEntry, 0, len(l.config.Files)+len(l.config.FileEntries)+1)
+	for _, file := range l.config.Files {
+		files = append(files, FileEntry{Path: file})
+	}
+	files = append(files, l.config.FileEntries...)
+
+	if l.config.FileFlag != "" {
+		configFile, err := l.fileFromFlag()
+		if err != nil {
+			return nil, err
+		}
+		if configFile != "" {
+			if l.config.MergeFiles {
+				files = append(files, FileEntry{Path: configFile})
+			} else {
+				files = []FileEntry{{Path: configFile}}
+			}
+		}
+	}
+
+	for i := range files {
+		switch {
+		case files[i].FileSystem != nil:
+		case files[i].Path == stdinName:
+			files[i].FileSystem = l.stdin
+		case l.urls.isURL(files[i].Path):
+			files[i].FileSystem = l.urls
+		default:
+			files[i].FileSystem = l.fsys
+		}
+	}
+	return files, nil
+}
+
+func (l *Loader) loadFile(file FileEntry) error {
+	actualFields, tag, err := l.fileValues(file)
+	if err != nil {
+		return err
+	}
+	l.loadedFiles = append(l.loadedFiles, file.Path)
+	return l.applyValues(ValueSource{Kind: "file", File: file.Path}, tag, l.applySubtree(actualFields))
+}
+
+// fileValues returns values of the file with envs expanded, values decrypted, conditions and profile applied.
+func (l *Loader) fileValues(file FileEntry) (map[string]interface{}, string, error) {
+	actualFields, tag, err := l.decodeFile(file)
+	if err != nil {
+		return nil, "", err
+	}
+	actualFields = l.expandEnv(actualFields)
+	actualFields, err = l.decryptValues(actualFields)
+	if err != nil {
+		return nil, "", fmt.Errorf("file %s: %w", file.Path, err)
+	}
+	actualFields, err = l.applyConditions(actualFields)
+	if err != nil {
+		return nil, "", fmt.Errorf("file %s: %w", file.Path, err)
+	}
+	actualFields, err = l.applyProfile(actualFields)
+	if err != nil {
+		return nil, "", fmt.Errorf("file %s: %w", file.Path, err)
+	}
+	return actualFields, tag, nil
+}
+
+// applyProfile removes profiles from the file values and merges the one selected by Config.Profile.
+func (l *Loader) applyProfile(values map[string]interface{}) (map[string]interface{}, error) {
+	raw, ok := values[profilesKey]
+	if !ok {
+		return values, nil
+	}
+	delete(values, profilesKey)
+
+	profile := l.profile()
+	if profile == "" {
+		return values, nil
+	}
+
+	profiles, ok := asMap(raw)
+	if !ok {
+		return nil, fmt.Errorf("%q must be an object, got %T", profilesKey, raw)
+	}
+	selected, ok := profiles[profile]
+	if !ok {
+		return nil, fmt.Errorf("profile %q not found", profile)
+	}
+	overrides, ok := asMap(selected)
+	if !ok {
+		return nil, fmt.Errorf("profile %q must be an object, got %T", profile, selected)
+	}
+	return mergeMaps(values, overrides), nil
+}
+
+// decodeFile returns file content and a tag (file format) that should be used for the fields.
+func (l *Loader) decodeFile(file FileEntry) (map[string]interface{}, string, error) {
+	ext := strings.ToLower(filepath.Ext(file.Path))
+	switch f := file.FileSystem.(type) {
+	case *urlFile:
+		ext = f.ext
+	case *stdinFS:
+		ext = l.stdinExt()
+	}
 	decoder, ok := l.config.FileDecoders[ext]
+	if f, isURL := file.FileSystem.(*urlFile); !ok && isURL {
+		decoder, ok = l.mimeDecoder(f.contentType)
+	}
+	if !ok && l.config.SniffFormat {
+		decoder, ok = l.sniffDecoder(file.FileSystem, file.Path)
+	}
 	if !ok {
-		return fmt.Errorf("file format %q is not supported", ext)
+		return nil, "", fmt.Errorf("file format %q is not supported", ext)
+	}
+
+	fsys := file.FileSystem
+	if l.config.MaxFileSize > 0 {
+		if err := checkFileSize(fsys, file.Path, l.config.MaxFileSize); err != nil {
+			return nil, "", err
+		}
+		fsys = &limitFS{FS: fsys, name: file.Path, max: l.config.MaxFileSize}
+	}
+	// the file is read once by the decoder, contents are kept only when Inputs need them.
+	h := sha256.New()
+	var raw *bytes.Buffer
+	if l.config.RecordInputs {
+		raw = &bytes.Buffer{}
+	}
+	fsys = &teeFS{FS: fsys, name: file.Path, w: h, raw: raw}
+	defer func() {
+		if raw != nil {
+			l.inputs.Files[file.Path] = raw.Bytes()
+		} else {
+			l.inputs.Hashes[file.Path] = hex.EncodeToString(h.Sum(nil))
+		}
+	}()
+	if l.config.FileTemplates {
+		var err error
+		if fsys, err = l.renderFile(fsys, file.Path); err != nil {
+			return nil, "", err
+		}
 	}
 
-	actualFields, err := decoder.DecodeFile(file)
+	actualFields, err := decodeWith(decoder, fsys, file.Path)
 	if err != nil {
-		return err
+		return nil, "", err
 	}
+	if l.config.MaxDepth > 0 {
+		if err := checkDepth(actualFields, l.config.MaxDepth); err != nil {
+			return nil, "", fmt.Errorf("file %s: %w", file.Path, err)
+		}
+	}
+	return actualFields, decoder.Format(), nil
+}
+
+// decodeWith decodes the file with DecodeReader if the decoder has it, otherwise with DecodeFile.
+func decodeWith(decoder FileDecoder, fsys fs.FS, name string) (map[string]any, error) {
+	if dec, ok := decoder.(ReaderDecoder); ok {
+		f, err := fsys.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
 
-	tag := decoder.Format()
+		return dec.DecodeReader(f)
+	}
+
+	// file might have its own file system.
+	return initDecoder(decoder, fsys).DecodeFile(name)
+}
 
+// initDecoders returns a copy of the decoders initialized by initDecoder.
+func initDecoders(decoders map[string]FileDecoder, fsys fs.FS) map[string]FileDecoder {
+	if decoders == nil {
+		return nil
+	}
+	res := make(map[string]FileDecoder, len(decoders))
+	for key, dec := range decoders {
+		res[key] = initDecoder(dec, fsys)
+	}
+	return res
+}
+
+// initDecoder returns a copy of the decoder with `Init(fs.FS)` method called with fsys.
+// The decoder itself isn't changed, so it's safe to share between loaders and files.
+// Decoders that aren't pointers to structs are initialized as is.
+func initDecoder(decoder FileDecoder, fsys fs.FS) FileDecoder {
+	if _, ok := decoder.(interface{ Init(fs.FS) }); !ok {
+		return decoder
+	}
+	if v := reflect.ValueOf(decoder); v.Kind() == reflect.Ptr && !v.IsNil() && v.Elem().Kind() == reflect.Struct {
+		cp := reflect.New(v.Elem().Type())
+		cp.Elem().Set(v.Elem())
+		decoder = cp.Interface().(FileDecoder)
+	}
+	decoder.(interface{ Init(fs.FS) }).Init(fsys)
+	return decoder
+}
+
+// applyValues sets fields from nested values of a file or a source, from is filled with a key of each field.
+func (l *Loader) applyValues(from ValueSource, tag string, actualFields map[string]interface{}) error {
 	if l.config.NewParser {
-		if err := l.parser.applyLevel(tag, actualFields); err != nil {
+		if err := l.parser.applyLevel(from, tag, actualFields); err != nil {
 			return fmt.Errorf("apply %s: %w", tag, err)
 		}
+		if from.Kind == "file" {
+			for _, key := range flattenKeys("", actualFields) {
+				l.unknownKeys = append(l.unknownKeys, fileKey{file: from.File, key: key})
+			}
+		}
 		return nil
 	}
 
+	used := make([]string, 0, len(actualFields))
 	for _, field := range l.fields {
-		name := l.fullTag("", field, tag)
+		name := l.fieldName(field, tag)
 		if name == "" {
 			continue
 		}
@@ -385,76 +1639,135 @@ func (l *Loader) loadFile(file string) error {
 			}
 		}
 
+		if l.config.Experimental.Has(ExperimentStrictCoercion) && !hasAdapter(l.config.Adapters, field.field.Type) {
+			if err := checkStrictType(field.field.Type, value); err != nil {
+				return fmt.Errorf("field %s: %w", field.name, err)
+			}
+		}
+		// a file over a file is always merged, see Config.MergeFiles.
+		var old reflect.Value
+		if field.value.Kind() == reflect.Map && from.Kind == "file" && field.source.isFile() {
+			old = reflect.ValueOf(field.value.Interface())
+		}
 		if err := l.setFieldData(field, value); err != nil {
 			return err
 		}
-		field.isSet = true
+		if old.IsValid() {
+			mergeMapValue(old, field.value)
+		}
+		if field.isProvided(value) {
+			source := from
+			source.Name = name
+			l.markSet(field, source, value)
+		}
+		if from.Kind == "file" {
+			l.fileFields[field.name] = struct{}{}
+		}
+		used = append(used, name)
+	}
+	// delete after all the fields are set, so duplicates get the same value.
+	for _, name := range used {
 		delete(actualFields, name)
 	}
+	if from.Kind == "file" {
+		for _, key := range flattenKeys("", actualFields) {
+			l.unknownKeys = append(l.unknownKeys, fileKey{file: from.File, key: key})
+		}
+	}
 
-	if !l.config.AllowUnknownFields {
+	if !l.config.AllowUnknownFields && from.Kind != "override" {
 		for env := range actualFields {
-			return fmt.Errorf("unknown field in file %q: %s (see AllowUnknownFields config param)", file, env)
+			return fmt.Errorf("unknown field in %s %q: %s (see AllowUnknownFields config param)", from.Kind, from.File, env)
 		}
 	}
 	return nil
 }
 
-func (l *Loader) loadFileFlag() error {
+// fileFromFlag returns a file passed via FileFlag or empty string if flag isn't set.
+func (l *Loader) fileFromFlag() (string, error) {
 	fileFlag := getActualFlag(l.config.FileFlag, l.flagSet)
 	if fileFlag == nil {
-		return nil
+		return "", nil
 	}
 
 	configFile := fileFlag.Value.String()
 	if configFile == "" {
-		return fmt.Errorf("%s should not be empty", l.config.FileFlag)
-	}
-
-	if l.config.MergeFiles {
-		l.config.Files = append(l.config.Files, configFile)
-	} else {
-		l.config.Files = []string{configFile}
+		return "", fmt.Errorf("%s should not be empty", l.config.FileFlag)
 	}
-	return nil
+	return configFile, nil
 }
 
 func (l *Loader) loadEnvironment() error {
-	actualEnvs := getEnv(l.config.Envs)
-	dupls := make(map[string]struct{})
-
 	if l.config.NewParser {
+		// like for the default parser only known envs and envs with the prefix are checked.
+		actualEnvs := map[string]interface{}{}
+		for _, env := range l.config.Envs {
+			name, value, ok := cut(env, "=")
+			if !ok {
+				continue
+			}
+			if _, known := l.envIndex[name]; known || l.config.EnvPrefix != "" && strings.HasPrefix(name, l.config.EnvPrefix) {
+				actualEnvs[name] = value
+			}
+		}
+		delete(actualEnvs, l.config.ProfileEnv)
 		if err := l.parser.applyFlat("env", actualEnvs); err != nil {
 			return fmt.Errorf("apply env: %w", err)
 		}
 		return nil
 	}
 
-	for _, field := range l.fields {
-		envName := l.fullTag(l.config.EnvPrefix, field, "env")
-		if envName == "" {
+	if l.envDupl != "" && !l.config.AllowDuplicates {
+		return fmt.Errorf("field %q is duplicated", l.envDupl)
+	}
+
+	checkUnknown := !l.config.AllowUnknownEnvs && l.config.EnvPrefix != ""
+
+	// environment might be huge, so walk it once and use the index to find the fields.
+	for _, env := range l.config.Envs {
+		name, value, ok := cut(env, "=")
+		if !ok {
 			continue
 		}
-		if err := l.setField(field, envName, actualEnvs, dupls); err != nil {
-			return err
+
+		idxs, ok := l.envIndex[name]
+		if !ok {
+			if checkUnknown && strings.HasPrefix(name, l.config.EnvPrefix) && name != l.config.ProfileEnv {
+				return fmt.Errorf("unknown environment var %s (see AllowUnknownEnvs config param)", name)
+			}
+			continue
+		}
+
+		for _, idx := range idxs {
+			field := l.fields[idx]
+			if err := l.setFieldData(field, value); err != nil {
+				return err
+			}
+			if field.isProvided(value) {
+				l.markSet(field, ValueSource{Kind: "env", Name: name}, value)
+			}
 		}
 	}
-	return l.postEnvCheck(actualEnvs, dupls)
+	return nil
 }
 
-func (l *Loader) postEnvCheck(values map[string]any, dupls map[string]struct{}) error {
-	if l.config.AllowUnknownEnvs || l.config.EnvPrefix == "" {
-		return nil
-	}
-	for name := range dupls {
-		delete(values, name)
-	}
-	for env := range values {
-		if strings.HasPrefix(env, l.config.EnvPrefix) {
-			return fmt.Errorf("unknown environment var %s (see AllowUnknownEnvs config param)", env)
+// indexEnvs maps env names to the indexes of allFields, so loading doesn't scan all the fields for each env var.
+// Indexes stay valid after the fields are rebound on reload.
+func (l *Loader) indexEnvs() {
+	fields := l.allFields()
+	l.envIndex = make(map[string][]int, len(fields))
+	l.envDupl = ""
+
+	for i, field := range fields {
+		name := l.sourceName(field, "env")
+		if name == "" {
+			continue
+		}
+		if _, ok := l.envIndex[name]; ok && l.envDupl == "" {
+			l.envDupl = name
 		}
+		l.envIndex[name] = append(l.envIndex[name], i)
 	}
-	return nil
 }
 
 func (l *Loader) loadFlags() error {
@@ -462,6 +1775,12 @@ func (l *Loader) loadFlags() error {
 	dupls := make(map[string]struct{})
 
 	if l.config.NewParser {
+		// new parser doesn't split strings, so pass accumulated flags as slices and maps.
+		l.flagSet.Visit(func(f *flag.Flag) {
+			if value, ok := f.Value.(repeatedValue); ok {
+				actualFlags[f.Name] = value.items()
+			}
+		})
 		if err := l.parser.applyFlat("flag", actualFlags); err != nil {
 			return fmt.Errorf("apply flag: %w", err)
 		}
@@ -469,11 +1788,11 @@ func (l *Loader) loadFlags() error {
 	}
 
 	for _, field := range l.fields {
-		flagName := l.fullTag(l.config.FlagPrefix, field, "flag")
+		flagName := l.fieldName(field, "flag")
 		if flagName == "" {
 			continue
 		}
-		if err := l.setField(field, flagName, actualFlags, dupls); err != nil {
+		if err := l.setField(field, "flag", flagName, actualFlags, dupls); err != nil {
 			return err
 		}
 	}
@@ -496,7 +1815,7 @@ func (l *Loader) postFlagCheck(values map[string]any, dupls map[string]struct{})
 }
 
 // TODO(cristaloleg): revisit.
-func (l *Loader) setField(field *fieldData, name string, values map[string]any, dupls map[string]struct{}) error {
+func (l *Loader) setField(field *fieldData, kind, name string, values map[string]any, dupls map[string]struct{}) error {
 	if !l.config.AllowDuplicates {
 		if _, ok := dupls[name]; ok {
 			return fmt.Errorf("field %q is duplicated", name)
@@ -513,7 +1832,9 @@ func (l *Loader) setField(field *fieldData, name string, values map[string]any,
 		return err
 	}
 
-	field.isSet = true
+	if field.isProvided(val) {
+		l.markSet(field, ValueSource{Kind: kind, Name: name}, val)
+	}
 	if !l.config.AllowDuplicates {
 		delete(values, name)
 	}
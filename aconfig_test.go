@@ -1,13 +1,19 @@
 package aconfig
 
 import (
+	"bytes"
 	"embed"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"net/url"
 	"os"
 	"reflect"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"testing/fstest"
 	"time"
@@ -327,6 +333,50 @@ func TestFile(t *testing.T) {
 //go:embed testdata
 var configEmbed embed.FS
 
+func TestStreamFiles(t *testing.T) {
+	f := func(file string, dst func() any) {
+		t.Helper()
+
+		load := func(stream bool) (any, *Loader) {
+			cfg := dst()
+			loader := LoaderFor(cfg, Config{
+				SkipDefaults: true,
+				SkipEnv:      true,
+				SkipFlags:    true,
+				StreamFiles:  stream,
+				Files:        []string{file},
+			})
+			failIfErr(t, loader.Load())
+			return cfg, loader
+		}
+
+		want, _ := load(false)
+		have, loader := load(true)
+		mustEqual(t, have, want)
+
+		in := loader.Inputs()
+		mustEqual(t, len(in.Files), 0)
+		mustEqual(t, len(in.Hashes), 1)
+	}
+
+	f("testdata/config.json", func() any { return &TestConfig{} })
+	f("testdata/complex.json", func() any { return &ConfigTest{} })
+	f("testdata/slice-deep-structs.json", func() any {
+		return &struct {
+			Services []*struct {
+				Nested struct {
+					Name     string
+					Strings  []string
+					Integers []int
+					Nullable *struct{}
+					Booleans []bool
+					Structs  []*struct{ Key int }
+				}
+			}
+		}{}
+	})
+}
+
 func TestFileEmbed(t *testing.T) {
 	filepath := "testdata/config.json"
 
@@ -384,6 +434,237 @@ func TestFileMerging(t *testing.T) {
 	mustEqual(t, cfg, want)
 }
 
+func TestFileMergingMaps(t *testing.T) {
+	type TestConfig struct {
+		Server struct {
+			Host string
+			Port int
+		}
+		Labels map[string]string
+		Limits map[string]map[string]int
+	}
+
+	fsys := fstest.MapFS{
+		"base.json": &fstest.MapFile{Data: []byte(`{
+			"server": {"host": "localhost", "port": 8080},
+			"labels": {"app": "web", "team": "core"},
+			"limits": {"cpu": {"min": 1, "max": 2}, "mem": {"min": 128}}
+		}`)},
+		"prod.json": &fstest.MapFile{Data: []byte(`{
+			"server": {"port": 80},
+			"labels": {"team": "infra"},
+			"limits": {"cpu": {"max": 8}}
+		}`)},
+	}
+
+	var cfg TestConfig
+	loader := LoaderFor(&cfg, Config{
+		NewParser:    newParser,
+		SkipDefaults: true,
+		SkipEnv:      true,
+		SkipFlags:    true,
+		MergeFiles:   true,
+		Files:        []string{"base.json", "prod.json"},
+		FileSystem:   fsys,
+	})
+	failIfErr(t, loader.Load())
+
+	var want TestConfig
+	want.Server.Host = "localhost"
+	want.Server.Port = 80
+	want.Labels = map[string]string{"app": "web", "team": "infra"}
+	want.Limits = map[string]map[string]int{
+		"cpu": {"min": 1, "max": 8},
+		"mem": {"min": 128},
+	}
+	mustEqual(t, cfg, want)
+}
+
+func TestFileEntries(t *testing.T) {
+	var cfg TestConfig
+	loader := LoaderFor(&cfg, Config{
+		NewParser:    newParser,
+		SkipDefaults: true,
+		SkipEnv:      true,
+		SkipFlags:    true,
+		MergeFiles:   true,
+		FileEntries: []FileEntry{
+			{Path: "testdata/config1.json", FileSystem: configEmbed},
+			{Path: "override.json", FileSystem: fstest.MapFS{
+				"override.json": &fstest.MapFile{Data: []byte(`{"http_port": 9999}`)},
+			}},
+			{Path: "testdata/config3.json"},
+		},
+	})
+	failIfErr(t, loader.Load())
+
+	want := TestConfig{
+		Str:      "111",
+		HTTPPort: 9999,
+		Sub: SubConfig{
+			Float: 333.333,
+		},
+	}
+	mustEqual(t, cfg, want)
+}
+
+func TestFileGroups(t *testing.T) {
+	f := func(fsys fstest.MapFS, want TestConfig) {
+		t.Helper()
+
+		var cfg TestConfig
+		loader := LoaderFor(&cfg, Config{
+			NewParser:    newParser,
+			SkipDefaults: true,
+			SkipEnv:      true,
+			SkipFlags:    true,
+			FileSystem:   fsys,
+			FileGroups: [][]string{
+				{"config.local.json", "config.json"},
+				{"secrets.json"},
+			},
+		})
+		failIfErr(t, loader.Load())
+		mustEqual(t, cfg, want)
+	}
+
+	local := &fstest.MapFile{Data: []byte(`{"str": "local"}`)}
+	base := &fstest.MapFile{Data: []byte(`{"str": "base", "http_port": 1}`)}
+	secrets := &fstest.MapFile{Data: []byte(`{"param": 42}`)}
+
+	f(fstest.MapFS{"config.local.json": local, "config.json": base, "secrets.json": secrets},
+		TestConfig{Str: "local", Param: 42})
+	f(fstest.MapFS{"config.json": base, "secrets.json": secrets},
+		TestConfig{Str: "base", HTTPPort: 1, Param: 42})
+	f(fstest.MapFS{"config.json": base},
+		TestConfig{Str: "base", HTTPPort: 1})
+}
+
+func TestFileGroupsFailOnFileNotFound(t *testing.T) {
+	loader := LoaderFor(&TestConfig{}, Config{
+		NewParser:          newParser,
+		SkipDefaults:       true,
+		SkipEnv:            true,
+		SkipFlags:          true,
+		FailOnFileNotFound: true,
+		FileSystem:         fstest.MapFS{},
+		FileGroups:         [][]string{{"config.local.json", "config.json"}},
+	})
+	failIfOk(t, loader.Load())
+}
+
+func TestProfiles(t *testing.T) {
+	type TestConfig struct {
+		Str string
+		DB  struct {
+			Host string
+			Port int
+		}
+	}
+
+	file := `{
+		"str": "base",
+		"db": {"host": "localhost", "port": 5432},
+		"profiles": {
+			"prod": {"str": "prod", "db": {"host": "db.prod"}},
+			"dev": {"db": {"port": 15432}}
+		}
+	}`
+
+	f := func(profile string, want TestConfig) {
+		t.Helper()
+
+		var cfg TestConfig
+		loader := LoaderFor(&cfg, Config{
+			NewParser:    newParser,
+			SkipDefaults: true,
+			SkipEnv:      true,
+			SkipFlags:    true,
+			Profile:      profile,
+			Files:        []string{"config.json"},
+			FileSystem: fstest.MapFS{
+				"config.json": &fstest.MapFile{Data: []byte(file)},
+			},
+		})
+		failIfErr(t, loader.Load())
+		mustEqual(t, cfg, want)
+	}
+
+	want := TestConfig{Str: "base"}
+	want.DB.Host = "localhost"
+	want.DB.Port = 5432
+	f("", want)
+
+	want.DB.Port = 15432
+	f("dev", want)
+
+	want = TestConfig{Str: "prod"}
+	want.DB.Host = "db.prod"
+	want.DB.Port = 5432
+	f("prod", want)
+}
+
+func TestProfileNotFound(t *testing.T) {
+	loader := LoaderFor(&TestConfig{}, Config{
+		NewParser:    newParser,
+		SkipDefaults: true,
+		SkipEnv:      true,
+		SkipFlags:    true,
+		Profile:      "stage",
+		Files:        []string{"config.json"},
+		FileSystem: fstest.MapFS{
+			"config.json": &fstest.MapFile{Data: []byte(`{"profiles": {"prod": {"str": "prod"}}}`)},
+		},
+	})
+
+	err := loader.Load()
+	failIfOk(t, err)
+	mustEqual(t, err.Error(), `load config: load files: file config.json: profile "stage" not found`)
+}
+
+func TestEmbeddedDefaults(t *testing.T) {
+	type TestConfig struct {
+		Str  string `default:"str-def"`
+		Port int    `default:"1111"`
+		Sub  struct {
+			Float float64 `default:"1.5"`
+		}
+	}
+
+	f := func(first bool, want TestConfig) {
+		t.Helper()
+
+		var cfg TestConfig
+		loader := LoaderFor(&cfg, Config{
+			SkipEnv:   true,
+			SkipFlags: true,
+			Files:     []string{"override.json"},
+			FileSystem: fstest.MapFS{
+				"override.json": &fstest.MapFile{Data: []byte(`{"port": 3333}`)},
+			},
+			EmbeddedDefaults: fstest.MapFS{
+				"defaults.json": &fstest.MapFile{Data: []byte(`{"str": "str-embed", "port": 2222}`)},
+			},
+			EmbeddedDefaultsFile:  "defaults.json",
+			EmbeddedDefaultsFirst: first,
+		})
+		failIfErr(t, loader.Load())
+		mustEqual(t, cfg, want)
+		mustEqual(t, loader.LoadedFiles(), []string{"override.json"})
+		mustEqual(t, loader.Drift().MissingFields, []string{"Str", "Sub.Float"})
+		if !first {
+			mustEqual(t, loader.Explain("Str"), "Str: default defaults.json (key str)")
+		}
+	}
+
+	want := TestConfig{Str: "str-embed", Port: 3333}
+	want.Sub.Float = 1.5
+	f(false, want)
+
+	want.Str = "str-def"
+	f(true, want)
+}
+
 func TestFileFlag(t *testing.T) {
 	file1 := "testdata/config1.json"
 
@@ -410,6 +691,385 @@ func TestFileFlag(t *testing.T) {
 	mustEqual(t, cfg, want)
 }
 
+func TestOnFieldSet(t *testing.T) {
+	type TestConfig struct {
+		Str  string `default:"str-def"`
+		Int  int    `default:"1"`
+		Bool bool
+		Sub  struct {
+			Name string
+		}
+	}
+
+	events := map[string][]string{}
+	loader := LoaderFor(&TestConfig{}, Config{
+		NewParser: newParser,
+		EnvPrefix: "APP",
+		Envs:      []string{"APP_INT=2", "APP_SUB_NAME="},
+		Args:      []string{"-str=str-flag"},
+		Files:     []string{"config.json"},
+		FileSystem: fstest.MapFS{
+			"config.json": {Data: []byte(`{"sub": {"name": "file"}}`)},
+		},
+		OnFieldSet: func(f Field, source string, raw any) {
+			events[f.Name()] = append(events[f.Name()], fmt.Sprintf("%s=%v", source, raw))
+		},
+	})
+	failIfErr(t, loader.Load())
+
+	want := map[string][]string{
+		"Str":      {"default=str-def", "flag=str-flag"},
+		"Int":      {"default=1", "env=2"},
+		"Sub.Name": {"file=file"},
+	}
+	mustEqual(t, events, want)
+}
+
+func TestValidator(t *testing.T) {
+	var calls []string
+	validateCalls = &calls
+
+	cfg := Config{
+		NewParser: newParser,
+		SkipFiles: true,
+		SkipFlags: true,
+		EnvPrefix: "APP",
+		Envs:      []string{"APP_PORT=80", "APP_DB_HOST=localhost"},
+	}
+	failIfErr(t, LoaderFor(&validatedConfig{}, cfg).Load())
+	mustEqual(t, calls, []string{"db", "config"})
+
+	calls = nil
+	cfg.Envs = []string{"APP_PORT=80"}
+	err := LoaderFor(&validatedConfig{}, cfg).Load()
+	failIfOk(t, err)
+	mustEqual(t, err.Error(), "load config: validate DB: host is empty")
+	if !errors.Is(err, errEmptyHost) {
+		t.Fatalf("want wrapped error, got %v", err)
+	}
+
+	cfg.Envs = []string{"APP_DB_HOST=localhost"}
+	err = LoaderFor(&validatedConfig{}, cfg).Load()
+	failIfOk(t, err)
+	mustEqual(t, err.Error(), "load config: validate: port is zero")
+}
+
+var (
+	validateCalls *[]string
+	errEmptyHost  = errors.New("host is empty")
+)
+
+type validatedConfig struct {
+	Port int
+	DB   validatedDB
+}
+
+func (c *validatedConfig) Validate() error {
+	*validateCalls = append(*validateCalls, "config")
+	if c.Port == 0 {
+		return errors.New("port is zero")
+	}
+	return nil
+}
+
+type validatedDB struct {
+	Host string
+}
+
+func (db validatedDB) Validate() error {
+	*validateCalls = append(*validateCalls, "db")
+	if db.Host == "" {
+		return errEmptyHost
+	}
+	return nil
+}
+
+func TestFieldValidator(t *testing.T) {
+	type TestConfig struct {
+		Port int `default:"8080" limit:"65535"`
+		Sub  struct {
+			Name string `default:"name"`
+		}
+	}
+
+	values := map[string]any{}
+	cfg := Config{
+		NewParser: newParser,
+		SkipFiles: true,
+		SkipFlags: true,
+		EnvPrefix: "APP",
+		Envs:      []string{"APP_SUB_NAME=sub"},
+		FieldValidator: func(f Field, value any) error {
+			values[f.Name()] = value
+			return nil
+		},
+	}
+	failIfErr(t, LoaderFor(&TestConfig{}, cfg).Load())
+
+	mustEqual(t, values["Port"], 8080)
+	mustEqual(t, values["Sub.Name"], "sub")
+
+	cfg.Envs = []string{"APP_PORT=70000"}
+	cfg.FieldValidator = func(f Field, value any) error {
+		max, _ := strconv.Atoi(f.Tag("limit"))
+		if v, ok := value.(int); ok && max > 0 && v > max {
+			return fmt.Errorf("%d is more than %d", v, max)
+		}
+		return nil
+	}
+	err := LoaderFor(&TestConfig{}, cfg).Load()
+	failIfOk(t, err)
+	mustEqual(t, err.Error(), "load config: validate field Port: 70000 is more than 65535")
+}
+
+func TestFactories(t *testing.T) {
+	type TestConfig struct {
+		Driver  string
+		Storage testStorage `factory:"Driver"`
+	}
+
+	cfg := Config{
+		NewParser: newParser,
+		SkipFlags: true,
+		Envs:      []string{},
+		Files:     []string{"config.json"},
+		FileSystem: fstest.MapFS{
+			"config.json": {Data: []byte(`{"driver": "postgres", "storage": {"host": "db", "port": 5432}}`)},
+		},
+		Factories: map[reflect.Type]func(kind string) (any, error){
+			reflect.TypeOf((*testStorage)(nil)).Elem(): func(kind string) (any, error) {
+				switch kind {
+				case "postgres":
+					return &pgStorage{}, nil
+				default:
+					return nil, fmt.Errorf("unknown storage %q", kind)
+				}
+			},
+		},
+	}
+
+	var tc TestConfig
+	failIfErr(t, LoaderFor(&tc, cfg).Load())
+	mustEqual(t, tc.Storage, testStorage(&pgStorage{Host: "db", Port: 5432}))
+
+	cfg.FileSystem = fstest.MapFS{
+		"config.json": {Data: []byte(`{"driver": "mysql", "storage": {}}`)},
+	}
+	err := LoaderFor(&TestConfig{}, cfg).Load()
+	failIfOk(t, err)
+	mustEqual(t, err.Error(), `load config: field Storage: unknown storage "mysql"`)
+}
+
+type testStorage interface {
+	Addr() string
+}
+
+type pgStorage struct {
+	Host string
+	Port int
+}
+
+func (s *pgStorage) Addr() string { return fmt.Sprintf("%s:%d", s.Host, s.Port) }
+
+func TestEnvironmentDefaults(t *testing.T) {
+	type TestConfig struct {
+		Workers int    `default:"10" default.prod:"100"`
+		Level   string `default:"debug" default.prod:"warn" default.stage:"info"`
+		Addr    string `default.dev:"localhost:8080"`
+		Name    string `default:"app"`
+	}
+
+	f := func(env string, want TestConfig) {
+		t.Helper()
+
+		var cfg TestConfig
+		loader := LoaderFor(&cfg, Config{
+			NewParser:   newParser,
+			SkipFiles:   true,
+			SkipEnv:     true,
+			SkipFlags:   true,
+			Environment: env,
+		})
+		failIfErr(t, loader.Load())
+		mustEqual(t, cfg, want)
+	}
+
+	f("", TestConfig{Workers: 10, Level: "debug", Name: "app"})
+	f("prod", TestConfig{Workers: 100, Level: "warn", Name: "app"})
+	f("stage", TestConfig{Workers: 10, Level: "info", Name: "app"})
+	f("dev", TestConfig{Workers: 10, Level: "debug", Addr: "localhost:8080", Name: "app"})
+}
+
+func TestDurationUnit(t *testing.T) {
+	type TestConfig struct {
+		Timeout  time.Duration `unit:"s"`
+		Interval time.Duration `unit:"ms" default:"250"`
+		Delay    time.Duration `unit:"s"`
+		Raw      time.Duration
+	}
+
+	var cfg TestConfig
+	loader := LoaderFor(&cfg, Config{
+		NewParser: newParser,
+		SkipFlags: true,
+		Envs:      []string{},
+		Files:     []string{"config.json"},
+		FileSystem: fstest.MapFS{
+			"config.json": {Data: []byte(`{"timeout": 30, "delay": "1m", "raw": "2s"}`)},
+		},
+	})
+	failIfErr(t, loader.Load())
+
+	want := TestConfig{
+		Timeout:  30 * time.Second,
+		Interval: 250 * time.Millisecond,
+		Delay:    time.Minute,
+		Raw:      2 * time.Second,
+	}
+	mustEqual(t, cfg, want)
+}
+
+func TestTimeLayout(t *testing.T) {
+	type TestConfig struct {
+		Start   time.Time `layout:"2006-01-02" default:"2020-01-02"`
+		End     time.Time `layout:"2006-01-02"`
+		Updated time.Time
+		Deleted *time.Time `layout:"02.01.2006"`
+		Created time.Time
+	}
+
+	var cfg TestConfig
+	loader := LoaderFor(&cfg, Config{
+		NewParser: newParser,
+		Envs:      []string{"END=2021-03-04"},
+		Args:      []string{"-deleted=05.06.2022"},
+		Files:     []string{"config.json"},
+		FileSystem: fstest.MapFS{
+			"config.json": {Data: []byte(`{"updated": "2023-07-08T09:10:11Z"}`)},
+		},
+	})
+	failIfErr(t, loader.Load())
+
+	deleted := time.Date(2022, 6, 5, 0, 0, 0, 0, time.UTC)
+	want := TestConfig{
+		Start:   time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC),
+		End:     time.Date(2021, 3, 4, 0, 0, 0, 0, time.UTC),
+		Updated: time.Date(2023, 7, 8, 9, 10, 11, 0, time.UTC),
+		Deleted: &deleted,
+	}
+	mustEqual(t, cfg, want)
+
+	loader = LoaderFor(&cfg, Config{
+		NewParser: newParser,
+		SkipFlags: true,
+		Envs:      []string{"END=04.03.2021"},
+	})
+	failIfOk(t, loader.Load())
+}
+
+func TestExplain(t *testing.T) {
+	loader := LoaderFor(&TestConfig{}, Config{
+		NewParser: newParser,
+		EnvPrefix: "APP",
+		Files:     []string{"testdata/config1.json"},
+		Envs:      []string{"APP_EM=em-env"},
+		Args:      []string{"-str_slice=a,b"},
+	})
+	failIfErr(t, loader.Load())
+
+	mustEqual(t, loader.Explain("Str"), "Str: file testdata/config1.json (key str)")
+	mustEqual(t, loader.Explain("Int"), "Int: default")
+	mustEqual(t, loader.Explain("Param"), "Param: not set")
+	mustEqual(t, loader.Explain("Em"), "Em: env APP_EM")
+	mustEqual(t, loader.Explain("StrSlice"), "StrSlice: flag -str_slice")
+	mustEqual(t, loader.Explain("Nope"), "Nope: unknown field")
+
+	var source ValueSource
+	loader.WalkFields(func(f Field) bool {
+		if f.Name() == "HTTPPort" {
+			source = f.Source()
+		}
+		return true
+	})
+	mustEqual(t, source, ValueSource{Kind: "file", Name: "http_port", File: "testdata/config1.json"})
+}
+
+func TestFlagInfos(t *testing.T) {
+	type TestConfig struct {
+		Port  int    `default:"8080" usage:"port to listen"`
+		Host  string `default:"localhost"`
+		Debug bool   `flag:"-"`
+		DB    struct {
+			User string `default:"admin"`
+		}
+	}
+
+	loader := LoaderFor(&TestConfig{}, Config{
+		NewParser: newParser,
+		SkipFiles: true,
+		SkipEnv:   true,
+		Args:      []string{"-port=8080", "-db.user=root"},
+	})
+	failIfErr(t, loader.Load())
+
+	want := []FlagInfo{
+		{Name: "port", Field: "Port", Usage: "port to listen", Default: "8080", Value: "8080", IsSet: true},
+		{Name: "host", Field: "Host", Default: "localhost"},
+		{Name: "db.user", Field: "DB.User", Default: "admin", Value: "root", IsSet: true},
+	}
+	mustEqual(t, loader.FlagInfos(), want)
+}
+
+func TestCheckRequiredFlags(t *testing.T) {
+	type TestConfig struct {
+		Name  string `required:"true" env:"-"`
+		Port  int    `required:"true"`
+		Mode  string `required:"true" env:"-" default:"fast"`
+		Level string `env:"-"`
+	}
+
+	f := func(args []string) (string, error) {
+		t.Helper()
+
+		loader := LoaderFor(&TestConfig{}, Config{
+			NewParser:          newParser,
+			SkipFiles:          true,
+			Envs:               []string{"PORT=8080"},
+			Args:               args,
+			CheckRequiredFlags: true,
+		})
+		var buf bytes.Buffer
+		loader.Flags().SetOutput(&buf)
+		err := loader.Load()
+		return buf.String(), err
+	}
+
+	usage, err := f([]string{})
+	failIfOk(t, err)
+	mustEqual(t, err.Error(), "load config: required flags are not set: -name")
+	mustEqual(t, strings.Contains(usage, "-name"), true)
+
+	usage, err = f([]string{"-name=app"})
+	failIfErr(t, err)
+	mustEqual(t, usage, "")
+}
+
+func TestLoadedFiles(t *testing.T) {
+	loader := LoaderFor(&TestConfig{}, Config{
+		NewParser:    newParser,
+		SkipDefaults: true,
+		SkipEnv:      true,
+		MergeFiles:   true,
+		FileFlag:     "file_flag",
+		Files:        []string{"testdata/config1.json", "testdata/not_found.json"},
+		Args:         []string{"-file_flag=testdata/config2.json"},
+	})
+	failIfErr(t, loader.Load())
+
+	mustEqual(t, loader.LoadedFiles(), []string{"testdata/config1.json", "testdata/config2.json"})
+	mustEqual(t, loader.MissingFiles(), []string{"testdata/not_found.json"})
+}
+
 func TestBadFileFlag(t *testing.T) {
 	flags := []string{
 		"-file_flag=",
@@ -534,6 +1194,72 @@ func TestFlag(t *testing.T) {
 	mustEqual(t, cfg, want)
 }
 
+func TestRepeatedFlags(t *testing.T) {
+	type TestConfig struct {
+		Tags  []string `default:"x,y"`
+		Hosts []string `default:"localhost,127.0.0.1"`
+		Name  string
+		Ports []int
+	}
+
+	var cfg TestConfig
+	loader := LoaderFor(&cfg, Config{
+		NewParser: newParser,
+		SkipFiles: true,
+		SkipEnv:   true,
+	})
+
+	flags := []string{
+		"-tags=a",
+		"-tags=b,c",
+		"-name=first",
+		"-name=second",
+		"-ports=1",
+		"-ports=2",
+	}
+	failIfErr(t, loader.Flags().Parse(flags))
+	failIfErr(t, loader.Load())
+
+	want := TestConfig{
+		Tags:  []string{"a", "b", "c"},
+		Hosts: []string{"localhost", "127.0.0.1"},
+		Name:  "second",
+		Ports: []int{1, 2},
+	}
+	mustEqual(t, cfg, want)
+}
+
+func TestRepeatedMapFlags(t *testing.T) {
+	type TestConfig struct {
+		Labels map[string]string
+		Limits map[string]int
+		Env    map[string]string `default:"a:1,b:2"`
+	}
+
+	var cfg TestConfig
+	loader := LoaderFor(&cfg, Config{
+		NewParser: newParser,
+		SkipFiles: true,
+		SkipEnv:   true,
+	})
+
+	flags := []string{
+		"-labels=app=web",
+		"-labels=url=http://localhost:8080",
+		"-limits=cpu=2",
+		"-limits=mem:512,disk:10",
+	}
+	failIfErr(t, loader.Flags().Parse(flags))
+	failIfErr(t, loader.Load())
+
+	want := TestConfig{
+		Labels: map[string]string{"app": "web", "url": "http://localhost:8080"},
+		Limits: map[string]int{"cpu": 2, "mem": 512, "disk": 10},
+		Env:    map[string]string{"a": "1", "b": "2"},
+	}
+	mustEqual(t, cfg, want)
+}
+
 func TestExactName(t *testing.T) {
 	t.Setenv("STR", "str-env")
 	t.Setenv("TST_STR", "bar-env")
@@ -582,48 +1308,128 @@ func TestSkipName(t *testing.T) {
 	var cfg ExactConfig
 
 	loader := LoaderFor(&cfg, Config{
-		NewParser: newParser,
-		SkipFiles: true,
-		SkipFlags: true,
+		NewParser: newParser,
+		SkipFiles: true,
+		SkipFlags: true,
+	})
+	failIfErr(t, loader.Load())
+
+	want := ExactConfig{
+		Foo: Foo{
+			String: "str-env",
+		},
+		Bar: "def",
+	}
+	mustEqual(t, cfg, want)
+}
+
+func TestDuplicatedName(t *testing.T) {
+	t.Setenv("FOO_BAR", "str-env")
+	defer os.Clearenv()
+
+	type Foo struct {
+		Bar string
+	}
+	type ExactConfig struct {
+		Foo    Foo
+		FooBar string
+	}
+	var cfg ExactConfig
+
+	loader := LoaderFor(&cfg, Config{
+		NewParser:       newParser,
+		SkipFlags:       true,
+		AllowDuplicates: true,
+	})
+	failIfErr(t, loader.Load())
+
+	want := ExactConfig{
+		Foo: Foo{
+			Bar: "str-env",
+		},
+		FooBar: "str-env",
+	}
+	mustEqual(t, cfg, want)
+}
+
+func TestDuplicatedNameReport(t *testing.T) {
+	type Foo struct {
+		Bar string `default:"first" usage:"first usage"`
+	}
+	type ExactConfig struct {
+		Foo    Foo
+		FooBar string `default:"second"`
+	}
+	var cfg ExactConfig
+
+	loader := LoaderFor(&cfg, Config{
+		NewParser:       newParser,
+		AllowDuplicates: true,
+		EnvPrefix:       "APP",
+		Files:           []string{"config.json"},
+		FileSystem: fstest.MapFS{
+			"config.json": &fstest.MapFile{Data: []byte(`{"foo": {"bar": "file"}, "foo_bar": "file2"}`)},
+		},
+		Args: []string{},
+	})
+	failIfErr(t, loader.Load())
+
+	want := ExactConfig{
+		Foo:    Foo{Bar: "file"},
+		FooBar: "file2",
+	}
+	mustEqual(t, cfg, want)
+
+	wantDupls := []Duplicate{
+		{Source: "env", Name: "APP_FOO_BAR", Fields: []string{"Foo.Bar", "FooBar"}},
+	}
+	mustEqual(t, loader.Duplicates(), wantDupls)
+}
+
+func TestDuplicatesOncePerFormat(t *testing.T) {
+	type TestConfig struct {
+		A string `yaml:"same" env:"SAME"`
+		B string `yaml:"same" env:"SAME"`
+	}
+	var cfg TestConfig
+
+	loader := LoaderFor(&cfg, Config{
+		NewParser:       newParser,
+		AllowDuplicates: true,
+		SkipEnv:         true,
+		Args:            []string{},
+		FileDecoders: map[string]FileDecoder{
+			".yaml": yamlStub{},
+			".yml":  yamlStub{},
+		},
 	})
 	failIfErr(t, loader.Load())
 
-	want := ExactConfig{
-		Foo: Foo{
-			String: "str-env",
-		},
-		Bar: "def",
+	wantDupls := []Duplicate{
+		{Source: "yaml", Name: "same", Fields: []string{"A", "B"}},
 	}
-	mustEqual(t, cfg, want)
+	mustEqual(t, loader.Duplicates(), wantDupls)
 }
 
-func TestDuplicatedName(t *testing.T) {
-	t.Setenv("FOO_BAR", "str-env")
-	defer os.Clearenv()
-
-	type Foo struct {
-		Bar string
-	}
-	type ExactConfig struct {
-		Foo    Foo
-		FooBar string
+func TestDuplicatedFlagFirstWins(t *testing.T) {
+	type TestConfig struct {
+		A string `flag:"same" default:"a" usage:"usage a"`
+		B string `flag:"same" default:"b" usage:"usage b"`
 	}
-	var cfg ExactConfig
+	var cfg TestConfig
 
 	loader := LoaderFor(&cfg, Config{
 		NewParser:       newParser,
-		SkipFlags:       true,
 		AllowDuplicates: true,
+		SkipEnv:         true,
+		SkipFiles:       true,
+		Args:            []string{"-same=flag"},
 	})
 	failIfErr(t, loader.Load())
 
-	want := ExactConfig{
-		Foo: Foo{
-			Bar: "str-env",
-		},
-		FooBar: "str-env",
-	}
-	mustEqual(t, cfg, want)
+	mustEqual(t, loader.Flags().Lookup("same").DefValue, "a")
+	mustEqual(t, loader.Flags().Lookup("same").Usage, "usage a")
+	mustEqual(t, cfg, TestConfig{A: "flag", B: "flag"})
 }
 
 func TestFailOnDuplicatedName(t *testing.T) {
@@ -769,6 +1575,97 @@ func TestBadDefauts(t *testing.T) {
 	}{})
 }
 
+func TestIgnoredField(t *testing.T) {
+	type TestConfig struct {
+		Str     string `default:"str"`
+		Ignored string `default:"def" required:"true" aconfig:"-"`
+		Sub     struct {
+			Int int `default:"10"`
+		} `aconfig:"-"`
+	}
+
+	var cfg TestConfig
+	loader := LoaderFor(&cfg, Config{
+		NewParser:          newParser,
+		AllowUnknownFields: true,
+		EnvPrefix:          "APP",
+		Envs:               []string{"APP_IGNORED=env"},
+		AllowUnknownEnvs:   true,
+		Args:               []string{},
+		Files:              []string{"config.json"},
+		FileSystem: fstest.MapFS{
+			"config.json": {Data: []byte(`{"ignored": "file"}`)},
+		},
+	})
+	failIfErr(t, loader.Load())
+
+	mustEqual(t, cfg.Str, "str")
+	mustEqual(t, cfg.Ignored, "")
+	mustEqual(t, cfg.Sub.Int, 0)
+	if loader.Flags().Lookup("ignored") != nil {
+		t.Fatal("flag for ignored field must not be defined")
+	}
+
+	var names []string
+	loader.WalkFields(func(f Field) bool {
+		names = append(names, f.Name())
+		return true
+	})
+	if !newParser {
+		mustEqual(t, names, []string{"Str"})
+	}
+}
+
+func TestFailOnUnexportedFields(t *testing.T) {
+	type Sub struct {
+		Name  string `default:"name"`
+		token string `env:"TOKEN"`
+	}
+	type TestConfig struct {
+		Str   string `default:"str"`
+		count int
+		Sub   Sub
+	}
+
+	cfg := Config{
+		NewParser: newParser,
+		SkipFiles: true,
+		SkipEnv:   true,
+		SkipFlags: true,
+	}
+	failIfErr(t, LoaderFor(&TestConfig{}, cfg).Load())
+
+	cfg.FailOnUnexportedFields = true
+	err := LoaderFor(&TestConfig{}, cfg).Load()
+	failIfOk(t, err)
+
+	want := `init loader: unexported field Sub.token has "env" tag (see FailOnUnexportedFields config param)`
+	mustEqual(t, err.Error(), want)
+}
+
+func TestBadDefaultsOnInit(t *testing.T) {
+	type TestConfig struct {
+		Str string `default:"str"`
+		Int int    `default:"10s"`
+	}
+
+	loader := LoaderFor(&TestConfig{}, Config{
+		NewParser:    newParser,
+		SkipDefaults: true,
+		SkipFiles:    true,
+		SkipEnv:      true,
+		SkipFlags:    true,
+	})
+
+	err := loader.Load()
+	failIfOk(t, err)
+
+	want := `init loader: incorrect default value for field "Int"`
+	if !strings.HasPrefix(err.Error(), want) {
+		t.Fatalf("got %s", err.Error())
+	}
+}
+
 func TestBadFiles(t *testing.T) {
 	f := func(filepath string) {
 		t.Helper()
@@ -1065,9 +1962,6 @@ func TestDontGenerateTags(t *testing.T) {
 }
 
 func TestWalkFields(t *testing.T) {
-	if newParser {
-		t.Skip()
-	}
 	type TestConfig struct {
 		A int `default:"-1" env:"one" marco:"polo"`
 		B struct {
@@ -1139,6 +2033,46 @@ func TestWalkFields(t *testing.T) {
 	}
 }
 
+func TestFieldAllTags(t *testing.T) {
+	type TestConfig struct {
+		UserName string `default:"admin" marco:"polo" escaped:"a\"b" json:"user"`
+		Port     int    `env:"APP_PORT" default:"1" default:"2"`
+	}
+
+	tags := map[string]map[string]string{}
+	LoaderFor(&TestConfig{}, Config{NewParser: newParser, SkipFlags: true}).WalkFields(func(f Field) bool {
+		tags[f.Name()] = f.AllTags()
+		return true
+	})
+
+	want := map[string]map[string]string{
+		"UserName": {
+			"default": "admin",
+			"marco":   "polo",
+			"escaped": `a"b`,
+			"env":     "USER_NAME",
+			"flag":    "user_name",
+			"json":    "user",
+			"usage":   "",
+		},
+		"Port": {
+			"default": "1",
+			"env":     "APP_PORT",
+			"flag":    "port",
+			"json":    "port",
+			"usage":   "",
+		},
+	}
+	mustEqual(t, tags, want)
+
+	// a copy is returned.
+	LoaderFor(&TestConfig{}, Config{NewParser: newParser, SkipFlags: true}).WalkFields(func(f Field) bool {
+		f.AllTags()["env"] = "changed"
+		mustEqual(t, f.Tag("env") != "changed", true)
+		return true
+	})
+}
+
 func TestDontFillFlagsIfDisabled(t *testing.T) {
 	loader := LoaderFor(&TestConfig{}, Config{
 		NewParser: newParser,
@@ -1212,7 +2146,7 @@ func TestMissingFieldWithRequiredTag(t *testing.T) {
 	})
 
 	err := loader.Load()
-	want := "load config: fields required but not set: Field1"
+	want := "load config: fields required but not set: Field1 (env FIELD_1, json field_1)"
 
 	if have := err.Error(); have != want {
 		t.Fatalf("got %v, want %v", err, want)
@@ -1229,13 +2163,72 @@ func TestMissingFieldsWithRequiredTag(t *testing.T) {
 	})
 
 	err := loader.Load()
-	want := "load config: fields required but not set: Field1,Field2"
+	want := "load config: fields required but not set: Field1 (env FIELD_1, json field_1); Field2 (env FIELD_2, json field_2)"
 
 	if have := err.Error(); have != want {
 		t.Fatalf("got %v, want %v", err, want)
 	}
 }
 
+func TestAllowEmpty(t *testing.T) {
+	type TestConfig struct {
+		Str   string `default:"str-def" aconfig:",allowempty"`
+		Ptr   *int   `default:"42" aconfig:",allowempty"`
+		NoTag string `default:"no-tag"`
+		Req   string `required:"true" aconfig:",allowempty"`
+	}
+
+	var cfg TestConfig
+	loader := LoaderFor(&cfg, Config{
+		SkipFiles: true,
+		SkipFlags: true,
+		Envs:      []string{"STR=", "PTR=", "NO_TAG=", "REQ="},
+	})
+	failIfErr(t, loader.Load())
+
+	want := TestConfig{
+		Str:   "",
+		Ptr:   new(int),
+		NoTag: "no-tag",
+		Req:   "",
+	}
+	mustEqual(t, cfg, want)
+}
+
+func TestEmptyDoesNotSatisfyRequired(t *testing.T) {
+	type TestConfig struct {
+		Req string `required:"true"`
+	}
+
+	loader := LoaderFor(&TestConfig{}, Config{
+		SkipFiles: true,
+		SkipFlags: true,
+		Envs:      []string{"REQ="},
+	})
+	failIfOk(t, loader.Load())
+}
+
+func TestMissingFieldGuidance(t *testing.T) {
+	type TestConfig struct {
+		Auth struct {
+			User string `required:"true"`
+			Pass string `required:"true" env:"-" json:"password"`
+		}
+	}
+	loader := LoaderFor(&TestConfig{}, Config{
+		EnvPrefix:  "APP",
+		FlagPrefix: "app",
+		Envs:       []string{},
+		Args:       []string{},
+	})
+
+	err := loader.Load()
+	want := "load config: fields required but not set: " +
+		"Auth.User (env APP_AUTH_USER, flag -app.auth.user, json auth.user); " +
+		"Auth.Pass (flag -app.auth.pass, json auth.password)"
+	mustEqual(t, err.Error(), want)
+}
+
 func int32Ptr(a int32) *int32 {
 	return &a
 }
@@ -1647,6 +2640,73 @@ func failIfOk(tb testing.TB, err error) {
 	}
 }
 
+// fsDecoder decodes JSON only with DecodeFile from a file system set by Init.
+type fsDecoder struct {
+	fsys fs.FS
+}
+
+func (d *fsDecoder) Init(fsys fs.FS) { d.fsys = fsys }
+
+func (d *fsDecoder) Format() string { return "json" }
+
+func (d *fsDecoder) DecodeFile(name string) (map[string]any, error) {
+	data, err := fs.ReadFile(d.fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	res := map[string]any{}
+	err = json.Unmarshal(data, &res)
+	return res, err
+}
+
+// lineDecoder decodes "key=value" lines only with DecodeReader.
+type lineDecoder struct{}
+
+func (lineDecoder) Format() string { return "json" }
+
+func (lineDecoder) DecodeFile(string) (map[string]any, error) {
+	return nil, errors.New("DecodeFile must not be called")
+}
+
+func (lineDecoder) DecodeReader(r io.Reader) (map[string]any, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	res := map[string]any{}
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		key, value, _ := strings.Cut(line, "=")
+		res[key] = value
+	}
+	return res, nil
+}
+
+func TestReaderDecoder(t *testing.T) {
+	type TestConfig struct {
+		Host string
+		Port int
+	}
+
+	var cfg TestConfig
+	loader := LoaderFor(&cfg, Config{
+		NewParser:    newParser,
+		SkipDefaults: true,
+		SkipEnv:      true,
+		SkipFlags:    true,
+		StreamFiles:  true,
+		FileDecoders: map[string]FileDecoder{".lines": lineDecoder{}},
+		Files:        []string{"config.lines"},
+		FileSystem: fstest.MapFS{
+			"config.lines": {Data: []byte("host=localhost\nport=8080\n")},
+		},
+	})
+	failIfErr(t, loader.Load())
+	mustEqual(t, cfg, TestConfig{Host: "localhost", Port: 8080})
+
+	// file is read once while decoding, the hash is recorded.
+	mustEqual(t, len(loader.Inputs().Hashes["config.lines"]), 64)
+}
+
 func failIfErr(tb testing.TB, err error) {
 	tb.Helper()
 	if err != nil {
@@ -1660,3 +2720,204 @@ func mustEqual(tb testing.TB, got, want any) {
 		tb.Fatalf("\nhave %+v\nwant %+v", got, want)
 	}
 }
+
+func BenchmarkLoad(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		loader := LoaderFor(&TestConfig{}, Config{
+			SkipFlags: true,
+			EnvPrefix: "APP",
+			Files:     []string{"testdata/config1.json"},
+		})
+		if err := loader.Load(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkReload(b *testing.B) {
+	loader := LoaderFor(&TestConfig{}, Config{
+		SkipFlags: true,
+		EnvPrefix: "APP",
+		Files:     []string{"testdata/config1.json"},
+	})
+	if err := loader.Load(); err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := loader.reload(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkLoadHugeEnv(b *testing.B) {
+	envs := make([]string, 0, 10_000)
+	for i := 0; i < cap(envs); i++ {
+		envs = append(envs, fmt.Sprintf("SOME_INJECTED_VAR_%d=value-%d", i, i))
+	}
+	envs = append(envs, "APP_STR=str-env", "APP_HTTP_PORT=9000")
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		loader := LoaderFor(&TestConfig{}, Config{
+			SkipFlags: true,
+			SkipFiles: true,
+			EnvPrefix: "APP",
+			Envs:      envs,
+		})
+		if err := loader.Load(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestSharedDecoder(t *testing.T) {
+	type TestConfig struct {
+		Port int
+	}
+	decoders := map[string]FileDecoder{".json": &fsDecoder{}}
+
+	load := func(port string) {
+		defer func() {
+			if r := recover(); r != nil {
+				t.Error(r)
+			}
+		}()
+		var cfg TestConfig
+		loader := LoaderFor(&cfg, Config{
+			NewParser:    newParser,
+			SkipFlags:    true,
+			SkipEnv:      true,
+			Files:        []string{"config.json"},
+			FileDecoders: decoders,
+			FileSystem: fstest.MapFS{
+				"config.json": &fstest.MapFile{Data: []byte(`{"port": ` + port + `}`)},
+			},
+		})
+		if err := loader.Load(); err != nil {
+			t.Error(err)
+			return
+		}
+		if strconv.Itoa(cfg.Port) != port {
+			t.Errorf("have %d, want %s", cfg.Port, port)
+		}
+	}
+
+	var wg sync.WaitGroup
+	for _, port := range []string{"1111", "2222"} {
+		port := port
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				load(port)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// the shared decoder isn't changed.
+	mustEqual(t, decoders[".json"].(*fsDecoder).fsys == nil, true)
+}
+
+func TestNotObjectForStruct(t *testing.T) {
+	var cfg struct {
+		Subs []struct {
+			Name string
+		}
+	}
+	err := LoaderFor(&cfg, Config{
+		SkipFlags: true,
+		Envs:      []string{},
+		Files:     []string{"config.json"},
+		FileSystem: fstest.MapFS{
+			"config.json": &fstest.MapFile{Data: []byte(`{"subs": [5]}`)},
+		},
+	}).Load()
+	failIfOk(t, err)
+}
+
+func TestEnvIndex(t *testing.T) {
+	type TestConfig struct {
+		Port int
+		Sub  struct {
+			Name string
+		}
+	}
+
+	var cfg TestConfig
+	loader := LoaderFor(&cfg, Config{
+		NewParser: newParser,
+		SkipFlags: true,
+		Envs:      []string{"PORT=80", "SUB_NAME=name", "HOME=/root"},
+	})
+	failIfErr(t, loader.Load())
+
+	mustEqual(t, len(loader.envIndex), 2)
+	mustEqual(t, loader.envIndex["PORT"], []int{0})
+	mustEqual(t, cfg.Sub.Name, "name")
+}
+
+func TestConcurrentLoad(t *testing.T) {
+	type TestConfig struct {
+		Port int    `default:"8080"`
+		Host string `default:"localhost"`
+	}
+
+	var cfg TestConfig
+	loader := LoaderFor(&cfg, Config{
+		NewParser: newParser,
+		Envs:      []string{"PORT=9000"},
+		Args:      []string{"-host=example.com"},
+	})
+
+	var wg sync.WaitGroup
+	readers := []func(){
+		func() {
+			if loader.Flags().Lookup("port") == nil {
+				t.Error("flag port not found")
+			}
+		},
+		func() {
+			loader.WalkFields(func(f Field) bool {
+				_ = f.Source()
+				return true
+			})
+		},
+		func() { _ = loader.Explain("Port") },
+		func() { _ = loader.FlagInfos() },
+		func() { _ = loader.Report() },
+		func() { _ = loader.Lint() },
+		func() { _ = loader.Drift() },
+		func() { _ = loader.Inputs() },
+		func() { _ = loader.Profile() },
+		func() { _ = loader.Health() },
+		func() { _ = loader.Dump(io.Discard, "json") },
+		func() { _ = loader.WriteDotenv(io.Discard, DotenvOptions{}) },
+		func() { _ = loader.GenerateDocs(io.Discard) },
+		func() { _ = loader.WriteExample(io.Discard, "json") },
+		func() { _, _ = loader.JSONSchema() },
+	}
+	for _, read := range readers {
+		read := read
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				read()
+			}
+		}()
+	}
+
+	for i := 0; i < 20; i++ {
+		failIfErr(t, loader.Load())
+	}
+	wg.Wait()
+
+	mustEqual(t, cfg, TestConfig{Port: 9000, Host: "example.com"})
+	mustEqual(t, loader.Explain("Port"), "Port: env PORT")
+}
@@ -0,0 +1,113 @@
+package aconfigconsul
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+)
+
+// Source of config values stored in Consul KV for aconfig.
+// Keys under the prefix are matched with the field names for env:
+// with prefix "app/" both "app/DB_HOST" and "app/db/host" are loaded into a field DB.Host.
+type Source struct {
+	kv     *api.KV
+	prefix string
+	config Config
+}
+
+// Config for the Consul source.
+type Config struct {
+	// Datacenter to query, default is the datacenter of the agent.
+	Datacenter string
+
+	// Token to use for requests, default is the token of the client.
+	Token string
+
+	// WaitTime is the maximum duration of a blocking query in Watch.
+	// Default is 0, then Consul default (5 minutes) is used.
+	WaitTime time.Duration
+}
+
+// New Consul source for aconfig which loads keys under the prefix.
+func New(client *api.Client, prefix string, cfg Config) *Source {
+	return &Source{
+		kv:     client.KV(),
+		prefix: prefix,
+		config: cfg,
+	}
+}
+
+// Name of the source.
+func (s *Source) Name() string {
+	return "consul:" + s.prefix
+}
+
+// Format of the source, keys are matched with env names.
+func (s *Source) Format() string {
+	return "env"
+}
+
+// Load implements aconfig.Source.
+func (s *Source) Load(ctx context.Context) (map[string]any, error) {
+	values, _, err := s.list(ctx, 0)
+	return values, err
+}
+
+// Watch implements aconfig.SourceWatcher with Consul blocking queries,
+// notify is called when any key under the prefix is changed.
+func (s *Source) Watch(ctx context.Context, notify func()) error {
+	_, index, err := s.list(ctx, 0)
+	if err != nil {
+		return err
+	}
+
+	for {
+		_, newIndex, err := s.list(ctx, index)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		switch {
+		case newIndex < index:
+			// index went backwards (like after a snapshot restore), start over.
+			index = 0
+		case newIndex > index:
+			index = newIndex
+			notify()
+		}
+	}
+}
+
+func (s *Source) list(ctx context.Context, index uint64) (map[string]any, uint64, error) {
+	opts := &api.QueryOptions{
+		Datacenter: s.config.Datacenter,
+		Token:      s.config.Token,
+		WaitIndex:  index,
+		WaitTime:   s.config.WaitTime,
+	}
+
+	pairs, meta, err := s.kv.List(s.prefix, opts.WithContext(ctx))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	res := make(map[string]any, len(pairs))
+	for _, pair := range pairs {
+		key := strings.Trim(strings.TrimPrefix(pair.Key, s.prefix), "/")
+		if key == "" || strings.HasSuffix(pair.Key, "/") {
+			continue
+		}
+		res[envName(key)] = string(pair.Value)
+	}
+	return res, meta.LastIndex, nil
+}
+
+// envName converts Consul key path to env name: db/host to DB_HOST.
+func envName(key string) string {
+	return strings.ToUpper(strings.ReplaceAll(key, "/", "_"))
+}
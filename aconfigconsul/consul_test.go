@@ -0,0 +1,167 @@
+package aconfigconsul_test
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/cristalhq/aconfig"
+	"github.com/cristalhq/aconfig/aconfigconsul"
+	"github.com/hashicorp/consul/api"
+)
+
+func TestConsulLoad(t *testing.T) {
+	srv := newConsulServer(t, map[string]string{
+		"app/PORT":    "8080",
+		"app/db/host": "localhost",
+		"app/DB_USER": "admin",
+		"app/db/":     "",
+		"other/PORT":  "1",
+	})
+
+	var cfg struct {
+		Port int
+		DB   struct {
+			Host string
+			User string
+		}
+	}
+	loader := aconfig.LoaderFor(&cfg, aconfig.Config{
+		SkipDefaults: true,
+		SkipFiles:    true,
+		SkipEnv:      true,
+		SkipFlags:    true,
+		Sources: []aconfig.Source{
+			aconfigconsul.New(srv.client(t), "app/", aconfigconsul.Config{
+				Datacenter: "dc2",
+				Token:      "secret",
+			}),
+		},
+	})
+	if err := loader.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Port != 8080 {
+		t.Fatalf("have: %v", cfg.Port)
+	}
+	if cfg.DB.Host != "localhost" || cfg.DB.User != "admin" {
+		t.Fatalf("have: %+v", cfg.DB)
+	}
+	if srv.dc != "dc2" || srv.token != "secret" {
+		t.Fatalf("have: %v %v", srv.dc, srv.token)
+	}
+}
+
+func TestConsulWatch(t *testing.T) {
+	srv := newConsulServer(t, map[string]string{"app/PORT": "8080"})
+	src := aconfigconsul.New(srv.client(t), "app/", aconfigconsul.Config{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	notified := make(chan struct{})
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- src.Watch(ctx, func() { notified <- struct{}{} })
+	}()
+
+	<-srv.blocked
+	srv.set("app/PORT", "9090")
+	<-notified
+
+	values, err := src.Load(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values["PORT"] != "9090" {
+		t.Fatalf("have: %v", values)
+	}
+
+	cancel()
+	if err := <-errCh; err != nil {
+		t.Fatal(err)
+	}
+}
+
+type consulServer struct {
+	*httptest.Server
+
+	mu      sync.Mutex
+	changed *sync.Cond
+	data    map[string]string
+	index   uint64
+	blocked chan struct{}
+	dc      string
+	token   string
+}
+
+func newConsulServer(t *testing.T, data map[string]string) *consulServer {
+	s := &consulServer{data: data, index: 1, blocked: make(chan struct{}, 1)}
+	s.changed = sync.NewCond(&s.mu)
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	t.Cleanup(func() {
+		s.mu.Lock()
+		s.index = 0
+		s.changed.Broadcast()
+		s.mu.Unlock()
+		s.Close()
+	})
+	return s
+}
+
+func (s *consulServer) client(t *testing.T) *api.Client {
+	client, err := api.NewClient(&api.Config{Address: s.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func (s *consulServer) set(key, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data[key] = value
+	s.index++
+	s.changed.Broadcast()
+}
+
+func (s *consulServer) handle(w http.ResponseWriter, r *http.Request) {
+	prefix := strings.TrimPrefix(r.URL.Path, "/v1/kv/")
+	query := r.URL.Query()
+	wait, _ := strconv.ParseUint(query.Get("index"), 10, 64)
+
+	s.mu.Lock()
+	s.dc = query.Get("dc")
+	s.token = r.Header.Get("X-Consul-Token")
+	if wait != 0 && s.index == wait {
+		select {
+		case s.blocked <- struct{}{}:
+		default:
+		}
+	}
+	for wait != 0 && s.index == wait {
+		s.changed.Wait()
+	}
+
+	pairs := []map[string]any{}
+	for key, value := range s.data {
+		if strings.HasPrefix(key, prefix) {
+			pairs = append(pairs, map[string]any{
+				"Key":   key,
+				"Value": base64.StdEncoding.EncodeToString([]byte(value)),
+			})
+		}
+	}
+	index := s.index
+	s.mu.Unlock()
+
+	w.Header().Set("X-Consul-Index", strconv.FormatUint(index, 10))
+	_ = json.NewEncoder(w).Encode(pairs)
+}
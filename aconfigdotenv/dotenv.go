@@ -1,6 +1,7 @@
 package aconfigdotenv
 
 import (
+	"io"
 	"io/fs"
 
 	"github.com/joho/godotenv"
@@ -21,12 +22,18 @@ func (d *Decoder) Format() string {
 
 // DecodeFile implements aconfig.FileDecoder.
 func (d *Decoder) DecodeFile(filename string) (map[string]interface{}, error) {
-	file, err := d.fsys.Open(filename)
+	f, err := d.fsys.Open(filename)
 	if err != nil {
 		return nil, err
 	}
+	defer f.Close()
 
-	raw, err := godotenv.Parse(file)
+	return d.DecodeReader(f)
+}
+
+// DecodeReader implements aconfig.ReaderDecoder.
+func (d *Decoder) DecodeReader(r io.Reader) (map[string]interface{}, error) {
+	raw, err := godotenv.Parse(r)
 	if err != nil {
 		return nil, err
 	}
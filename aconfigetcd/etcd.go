@@ -0,0 +1,103 @@
+package aconfigetcd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// Source of config values stored in etcd v3 for aconfig.
+// Keys under the prefix are split by "/" into nested values:
+// with prefix "app/" a key "app/db/host" is loaded as a field with JSON name "db.host".
+type Source struct {
+	kv      clientv3.KV
+	watcher clientv3.Watcher
+	prefix  string
+}
+
+// New etcd source for aconfig which loads keys under the prefix.
+func New(client *clientv3.Client, prefix string) *Source {
+	return NewWithKV(client, client, prefix)
+}
+
+// NewWithKV returns etcd source which uses the given KV and Watcher.
+// Watcher can be nil, then Watch returns an error.
+func NewWithKV(kv clientv3.KV, watcher clientv3.Watcher, prefix string) *Source {
+	return &Source{
+		kv:      kv,
+		watcher: watcher,
+		prefix:  prefix,
+	}
+}
+
+// Name of the source.
+func (s *Source) Name() string {
+	return "etcd:" + s.prefix
+}
+
+// Load implements aconfig.Source.
+func (s *Source) Load(ctx context.Context) (map[string]any, error) {
+	resp, err := s.kv.Get(ctx, s.prefix, clientv3.WithPrefix())
+	if err != nil {
+		return nil, err
+	}
+
+	res := map[string]any{}
+	for _, kv := range resp.Kvs {
+		key := strings.Trim(strings.TrimPrefix(string(kv.Key), s.prefix), "/")
+		if key == "" {
+			continue
+		}
+		if err := setNested(res, strings.Split(key, "/"), string(kv.Value)); err != nil {
+			return nil, fmt.Errorf("key %q: %w", kv.Key, err)
+		}
+	}
+	return res, nil
+}
+
+// Watch implements aconfig.SourceWatcher, notify is called on every change under the prefix.
+func (s *Source) Watch(ctx context.Context, notify func()) error {
+	if s.watcher == nil {
+		return errors.New("watcher isn't set")
+	}
+
+	ch := s.watcher.Watch(ctx, s.prefix, clientv3.WithPrefix())
+	for resp := range ch {
+		if err := resp.Err(); err != nil {
+			return err
+		}
+		if len(resp.Events) > 0 {
+			notify()
+		}
+	}
+
+	if ctx.Err() != nil {
+		return nil
+	}
+	return errors.New("watch channel closed")
+}
+
+func setNested(m map[string]any, keys []string, value any) error {
+	for _, key := range keys[:len(keys)-1] {
+		switch sub := m[key].(type) {
+		case map[string]any:
+			m = sub
+		case nil:
+			next := map[string]any{}
+			m[key] = next
+			m = next
+		default:
+			return fmt.Errorf("%q has both a value and nested keys", key)
+		}
+	}
+
+	key := keys[len(keys)-1]
+	if _, ok := m[key].(map[string]any); ok {
+		return fmt.Errorf("%q has both a value and nested keys", key)
+	}
+	m[key] = value
+	return nil
+}
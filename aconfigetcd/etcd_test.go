@@ -0,0 +1,123 @@
+package aconfigetcd_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/cristalhq/aconfig"
+	"github.com/cristalhq/aconfig/aconfigetcd"
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+func TestEtcdLoad(t *testing.T) {
+	kv := &fakeKV{data: map[string]string{
+		"app/port":    "8080",
+		"app/db/host": "localhost",
+		"app/db/user": "admin",
+		"other/port":  "1",
+	}}
+
+	var cfg struct {
+		Port int
+		DB   struct {
+			Host string
+			User string
+		}
+	}
+	loader := aconfig.LoaderFor(&cfg, aconfig.Config{
+		SkipDefaults: true,
+		SkipFiles:    true,
+		SkipEnv:      true,
+		SkipFlags:    true,
+		Sources:      []aconfig.Source{aconfigetcd.NewWithKV(kv, nil, "app/")},
+	})
+	if err := loader.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Port != 8080 {
+		t.Fatalf("have: %v", cfg.Port)
+	}
+	if cfg.DB.Host != "localhost" || cfg.DB.User != "admin" {
+		t.Fatalf("have: %+v", cfg.DB)
+	}
+}
+
+func TestEtcdLoadConflict(t *testing.T) {
+	kv := &fakeKV{data: map[string]string{
+		"app/db":      "value",
+		"app/db/host": "localhost",
+	}}
+
+	_, err := aconfigetcd.NewWithKV(kv, nil, "app/").Load(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "both a value and nested keys") {
+		t.Fatalf("have: %v", err)
+	}
+}
+
+func TestEtcdWatch(t *testing.T) {
+	ch := make(chan clientv3.WatchResponse, 2)
+	ch <- clientv3.WatchResponse{Events: []*clientv3.Event{{}}}
+	close(ch)
+
+	src := aconfigetcd.NewWithKV(&fakeKV{}, &fakeWatcher{ch: ch}, "app/")
+
+	var notified int
+	err := src.Watch(context.Background(), func() { notified++ })
+	if err == nil || err.Error() != "watch channel closed" {
+		t.Fatalf("have: %v", err)
+	}
+	if notified != 1 {
+		t.Fatalf("have: %v", notified)
+	}
+}
+
+func TestEtcdWatchWithoutWatcher(t *testing.T) {
+	src := aconfigetcd.NewWithKV(&fakeKV{}, nil, "app/")
+
+	err := src.Watch(context.Background(), func() {})
+	if err == nil {
+		t.Fatal("must be an error")
+	}
+}
+
+func TestEtcdLoadError(t *testing.T) {
+	kv := &fakeKV{err: errors.New("unavailable")}
+
+	_, err := aconfigetcd.NewWithKV(kv, nil, "app/").Load(context.Background())
+	if err == nil || err.Error() != "unavailable" {
+		t.Fatalf("have: %v", err)
+	}
+}
+
+type fakeKV struct {
+	clientv3.KV
+	data map[string]string
+	err  error
+}
+
+func (kv *fakeKV) Get(ctx context.Context, key string, opts ...clientv3.OpOption) (*clientv3.GetResponse, error) {
+	if kv.err != nil {
+		return nil, kv.err
+	}
+
+	resp := &clientv3.GetResponse{}
+	for k, v := range kv.data {
+		if strings.HasPrefix(k, key) {
+			resp.Kvs = append(resp.Kvs, &mvccpb.KeyValue{Key: []byte(k), Value: []byte(v)})
+		}
+	}
+	return resp, nil
+}
+
+type fakeWatcher struct {
+	clientv3.Watcher
+	ch chan clientv3.WatchResponse
+}
+
+func (w *fakeWatcher) Watch(ctx context.Context, key string, opts ...clientv3.OpOption) clientv3.WatchChan {
+	return w.ch
+}
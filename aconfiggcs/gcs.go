@@ -0,0 +1,76 @@
+package aconfiggcs
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// DefaultEndpoint of Google Cloud Storage JSON API.
+const DefaultEndpoint = "https://storage.googleapis.com"
+
+// Fetcher of config files stored in Google Cloud Storage for aconfig.
+// Register it for "gs" scheme in aconfig.Config.FileFetchers to load files like gs://bucket/config.yaml.
+type Fetcher struct {
+	client   *http.Client
+	endpoint string
+}
+
+// New GCS fetcher for aconfig. Client must authorize requests,
+// like the one from golang.org/x/oauth2/google.DefaultClient with storage read scope.
+func New(client *http.Client) *Fetcher {
+	return NewWithEndpoint(client, DefaultEndpoint)
+}
+
+// NewWithEndpoint returns GCS fetcher which uses a custom endpoint, like an emulator.
+func NewWithEndpoint(client *http.Client, endpoint string) *Fetcher {
+	return &Fetcher{
+		client:   client,
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+	}
+}
+
+// Fetch implements aconfig.FileFetcher.
+func (f *Fetcher) Fetch(ctx context.Context, rawURL string) ([]byte, error) {
+	bucket, object, err := parseURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	u := fmt.Sprintf("%s/storage/v1/b/%s/o/%s?alt=media", f.endpoint, url.PathEscape(bucket), url.PathEscape(object))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, fmt.Errorf("%w: object %s in bucket %s", fs.ErrNotExist, object, bucket)
+	case resp.StatusCode != http.StatusOK:
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// parseURL returns bucket and object from gs://bucket/object.
+func parseURL(rawURL string) (bucket, object string, err error) {
+	if !strings.HasPrefix(rawURL, "gs://") {
+		return "", "", fmt.Errorf("URL %q must start with gs://", rawURL)
+	}
+	bucket, object, ok := strings.Cut(strings.TrimPrefix(rawURL, "gs://"), "/")
+	if !ok || bucket == "" || object == "" {
+		return "", "", fmt.Errorf("URL %q must be like gs://bucket/object", rawURL)
+	}
+	return bucket, object, nil
+}
@@ -0,0 +1,75 @@
+package aconfiggcs_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cristalhq/aconfig"
+	"github.com/cristalhq/aconfig/aconfiggcs"
+)
+
+func TestGCS(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("alt") != "media" {
+			http.Error(w, "metadata isn't supported", http.StatusBadRequest)
+			return
+		}
+		switch r.URL.EscapedPath() {
+		case "/storage/v1/b/configs/o/prod%2Fapp.json":
+			fmt.Fprint(w, `{"port": 8080, "db": {"host": "db.prod"}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	var cfg struct {
+		Port int
+		DB   struct {
+			Host string
+		}
+	}
+	loader := aconfig.LoaderFor(&cfg, aconfig.Config{
+		SkipDefaults: true,
+		SkipEnv:      true,
+		SkipFlags:    true,
+		MergeFiles:   true,
+		Files:        []string{"gs://configs/prod/app.json", "gs://configs/prod/local.json"},
+		FileFetchers: map[string]aconfig.FileFetcher{
+			"gs": aconfiggcs.NewWithEndpoint(srv.Client(), srv.URL),
+		},
+	})
+	if err := loader.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Port != 8080 || cfg.DB.Host != "db.prod" {
+		t.Fatalf("have: %+v", cfg)
+	}
+	if missing := loader.MissingFiles(); len(missing) != 1 || missing[0] != "gs://configs/prod/local.json" {
+		t.Fatalf("have: %v", missing)
+	}
+}
+
+func TestGCSErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "forbidden", http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	fetcher := aconfiggcs.NewWithEndpoint(srv.Client(), srv.URL)
+
+	_, err := fetcher.Fetch(context.Background(), "gs://configs/app.json")
+	if err == nil || err.Error() != "403 Forbidden: forbidden" {
+		t.Fatalf("have: %v", err)
+	}
+
+	for _, url := range []string{"gs://bucket", "gs:///object", "s3://bucket/object"} {
+		if _, err := fetcher.Fetch(context.Background(), url); err == nil {
+			t.Fatalf("must be an error for %q", url)
+		}
+	}
+}
@@ -0,0 +1,7 @@
+module github.com/cristalhq/aconfig/aconfiggcs
+
+go 1.18
+
+require github.com/cristalhq/aconfig v0.20.0
+
+require github.com/mitchellh/mapstructure v1.5.0 // indirect
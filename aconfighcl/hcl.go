@@ -1,6 +1,7 @@
 package aconfighcl
 
 import (
+	"io"
 	"io/fs"
 
 	"github.com/hashicorp/hcl"
@@ -21,7 +22,18 @@ func (d *Decoder) Format() string {
 
 // DecodeFile implements aconfig.FileDecoder.
 func (d *Decoder) DecodeFile(filename string) (map[string]interface{}, error) {
-	b, err := fs.ReadFile(d.fsys, filename)
+	f, err := d.fsys.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return d.DecodeReader(f)
+}
+
+// DecodeReader implements aconfig.ReaderDecoder.
+func (d *Decoder) DecodeReader(r io.Reader) (map[string]interface{}, error) {
+	b, err := io.ReadAll(r)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,10 @@
+module github.com/cristalhq/aconfig/aconfigini
+
+go 1.18
+
+require (
+	github.com/cristalhq/aconfig v0.19.0
+	gopkg.in/ini.v1 v1.67.0
+)
+
+require github.com/mitchellh/mapstructure v1.5.0 // indirect
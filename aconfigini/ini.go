@@ -0,0 +1,73 @@
+package aconfigini
+
+import (
+	"io"
+	"io/fs"
+	"strings"
+
+	"gopkg.in/ini.v1"
+)
+
+// Decoder of INI files for aconfig.
+// Sections are nested objects, dots in a section name make deeper levels: [db.primary].
+// Keys before the first section are top-level keys.
+// Register it for both ".ini" and ".cfg" extensions to read legacy configs.
+type Decoder struct {
+	fsys fs.FS
+}
+
+// New INI decoder for aconfig.
+func New() *Decoder { return &Decoder{} }
+
+// Format of the decoder.
+func (d *Decoder) Format() string {
+	return "ini"
+}
+
+// DecodeFile implements aconfig.FileDecoder.
+func (d *Decoder) DecodeFile(filename string) (map[string]interface{}, error) {
+	f, err := d.fsys.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return d.DecodeReader(f)
+}
+
+// DecodeReader implements aconfig.ReaderDecoder.
+func (d *Decoder) DecodeReader(r io.Reader) (map[string]interface{}, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := ini.Load(data)
+	if err != nil {
+		return nil, err
+	}
+
+	res := map[string]interface{}{}
+	for _, section := range file.Sections() {
+		dst := res
+		if name := section.Name(); name != ini.DefaultSection {
+			for _, key := range strings.Split(name, ".") {
+				sub, ok := dst[key].(map[string]interface{})
+				if !ok {
+					sub = map[string]interface{}{}
+					dst[key] = sub
+				}
+				dst = sub
+			}
+		}
+		for _, key := range section.Keys() {
+			dst[key.Name()] = key.Value()
+		}
+	}
+	return res, nil
+}
+
+// DecodeFile implements aconfig.FileDecoder.
+func (d *Decoder) Init(fsys fs.FS) {
+	d.fsys = fsys
+}
@@ -0,0 +1,60 @@
+package aconfigini_test
+
+import (
+	"embed"
+	"reflect"
+	"testing"
+
+	"github.com/cristalhq/aconfig"
+	"github.com/cristalhq/aconfig/aconfigini"
+)
+
+//go:embed testdata
+var configEmbed embed.FS
+
+func TestINIEmbed(t *testing.T) {
+	type DB struct {
+		Host    string
+		Port    int
+		Replica struct {
+			Host  string
+			Hosts []string
+		}
+	}
+	var cfg struct {
+		Foo string
+		Bar string
+		DB  DB
+	}
+	loader := aconfig.LoaderFor(&cfg, aconfig.Config{
+		SkipDefaults:       true,
+		SkipEnv:            true,
+		SkipFlags:          true,
+		FailOnFileNotFound: true,
+		FileDecoders: map[string]aconfig.FileDecoder{
+			".ini": aconfigini.New(),
+		},
+		Files:      []string{"testdata/config.ini"},
+		FileSystem: configEmbed,
+	})
+
+	if err := loader.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Foo != "value1" {
+		t.Fatalf("have: %v", cfg.Foo)
+	}
+	if cfg.Bar != "value2" {
+		t.Fatalf("have: %v", cfg.Bar)
+	}
+	if cfg.DB.Host != "localhost" || cfg.DB.Port != 5432 {
+		t.Fatalf("have: %+v", cfg.DB)
+	}
+	if cfg.DB.Replica.Host != "replica.local" {
+		t.Fatalf("have: %v", cfg.DB.Replica.Host)
+	}
+	if want := []string{"a", "b", "c"}; !reflect.DeepEqual(cfg.DB.Replica.Hosts, want) {
+		t.Fatalf("have: %v", cfg.DB.Replica.Hosts)
+	}
+}
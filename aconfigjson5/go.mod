@@ -0,0 +1,10 @@
+module github.com/cristalhq/aconfig/aconfigjson5
+
+go 1.18
+
+require (
+	github.com/cristalhq/aconfig v0.19.0
+	github.com/titanous/json5 v1.0.0
+)
+
+require github.com/mitchellh/mapstructure v1.5.0 // indirect
@@ -0,0 +1,53 @@
+package aconfigjson5
+
+import (
+	"io"
+	"io/fs"
+
+	"github.com/titanous/json5"
+)
+
+// Decoder of JSON5 files for aconfig.
+// Comments, trailing commas, unquoted keys and single-quoted strings are allowed,
+// so it reads JSONC files too. Register it for both ".json5" and ".jsonc" extensions if needed.
+type Decoder struct {
+	fsys fs.FS
+}
+
+// New JSON5 decoder for aconfig.
+func New() *Decoder { return &Decoder{} }
+
+// Format of the decoder.
+func (d *Decoder) Format() string {
+	return "json"
+}
+
+// DecodeFile implements aconfig.FileDecoder.
+func (d *Decoder) DecodeFile(filename string) (map[string]interface{}, error) {
+	f, err := d.fsys.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return d.DecodeReader(f)
+}
+
+// DecodeReader implements aconfig.ReaderDecoder.
+func (d *Decoder) DecodeReader(r io.Reader) (map[string]interface{}, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw map[string]interface{}
+	if err := json5.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	return raw, nil
+}
+
+// DecodeFile implements aconfig.FileDecoder.
+func (d *Decoder) Init(fsys fs.FS) {
+	d.fsys = fsys
+}
@@ -0,0 +1,52 @@
+package aconfigjson5_test
+
+import (
+	"embed"
+	"reflect"
+	"testing"
+
+	"github.com/cristalhq/aconfig"
+	"github.com/cristalhq/aconfig/aconfigjson5"
+)
+
+//go:embed testdata
+var configEmbed embed.FS
+
+func TestJSON5Embed(t *testing.T) {
+	var cfg struct {
+		Foo  string
+		Bar  string
+		HTTP struct {
+			Port  int
+			Hosts []string
+		}
+	}
+	loader := aconfig.LoaderFor(&cfg, aconfig.Config{
+		SkipDefaults:       true,
+		SkipEnv:            true,
+		SkipFlags:          true,
+		FailOnFileNotFound: true,
+		FileDecoders: map[string]aconfig.FileDecoder{
+			".json5": aconfigjson5.New(),
+		},
+		Files:      []string{"testdata/config.json5"},
+		FileSystem: configEmbed,
+	})
+
+	if err := loader.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Foo != "value1" {
+		t.Fatalf("have: %v", cfg.Foo)
+	}
+	if cfg.Bar != "value2" {
+		t.Fatalf("have: %v", cfg.Bar)
+	}
+	if cfg.HTTP.Port != 8080 {
+		t.Fatalf("have: %v", cfg.HTTP.Port)
+	}
+	if want := []string{"a", "b"}; !reflect.DeepEqual(cfg.HTTP.Hosts, want) {
+		t.Fatalf("have: %v", cfg.HTTP.Hosts)
+	}
+}
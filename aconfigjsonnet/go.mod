@@ -0,0 +1,14 @@
+module github.com/cristalhq/aconfig/aconfigjsonnet
+
+go 1.18
+
+require (
+	github.com/cristalhq/aconfig v0.19.0
+	github.com/google/go-jsonnet v0.20.0
+)
+
+require (
+	github.com/mitchellh/mapstructure v1.5.0 // indirect
+	gopkg.in/yaml.v2 v2.2.7 // indirect
+	sigs.k8s.io/yaml v1.1.0 // indirect
+)
@@ -0,0 +1,104 @@
+package aconfigjsonnet
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+
+	"github.com/google/go-jsonnet"
+)
+
+// Config of the decoder.
+type Config struct {
+	// ImportPaths are directories to search for imports after the directory of the importing file.
+	// Paths are in the same file system as config files.
+	ImportPaths []string
+
+	// ExtVars are external variables available with std.extVar("name").
+	ExtVars map[string]string
+}
+
+// Decoder of Jsonnet files for aconfig.
+// Files are evaluated into JSON, so fields are matched by JSON names.
+// Register it for both ".jsonnet" and ".libsonnet" extensions if needed.
+type Decoder struct {
+	fsys fs.FS
+	cfg  Config
+}
+
+// New Jsonnet decoder for aconfig.
+func New(cfg Config) *Decoder { return &Decoder{cfg: cfg} }
+
+// Format of the decoder.
+func (d *Decoder) Format() string {
+	return "json"
+}
+
+// DecodeFile implements aconfig.FileDecoder.
+func (d *Decoder) DecodeFile(filename string) (map[string]interface{}, error) {
+	data, err := fs.ReadFile(d.fsys, filename)
+	if err != nil {
+		return nil, err
+	}
+
+	vm := jsonnet.MakeVM()
+	vm.Importer(&importer{fsys: d.fsys, paths: d.cfg.ImportPaths})
+	for name, value := range d.cfg.ExtVars {
+		vm.ExtVar(name, value)
+	}
+
+	out, err := vm.EvaluateAnonymousSnippet(filename, string(data))
+	if err != nil {
+		return nil, err
+	}
+
+	var raw map[string]interface{}
+	if err := json.Unmarshal([]byte(out), &raw); err != nil {
+		return nil, err
+	}
+	return raw, nil
+}
+
+// DecodeFile implements aconfig.FileDecoder.
+func (d *Decoder) Init(fsys fs.FS) {
+	d.fsys = fsys
+}
+
+// importer reads imports from the file system, see jsonnet.FileImporter.
+type importer struct {
+	fsys  fs.FS
+	paths []string
+	cache map[string]jsonnet.Contents
+}
+
+// Import implements jsonnet.Importer.
+func (i *importer) Import(importedFrom, importedPath string) (jsonnet.Contents, string, error) {
+	if i.cache == nil {
+		i.cache = map[string]jsonnet.Contents{}
+	}
+
+	dirs := append([]string{path.Dir(importedFrom)}, i.paths...)
+	for _, dir := range dirs {
+		name := importedPath
+		if !path.IsAbs(name) {
+			name = path.Join(dir, name)
+		}
+		if contents, ok := i.cache[name]; ok {
+			return contents, name, nil
+		}
+
+		data, err := fs.ReadFile(i.fsys, name)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			continue
+		case err != nil:
+			return jsonnet.Contents{}, "", err
+		}
+		contents := jsonnet.MakeContents(string(data))
+		i.cache[name] = contents
+		return contents, name, nil
+	}
+	return jsonnet.Contents{}, "", fmt.Errorf("import %q not found", importedPath)
+}
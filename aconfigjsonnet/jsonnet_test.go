@@ -0,0 +1,77 @@
+package aconfigjsonnet_test
+
+import (
+	"embed"
+	"testing"
+	"testing/fstest"
+
+	"github.com/cristalhq/aconfig"
+	"github.com/cristalhq/aconfig/aconfigjsonnet"
+)
+
+//go:embed testdata
+var configEmbed embed.FS
+
+func TestJsonnetEmbed(t *testing.T) {
+	var cfg struct {
+		Foo      string
+		Bar      string
+		Env      string
+		Replicas []struct {
+			Host string
+		}
+	}
+	loader := aconfig.LoaderFor(&cfg, aconfig.Config{
+		SkipDefaults:       true,
+		SkipEnv:            true,
+		SkipFlags:          true,
+		FailOnFileNotFound: true,
+		FileDecoders: map[string]aconfig.FileDecoder{
+			".jsonnet": aconfigjsonnet.New(aconfigjsonnet.Config{
+				ImportPaths: []string{"testdata/lib"},
+				ExtVars:     map[string]string{"env": "prod"},
+			}),
+		},
+		Files:      []string{"testdata/config.jsonnet"},
+		FileSystem: configEmbed,
+	})
+
+	if err := loader.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Foo != "value1" {
+		t.Fatalf("have: %v", cfg.Foo)
+	}
+	if cfg.Bar != "value2" {
+		t.Fatalf("have: %v", cfg.Bar)
+	}
+	if cfg.Env != "prod" {
+		t.Fatalf("have: %v", cfg.Env)
+	}
+	if len(cfg.Replicas) != 2 || cfg.Replicas[1].Host != "r2" {
+		t.Fatalf("have: %v", cfg.Replicas)
+	}
+}
+
+func TestJsonnetMissingImport(t *testing.T) {
+	var cfg struct {
+		Foo string
+	}
+	loader := aconfig.LoaderFor(&cfg, aconfig.Config{
+		SkipDefaults: true,
+		SkipEnv:      true,
+		SkipFlags:    true,
+		FileDecoders: map[string]aconfig.FileDecoder{
+			".jsonnet": aconfigjsonnet.New(aconfigjsonnet.Config{}),
+		},
+		Files: []string{"config.jsonnet"},
+		FileSystem: fstest.MapFS{
+			"config.jsonnet": {Data: []byte(`import 'missing.libsonnet'`)},
+		},
+	})
+
+	if err := loader.Load(); err == nil {
+		t.Fatal("must fail")
+	}
+}
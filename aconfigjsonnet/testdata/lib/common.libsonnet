@@ -0,0 +1,4 @@
+{
+  foo: 'value1',
+  bar: 'value' + 2,
+}
@@ -0,0 +1,253 @@
+package aconfigk8s
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Kind of the object with config values.
+type Kind string
+
+// Supported kinds.
+const (
+	ConfigMap Kind = "configmaps"
+	Secret    Kind = "secrets"
+)
+
+// serviceAccountDir is where Kubernetes mounts the service account of the pod.
+const serviceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+
+// Config for the Kubernetes source.
+type Config struct {
+	// Host is the API server URL like https://10.0.0.1:443.
+	Host string
+
+	// Token is a bearer token for the API server.
+	Token string
+
+	// Client to make requests, default is http.DefaultClient.
+	Client *http.Client
+
+	// Kind of the object, ConfigMap or Secret.
+	Kind Kind
+
+	// Namespace and Name of the object.
+	Namespace string
+	Name      string
+}
+
+// Source of config values stored in a Kubernetes ConfigMap or Secret for aconfig.
+// Values are read via the API server, so changes are seen right away unlike with mounted files.
+// Keys of the object are matched with the field names for env, like with `envFrom` in a pod spec.
+type Source struct {
+	config Config
+}
+
+// New Kubernetes source for aconfig.
+func New(cfg Config) *Source {
+	if cfg.Client == nil {
+		cfg.Client = http.DefaultClient
+	}
+	return &Source{config: cfg}
+}
+
+// InCluster returns a source which uses the service account of the pod.
+// Namespace can be empty, then the namespace of the pod is used.
+func InCluster(kind Kind, namespace, name string) (*Source, error) {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return nil, errors.New("not running in a cluster: KUBERNETES_SERVICE_HOST or KUBERNETES_SERVICE_PORT isn't set")
+	}
+
+	token, err := os.ReadFile(filepath.Join(serviceAccountDir, "token"))
+	if err != nil {
+		return nil, err
+	}
+	ca, err := os.ReadFile(filepath.Join(serviceAccountDir, "ca.crt"))
+	if err != nil {
+		return nil, err
+	}
+	if namespace == "" {
+		ns, err := os.ReadFile(filepath.Join(serviceAccountDir, "namespace"))
+		if err != nil {
+			return nil, err
+		}
+		namespace = strings.TrimSpace(string(ns))
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, errors.New("invalid service account CA certificate")
+	}
+
+	return New(Config{
+		Host:  "https://" + net.JoinHostPort(host, port),
+		Token: strings.TrimSpace(string(token)),
+		Client: &http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{RootCAs: pool},
+			},
+		},
+		Kind:      kind,
+		Namespace: namespace,
+		Name:      name,
+	}), nil
+}
+
+// Name of the source.
+func (s *Source) Name() string {
+	return fmt.Sprintf("k8s:%s/%s/%s", s.config.Kind, s.config.Namespace, s.config.Name)
+}
+
+// Format of the source, keys are matched with env names.
+func (s *Source) Format() string {
+	return "env"
+}
+
+// Load implements aconfig.Source.
+func (s *Source) Load(ctx context.Context) (map[string]any, error) {
+	obj, err := s.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.values(obj)
+}
+
+// Watch implements aconfig.SourceWatcher, notify is called on every change of the object.
+// Watch reconnects when the API server closes the stream.
+func (s *Source) Watch(ctx context.Context, notify func()) error {
+	obj, err := s.get(ctx)
+	if err != nil {
+		return err
+	}
+	version := obj.Metadata.ResourceVersion
+
+	for {
+		version, err = s.watch(ctx, version, notify)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if version == "" {
+			// the version is too old, get the current one and notify as changes might be missed.
+			obj, err := s.get(ctx)
+			if err != nil {
+				return err
+			}
+			version = obj.Metadata.ResourceVersion
+			notify()
+		}
+	}
+}
+
+// watch streams events after the version until the stream ends, returns the last seen version.
+// Empty version means that the watch must be restarted from the current state.
+func (s *Source) watch(ctx context.Context, version string, notify func()) (string, error) {
+	query := url.Values{
+		"watch":           {"true"},
+		"fieldSelector":   {"metadata.name=" + s.config.Name},
+		"resourceVersion": {version},
+	}
+	resp, err := s.do(ctx, s.collectionPath()+"?"+query.Encode())
+	if err != nil {
+		return version, err
+	}
+	defer resp.Body.Close()
+
+	dec := json.NewDecoder(resp.Body)
+	for {
+		var event struct {
+			Type   string `json:"type"`
+			Object object `json:"object"`
+		}
+		if err := dec.Decode(&event); err != nil {
+			if errors.Is(err, io.EOF) || ctx.Err() != nil {
+				return version, nil
+			}
+			return version, err
+		}
+
+		if event.Type == "ERROR" {
+			return "", nil
+		}
+		version = event.Object.Metadata.ResourceVersion
+		notify()
+	}
+}
+
+type object struct {
+	Metadata struct {
+		ResourceVersion string `json:"resourceVersion"`
+	} `json:"metadata"`
+	Data map[string]string `json:"data"`
+}
+
+func (s *Source) get(ctx context.Context) (*object, error) {
+	resp, err := s.do(ctx, s.collectionPath()+"/"+url.PathEscape(s.config.Name))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var obj object
+	if err := json.NewDecoder(resp.Body).Decode(&obj); err != nil {
+		return nil, err
+	}
+	return &obj, nil
+}
+
+func (s *Source) do(ctx context.Context, path string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(s.config.Host, "/")+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	if s.config.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.config.Token)
+	}
+
+	resp, err := s.config.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return resp, nil
+}
+
+func (s *Source) collectionPath() string {
+	return fmt.Sprintf("/api/v1/namespaces/%s/%s", url.PathEscape(s.config.Namespace), s.config.Kind)
+}
+
+// values returns data of the object, secrets are decoded from base64.
+func (s *Source) values(obj *object) (map[string]any, error) {
+	res := make(map[string]any, len(obj.Data))
+	for key, value := range obj.Data {
+		if s.config.Kind == Secret {
+			data, err := base64.StdEncoding.DecodeString(value)
+			if err != nil {
+				return nil, fmt.Errorf("key %q: %w", key, err)
+			}
+			value = string(data)
+		}
+		res[key] = value
+	}
+	return res, nil
+}
@@ -0,0 +1,159 @@
+package aconfigk8s_test
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/cristalhq/aconfig"
+	"github.com/cristalhq/aconfig/aconfigk8s"
+)
+
+func TestConfigMap(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/namespaces/prod/configmaps/app" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `{"metadata": {"resourceVersion": "1"}, "data": {"PORT": "8080", "DB_HOST": "db.prod"}}`)
+	}))
+	defer srv.Close()
+
+	var cfg struct {
+		Port int
+		DB   struct {
+			Host string
+		}
+	}
+	loader := aconfig.LoaderFor(&cfg, aconfig.Config{
+		SkipDefaults: true,
+		SkipFiles:    true,
+		SkipEnv:      true,
+		SkipFlags:    true,
+		Sources: []aconfig.Source{
+			aconfigk8s.New(aconfigk8s.Config{
+				Host:      srv.URL,
+				Token:     "token",
+				Kind:      aconfigk8s.ConfigMap,
+				Namespace: "prod",
+				Name:      "app",
+			}),
+		},
+	})
+	if err := loader.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Port != 8080 || cfg.DB.Host != "db.prod" {
+		t.Fatalf("have: %+v", cfg)
+	}
+}
+
+func TestSecret(t *testing.T) {
+	password := base64.StdEncoding.EncodeToString([]byte("qwerty"))
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"metadata": {"resourceVersion": "1"}, "data": {"DB_PASSWORD": %q}}`, password)
+	}))
+	defer srv.Close()
+
+	src := aconfigk8s.New(aconfigk8s.Config{
+		Host:      srv.URL,
+		Kind:      aconfigk8s.Secret,
+		Namespace: "prod",
+		Name:      "app",
+	})
+
+	values, err := src.Load(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values["DB_PASSWORD"] != "qwerty" {
+		t.Fatalf("have: %v", values)
+	}
+	if src.Name() != "k8s:secrets/prod/app" {
+		t.Fatalf("have: %v", src.Name())
+	}
+}
+
+func TestNotFound(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	src := aconfigk8s.New(aconfigk8s.Config{
+		Host:      srv.URL,
+		Kind:      aconfigk8s.ConfigMap,
+		Namespace: "prod",
+		Name:      "app",
+	})
+
+	_, err := src.Load(context.Background())
+	if err == nil || err.Error() != "404 Not Found: 404 page not found" {
+		t.Fatalf("have: %v", err)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		watches []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("watch") == "" {
+			fmt.Fprint(w, `{"metadata": {"resourceVersion": "10"}}`)
+			return
+		}
+
+		mu.Lock()
+		watches = append(watches, r.URL.Query().Get("resourceVersion"))
+		n := len(watches)
+		mu.Unlock()
+
+		switch n {
+		case 1:
+			fmt.Fprint(w, `{"type": "MODIFIED", "object": {"metadata": {"resourceVersion": "11"}}}`)
+		case 2:
+			fmt.Fprint(w, `{"type": "ERROR", "object": {"code": 410}}`)
+		default:
+			<-r.Context().Done()
+		}
+	}))
+	defer srv.Close()
+
+	src := aconfigk8s.New(aconfigk8s.Config{
+		Host:      srv.URL,
+		Kind:      aconfigk8s.ConfigMap,
+		Namespace: "prod",
+		Name:      "app",
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	notified := make(chan struct{})
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- src.Watch(ctx, func() { notified <- struct{}{} })
+	}()
+
+	<-notified // MODIFIED event
+	<-notified // restart after ERROR event
+	cancel()
+	if err := <-errCh; err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := []string{"10", "11"}
+	if fmt.Sprint(watches[:2]) != fmt.Sprint(want) {
+		t.Fatalf("have: %v, want: %v", watches, want)
+	}
+}
@@ -0,0 +1,13 @@
+module github.com/cristalhq/aconfig/aconfigmsgpack
+
+go 1.18
+
+require (
+	github.com/cristalhq/aconfig v0.19.0
+	github.com/vmihailenco/msgpack/v5 v5.4.1
+)
+
+require (
+	github.com/mitchellh/mapstructure v1.5.0 // indirect
+	github.com/vmihailenco/tagparser/v2 v2.0.0 // indirect
+)
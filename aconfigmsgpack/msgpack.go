@@ -0,0 +1,47 @@
+package aconfigmsgpack
+
+import (
+	"io"
+	"io/fs"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Decoder of MessagePack files for aconfig.
+// File must contain a single map with string keys, fields are matched by JSON names.
+type Decoder struct {
+	fsys fs.FS
+}
+
+// New MessagePack decoder for aconfig.
+func New() *Decoder { return &Decoder{} }
+
+// Format of the decoder.
+func (d *Decoder) Format() string {
+	return "json"
+}
+
+// DecodeFile implements aconfig.FileDecoder.
+func (d *Decoder) DecodeFile(filename string) (map[string]interface{}, error) {
+	f, err := d.fsys.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return d.DecodeReader(f)
+}
+
+// DecodeReader implements aconfig.ReaderDecoder.
+func (d *Decoder) DecodeReader(r io.Reader) (map[string]interface{}, error) {
+	var raw map[string]interface{}
+	if err := msgpack.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, err
+	}
+	return raw, nil
+}
+
+// DecodeFile implements aconfig.FileDecoder.
+func (d *Decoder) Init(fsys fs.FS) {
+	d.fsys = fsys
+}
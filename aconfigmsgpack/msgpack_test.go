@@ -0,0 +1,67 @@
+package aconfigmsgpack_test
+
+import (
+	"reflect"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/cristalhq/aconfig"
+	"github.com/cristalhq/aconfig/aconfigmsgpack"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+func TestMsgpack(t *testing.T) {
+	data, err := msgpack.Marshal(map[string]interface{}{
+		"foo":     "value1",
+		"timeout": "5s",
+		"http": map[string]interface{}{
+			"port":  8080,
+			"ratio": 0.5,
+			"hosts": []string{"a", "b"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var cfg struct {
+		Foo     string
+		Timeout time.Duration
+		HTTP    struct {
+			Port  int
+			Ratio float64
+			Hosts []string
+		}
+	}
+	loader := aconfig.LoaderFor(&cfg, aconfig.Config{
+		SkipDefaults:       true,
+		SkipEnv:            true,
+		SkipFlags:          true,
+		FailOnFileNotFound: true,
+		FileDecoders: map[string]aconfig.FileDecoder{
+			".msgpack": aconfigmsgpack.New(),
+		},
+		Files: []string{"config.msgpack"},
+		FileSystem: fstest.MapFS{
+			"config.msgpack": {Data: data},
+		},
+	})
+
+	if err := loader.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Foo != "value1" {
+		t.Fatalf("have: %v", cfg.Foo)
+	}
+	if cfg.Timeout != 5*time.Second {
+		t.Fatalf("have: %v", cfg.Timeout)
+	}
+	if cfg.HTTP.Port != 8080 || cfg.HTTP.Ratio != 0.5 {
+		t.Fatalf("have: %+v", cfg.HTTP)
+	}
+	if want := []string{"a", "b"}; !reflect.DeepEqual(cfg.HTTP.Hosts, want) {
+		t.Fatalf("have: %v", cfg.HTTP.Hosts)
+	}
+}
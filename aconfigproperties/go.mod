@@ -0,0 +1,10 @@
+module github.com/cristalhq/aconfig/aconfigproperties
+
+go 1.18
+
+require (
+	github.com/cristalhq/aconfig v0.19.0
+	github.com/magiconair/properties v1.8.7
+)
+
+require github.com/mitchellh/mapstructure v1.5.0 // indirect
@@ -0,0 +1,86 @@
+package aconfigproperties
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+
+	"github.com/magiconair/properties"
+)
+
+// Decoder of Java .properties files for aconfig.
+// Dotted keys are nested objects: db.host=localhost sets field DB.Host.
+// Line continuations with \ and \uXXXX escapes are supported, ${key} references aren't expanded.
+type Decoder struct {
+	fsys fs.FS
+}
+
+// New .properties decoder for aconfig.
+func New() *Decoder { return &Decoder{} }
+
+// Format of the decoder.
+func (d *Decoder) Format() string {
+	return "properties"
+}
+
+// DecodeFile implements aconfig.FileDecoder.
+func (d *Decoder) DecodeFile(filename string) (map[string]interface{}, error) {
+	f, err := d.fsys.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return d.DecodeReader(f)
+}
+
+// DecodeReader implements aconfig.ReaderDecoder.
+func (d *Decoder) DecodeReader(r io.Reader) (map[string]interface{}, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	loader := properties.Loader{Encoding: properties.UTF8, DisableExpansion: true}
+	props, err := loader.LoadBytes(data)
+	if err != nil {
+		return nil, err
+	}
+
+	res := map[string]interface{}{}
+	for _, key := range props.Keys() {
+		value, _ := props.Get(key)
+		if err := setNested(res, strings.Split(key, "."), value); err != nil {
+			return nil, fmt.Errorf("key %q: %w", key, err)
+		}
+	}
+	return res, nil
+}
+
+// DecodeFile implements aconfig.FileDecoder.
+func (d *Decoder) Init(fsys fs.FS) {
+	d.fsys = fsys
+}
+
+func setNested(dst map[string]interface{}, keys []string, value string) error {
+	for _, key := range keys[:len(keys)-1] {
+		switch sub := dst[key].(type) {
+		case map[string]interface{}:
+			dst = sub
+		case nil:
+			next := map[string]interface{}{}
+			dst[key] = next
+			dst = next
+		default:
+			return fmt.Errorf("%q is already a value", key)
+		}
+	}
+
+	last := keys[len(keys)-1]
+	if _, ok := dst[last].(map[string]interface{}); ok {
+		return fmt.Errorf("%q is already an object", last)
+	}
+	dst[last] = value
+	return nil
+}
@@ -0,0 +1,85 @@
+package aconfigproperties_test
+
+import (
+	"embed"
+	"reflect"
+	"testing"
+	"testing/fstest"
+
+	"github.com/cristalhq/aconfig"
+	"github.com/cristalhq/aconfig/aconfigproperties"
+)
+
+//go:embed testdata
+var configEmbed embed.FS
+
+func TestPropertiesEmbed(t *testing.T) {
+	var cfg struct {
+		Foo      string
+		Bar      string
+		Greeting string
+		DB       struct {
+			Host  string
+			Port  int
+			Hosts []string
+		}
+	}
+	loader := aconfig.LoaderFor(&cfg, aconfig.Config{
+		SkipDefaults:       true,
+		SkipEnv:            true,
+		SkipFlags:          true,
+		FailOnFileNotFound: true,
+		FileDecoders: map[string]aconfig.FileDecoder{
+			".properties": aconfigproperties.New(),
+		},
+		Files:      []string{"testdata/config.properties"},
+		FileSystem: configEmbed,
+	})
+
+	if err := loader.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Foo != "value1" {
+		t.Fatalf("have: %v", cfg.Foo)
+	}
+	if cfg.Bar != "value2" {
+		t.Fatalf("have: %v", cfg.Bar)
+	}
+	if cfg.Greeting != "café" {
+		t.Fatalf("have: %v", cfg.Greeting)
+	}
+	if cfg.DB.Host != "localhost" || cfg.DB.Port != 5432 {
+		t.Fatalf("have: %+v", cfg.DB)
+	}
+	if want := []string{"a", "b", "c"}; !reflect.DeepEqual(cfg.DB.Hosts, want) {
+		t.Fatalf("have: %v", cfg.DB.Hosts)
+	}
+}
+
+func TestPropertiesConflict(t *testing.T) {
+	var cfg struct {
+		DB string
+	}
+	loader := aconfig.LoaderFor(&cfg, aconfig.Config{
+		SkipDefaults: true,
+		SkipEnv:      true,
+		SkipFlags:    true,
+		FileDecoders: map[string]aconfig.FileDecoder{
+			".properties": aconfigproperties.New(),
+		},
+		Files: []string{"config.properties"},
+		FileSystem: fstest.MapFS{
+			"config.properties": {Data: []byte("db=x\ndb.host=y\n")},
+		},
+	})
+
+	err := loader.Load()
+	if err == nil {
+		t.Fatal("must fail")
+	}
+	want := `load config: load files: key "db.host": "db" is already a value`
+	if err.Error() != want {
+		t.Fatalf("have: %v", err)
+	}
+}
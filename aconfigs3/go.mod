@@ -0,0 +1,22 @@
+module github.com/cristalhq/aconfig/aconfigs3
+
+go 1.18
+
+require (
+	github.com/aws/aws-sdk-go-v2/service/s3 v1.56.0
+	github.com/cristalhq/aconfig v0.20.0
+)
+
+require (
+	github.com/aws/aws-sdk-go-v2 v1.30.0 // indirect
+	github.com/aws/aws-sdk-go-v2/aws/protocol/eventstream v1.6.2 // indirect
+	github.com/aws/aws-sdk-go-v2/internal/configsources v1.3.11 // indirect
+	github.com/aws/aws-sdk-go-v2/internal/endpoints/v2 v2.6.11 // indirect
+	github.com/aws/aws-sdk-go-v2/internal/v4a v1.3.11 // indirect
+	github.com/aws/aws-sdk-go-v2/service/internal/accept-encoding v1.11.2 // indirect
+	github.com/aws/aws-sdk-go-v2/service/internal/checksum v1.3.13 // indirect
+	github.com/aws/aws-sdk-go-v2/service/internal/presigned-url v1.11.13 // indirect
+	github.com/aws/aws-sdk-go-v2/service/internal/s3shared v1.17.11 // indirect
+	github.com/aws/smithy-go v1.20.2 // indirect
+	github.com/mitchellh/mapstructure v1.5.0 // indirect
+)
@@ -0,0 +1,64 @@
+package aconfigs3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// Client is a part of s3.Client used by the fetcher.
+type Client interface {
+	GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error)
+}
+
+// Fetcher of config files stored in AWS S3 for aconfig.
+// Register it for "s3" scheme in aconfig.Config.FileFetchers to load files like s3://bucket/config.yaml.
+type Fetcher struct {
+	client Client
+}
+
+// New S3 fetcher for aconfig.
+func New(client Client) *Fetcher {
+	return &Fetcher{client: client}
+}
+
+// Fetch implements aconfig.FileFetcher.
+func (f *Fetcher) Fetch(ctx context.Context, url string) ([]byte, error) {
+	bucket, key, err := parseURL(url)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := f.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: &bucket,
+		Key:    &key,
+	})
+	if err != nil {
+		var noKey *types.NoSuchKey
+		if errors.As(err, &noKey) {
+			return nil, fmt.Errorf("%w: %v", fs.ErrNotExist, err)
+		}
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	return io.ReadAll(resp.Body)
+}
+
+// parseURL returns bucket and key from s3://bucket/key.
+func parseURL(url string) (bucket, key string, err error) {
+	if !strings.HasPrefix(url, "s3://") {
+		return "", "", fmt.Errorf("URL %q must start with s3://", url)
+	}
+	bucket, key, ok := strings.Cut(strings.TrimPrefix(url, "s3://"), "/")
+	if !ok || bucket == "" || key == "" {
+		return "", "", fmt.Errorf("URL %q must be like s3://bucket/key", url)
+	}
+	return bucket, key, nil
+}
@@ -0,0 +1,82 @@
+package aconfigs3_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/cristalhq/aconfig"
+	"github.com/cristalhq/aconfig/aconfigs3"
+)
+
+func TestS3(t *testing.T) {
+	client := &fakeClient{objects: map[string]string{
+		"configs/prod/app.json": `{"port": 8080, "db": {"host": "db.prod"}}`,
+	}}
+
+	var cfg struct {
+		Port int
+		DB   struct {
+			Host string
+		}
+	}
+	loader := aconfig.LoaderFor(&cfg, aconfig.Config{
+		SkipDefaults: true,
+		SkipEnv:      true,
+		SkipFlags:    true,
+		MergeFiles:   true,
+		Files:        []string{"s3://configs/prod/app.json", "s3://configs/prod/local.json"},
+		FileFetchers: map[string]aconfig.FileFetcher{
+			"s3": aconfigs3.New(client),
+		},
+	})
+	if err := loader.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Port != 8080 || cfg.DB.Host != "db.prod" {
+		t.Fatalf("have: %+v", cfg)
+	}
+	if missing := loader.MissingFiles(); len(missing) != 1 || missing[0] != "s3://configs/prod/local.json" {
+		t.Fatalf("have: %v", missing)
+	}
+}
+
+func TestS3BadURL(t *testing.T) {
+	fetcher := aconfigs3.New(&fakeClient{})
+
+	for _, url := range []string{"s3://bucket", "s3:///key", "gs://bucket/key"} {
+		if _, err := fetcher.Fetch(context.Background(), url); err == nil {
+			t.Fatalf("must be an error for %q", url)
+		}
+	}
+}
+
+func TestS3Error(t *testing.T) {
+	fetcher := aconfigs3.New(&fakeClient{err: errors.New("access denied")})
+
+	_, err := fetcher.Fetch(context.Background(), "s3://configs/app.json")
+	if err == nil || err.Error() != "access denied" {
+		t.Fatalf("have: %v", err)
+	}
+}
+
+type fakeClient struct {
+	objects map[string]string
+	err     error
+}
+
+func (c *fakeClient) GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	data, ok := c.objects[*params.Bucket+"/"+*params.Key]
+	if !ok {
+		return nil, &types.NoSuchKey{}
+	}
+	return &s3.GetObjectOutput{Body: io.NopCloser(strings.NewReader(data))}, nil
+}
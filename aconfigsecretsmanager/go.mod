@@ -0,0 +1,16 @@
+module github.com/cristalhq/aconfig/aconfigsecretsmanager
+
+go 1.18
+
+require (
+	github.com/aws/aws-sdk-go-v2/service/secretsmanager v1.32.0
+	github.com/cristalhq/aconfig v0.20.0
+)
+
+require (
+	github.com/aws/aws-sdk-go-v2 v1.30.0 // indirect
+	github.com/aws/aws-sdk-go-v2/internal/configsources v1.3.12 // indirect
+	github.com/aws/aws-sdk-go-v2/internal/endpoints/v2 v2.6.12 // indirect
+	github.com/aws/smithy-go v1.20.2 // indirect
+	github.com/mitchellh/mapstructure v1.5.0 // indirect
+)
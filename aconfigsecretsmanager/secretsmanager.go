@@ -0,0 +1,115 @@
+package aconfigsecretsmanager
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+)
+
+// Client is a part of secretsmanager.Client used by the source.
+type Client interface {
+	GetSecretValue(ctx context.Context, params *secretsmanager.GetSecretValueInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error)
+}
+
+// Source of config values stored in AWS Secrets Manager for aconfig.
+//
+// A secret with JSON object is loaded like a JSON file, so nested objects are mapped onto nested fields.
+// Fields can also reference a single secret or a key in a JSON secret with a tag:
+//
+//	Password string `secretsmanager:"prod/db#password"`
+//	APIKey   string `secretsmanager:"prod/api-key"`
+type Source struct {
+	client   Client
+	secretID string
+	cache    map[string]string
+}
+
+// New Secrets Manager source for aconfig which loads the secret with secretID.
+// SecretID can be empty, then only fields with `secretsmanager` tag are loaded.
+func New(client Client, secretID string) *Source {
+	return &Source{
+		client:   client,
+		secretID: secretID,
+	}
+}
+
+// Name of the source.
+func (s *Source) Name() string {
+	return "secretsmanager:" + s.secretID
+}
+
+// FieldTag implements aconfig.FieldSource.
+func (s *Source) FieldTag() string {
+	return "secretsmanager"
+}
+
+// Load implements aconfig.Source.
+func (s *Source) Load(ctx context.Context) (map[string]any, error) {
+	// secrets are fetched once per load.
+	s.cache = map[string]string{}
+
+	if s.secretID == "" {
+		return nil, nil
+	}
+
+	secret, err := s.secret(ctx, s.secretID)
+	if err != nil {
+		return nil, err
+	}
+
+	var res map[string]any
+	if err := json.Unmarshal([]byte(secret), &res); err != nil {
+		return nil, fmt.Errorf("secret %q must be a JSON object: %w", s.secretID, err)
+	}
+	return res, nil
+}
+
+// LoadField implements aconfig.FieldSource.
+// Reference is a secret name with an optional key in the JSON secret after #.
+func (s *Source) LoadField(ctx context.Context, ref string) (any, error) {
+	name, key, hasKey := strings.Cut(ref, "#")
+
+	secret, err := s.secret(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if !hasKey {
+		return secret, nil
+	}
+
+	var values map[string]any
+	if err := json.Unmarshal([]byte(secret), &values); err != nil {
+		return nil, fmt.Errorf("secret %q must be a JSON object: %w", name, err)
+	}
+	value, ok := values[key]
+	if !ok {
+		return nil, fmt.Errorf("secret %q has no key %q", name, key)
+	}
+	return value, nil
+}
+
+func (s *Source) secret(ctx context.Context, name string) (string, error) {
+	if secret, ok := s.cache[name]; ok {
+		return secret, nil
+	}
+
+	resp, err := s.client.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{
+		SecretId: &name,
+	})
+	if err != nil {
+		return "", err
+	}
+	if resp.SecretString == nil {
+		return "", errors.New("binary secrets aren't supported")
+	}
+
+	if s.cache == nil {
+		s.cache = map[string]string{}
+	}
+	s.cache[name] = *resp.SecretString
+	return *resp.SecretString, nil
+}
@@ -0,0 +1,84 @@
+package aconfigsecretsmanager_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/cristalhq/aconfig"
+	"github.com/cristalhq/aconfig/aconfigsecretsmanager"
+)
+
+func TestSecretsManager(t *testing.T) {
+	client := &fakeClient{secrets: map[string]string{
+		"prod/app":     `{"port": 8080, "db": {"host": "db.prod", "user": "app"}}`,
+		"prod/db":      `{"password": "qwerty"}`,
+		"prod/api-key": "secret-key",
+	}}
+
+	var cfg struct {
+		Port int
+		DB   struct {
+			Host     string
+			User     string
+			Password string `secretsmanager:"prod/db#password"`
+		}
+		APIKey string `secretsmanager:"prod/api-key"`
+	}
+	loader := aconfig.LoaderFor(&cfg, aconfig.Config{
+		SkipDefaults: true,
+		SkipFiles:    true,
+		SkipEnv:      true,
+		SkipFlags:    true,
+		Sources:      []aconfig.Source{aconfigsecretsmanager.New(client, "prod/app")},
+	})
+	if err := loader.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Port != 8080 || cfg.APIKey != "secret-key" {
+		t.Fatalf("have: %+v", cfg)
+	}
+	if cfg.DB.Host != "db.prod" || cfg.DB.User != "app" || cfg.DB.Password != "qwerty" {
+		t.Fatalf("have: %+v", cfg.DB)
+	}
+	if client.calls != 3 {
+		t.Fatalf("have: %v", client.calls)
+	}
+}
+
+func TestSecretsManagerErrors(t *testing.T) {
+	client := &fakeClient{secrets: map[string]string{
+		"prod/db":      `{"password": "qwerty"}`,
+		"prod/api-key": "secret-key",
+	}}
+	src := aconfigsecretsmanager.New(client, "")
+
+	f := func(ref, want string) {
+		t.Helper()
+
+		_, err := src.LoadField(context.Background(), ref)
+		if err == nil || err.Error() != want {
+			t.Fatalf("have: %v, want: %v", err, want)
+		}
+	}
+
+	f("prod/db#user", `secret "prod/db" has no key "user"`)
+	f("prod/api-key#key", `secret "prod/api-key" must be a JSON object: invalid character 's' looking for beginning of value`)
+	f("prod/none", "not found")
+}
+
+type fakeClient struct {
+	secrets map[string]string
+	calls   int
+}
+
+func (c *fakeClient) GetSecretValue(ctx context.Context, params *secretsmanager.GetSecretValueInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error) {
+	c.calls++
+	secret, ok := c.secrets[*params.SecretId]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return &secretsmanager.GetSecretValueOutput{SecretString: &secret}, nil
+}
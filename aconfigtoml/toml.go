@@ -1,6 +1,7 @@
 package aconfigtoml
 
 import (
+	"io"
 	"io/fs"
 
 	"github.com/BurntSushi/toml"
@@ -27,8 +28,13 @@ func (d *Decoder) DecodeFile(filename string) (map[string]interface{}, error) {
 	}
 	defer f.Close()
 
+	return d.DecodeReader(f)
+}
+
+// DecodeReader implements aconfig.ReaderDecoder.
+func (d *Decoder) DecodeReader(r io.Reader) (map[string]interface{}, error) {
 	var raw map[string]interface{}
-	if _, err := toml.DecodeReader(f, &raw); err != nil {
+	if _, err := toml.NewDecoder(r).Decode(&raw); err != nil {
 		return nil, err
 	}
 	return raw, nil
@@ -3,6 +3,6 @@ module github.com/cristalhq/aconfig/aconfigyaml
 go 1.16
 
 require (
-	github.com/cristalhq/aconfig v0.17.0
+	github.com/cristalhq/aconfig v0.20.0
 	gopkg.in/yaml.v3 v3.0.1
 )
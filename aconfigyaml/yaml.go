@@ -1,6 +1,7 @@
 package aconfigyaml
 
 import (
+	"io"
 	"io/fs"
 
 	"gopkg.in/yaml.v3"
@@ -27,13 +28,28 @@ func (d *Decoder) DecodeFile(filename string) (map[string]interface{}, error) {
 	}
 	defer f.Close()
 
+	return d.DecodeReader(f)
+}
+
+// DecodeReader implements aconfig.ReaderDecoder.
+func (d *Decoder) DecodeReader(r io.Reader) (map[string]interface{}, error) {
 	var raw map[string]interface{}
-	if err := yaml.NewDecoder(f).Decode(&raw); err != nil {
+	if err := yaml.NewDecoder(r).Decode(&raw); err != nil {
 		return nil, err
 	}
 	return raw, nil
 }
 
+// Encode implements aconfig.FileEncoder.
+func (d *Decoder) Encode(w io.Writer, values map[string]interface{}) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(values); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
 // DecodeFile implements aconfig.FileDecoder.
 func (d *Decoder) Init(fsys fs.FS) {
 	d.fsys = fsys
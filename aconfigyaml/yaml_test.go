@@ -1,6 +1,7 @@
 package aconfigyaml_test
 
 import (
+	"bytes"
 	"embed"
 	"os"
 	"reflect"
@@ -42,6 +43,38 @@ func TestYAMLEmbed(t *testing.T) {
 	}
 }
 
+func TestYAMLDump(t *testing.T) {
+	var cfg struct {
+		Foo string
+		Bar string
+		Sub struct {
+			Baz int `default:"42"`
+		}
+	}
+	loader := aconfig.LoaderFor(&cfg, aconfig.Config{
+		SkipEnv:   true,
+		SkipFlags: true,
+		FileDecoders: map[string]aconfig.FileDecoder{
+			".yaml": aconfigyaml.New(),
+		},
+		Files:      []string{"testdata/config.yaml"},
+		FileSystem: configEmbed,
+	})
+	if err := loader.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := loader.Dump(&buf, "yaml"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "bar: value2\nfoo: value1\nsub:\n  baz: 42\n"
+	if buf.String() != want {
+		t.Fatalf("want %q, have %q", want, buf.String())
+	}
+}
+
 func TestYAML(t *testing.T) {
 	filepath := createTestFile(t)
 
@@ -0,0 +1,14 @@
+module github.com/cristalhq/aconfig/aconfigzap
+
+go 1.18
+
+require (
+	github.com/cristalhq/aconfig v0.20.0
+	go.uber.org/zap v1.17.0
+)
+
+require (
+	github.com/mitchellh/mapstructure v1.5.0 // indirect
+	go.uber.org/atomic v1.7.0 // indirect
+	go.uber.org/multierr v1.6.0 // indirect
+)
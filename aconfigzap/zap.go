@@ -0,0 +1,39 @@
+// Package aconfigzap provides aconfig adapters for zap types.
+package aconfigzap
+
+import (
+	"reflect"
+
+	"github.com/cristalhq/aconfig"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Adapters for zapcore.Level and zap.AtomicLevel, values are level names like "debug" or "warn".
+// Use them as aconfig.Config.Adapters or add them to your own adapters.
+func Adapters() map[reflect.Type]aconfig.Adapter {
+	adapters := map[reflect.Type]aconfig.Adapter{}
+
+	typ, adapter := aconfig.AdapterFor(parseLevel, zapcore.Level.String)
+	adapters[typ] = adapter
+
+	typ, adapter = aconfig.AdapterFor(parseAtomicLevel, zap.AtomicLevel.String)
+	adapters[typ] = adapter
+
+	return adapters
+}
+
+func parseLevel(s string) (zapcore.Level, error) {
+	var level zapcore.Level
+	err := level.UnmarshalText([]byte(s))
+	return level, err
+}
+
+// parseAtomicLevel returns a new AtomicLevel, so loaded configs don't share levels.
+func parseAtomicLevel(s string) (zap.AtomicLevel, error) {
+	level, err := parseLevel(s)
+	if err != nil {
+		return zap.AtomicLevel{}, err
+	}
+	return zap.NewAtomicLevelAt(level), nil
+}
@@ -0,0 +1,48 @@
+package aconfigzap_test
+
+import (
+	"testing"
+
+	"github.com/cristalhq/aconfig"
+	"github.com/cristalhq/aconfig/aconfigzap"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestAdapters(t *testing.T) {
+	var cfg struct {
+		Level    zapcore.Level   `default:"warn"`
+		LogLevel zap.AtomicLevel `default:"info"`
+	}
+	loader := aconfig.LoaderFor(&cfg, aconfig.Config{
+		SkipFiles: true,
+		SkipFlags: true,
+		Envs:      []string{"LOG_LEVEL=debug"},
+		Adapters:  aconfigzap.Adapters(),
+	})
+	if err := loader.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Level != zapcore.WarnLevel {
+		t.Fatalf("have %v, want %v", cfg.Level, zapcore.WarnLevel)
+	}
+	if cfg.LogLevel.Level() != zapcore.DebugLevel {
+		t.Fatalf("have %v, want %v", cfg.LogLevel.Level(), zapcore.DebugLevel)
+	}
+}
+
+func TestAdaptersInvalid(t *testing.T) {
+	var cfg struct {
+		LogLevel zap.AtomicLevel
+	}
+	loader := aconfig.LoaderFor(&cfg, aconfig.Config{
+		SkipFiles: true,
+		SkipFlags: true,
+		Envs:      []string{"LOG_LEVEL=loud"},
+		Adapters:  aconfigzap.Adapters(),
+	})
+	if err := loader.Load(); err == nil {
+		t.Fatal("must fail")
+	}
+}
@@ -0,0 +1,120 @@
+package aconfig
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Adapter parses and formats values of a type that the loader can't set on its own,
+// usually a type from another module like zapcore.Level or *pgx.ConnConfig.
+// Adapters for common types are in optional submodules, like aconfigzap.
+// See Config.Adapters and AdapterFor.
+type Adapter struct {
+	// Parse returns a value of the adapted type from a string.
+	Parse func(s string) (any, error)
+
+	// Format returns a string for a value of the adapted type for Dump, print config, samples and changes.
+	// Can be nil, then values are rendered as usual.
+	Format func(v any) string
+}
+
+// AdapterFor returns a key and an adapter for Config.Adapters from typed functions:
+//
+//	typ, adapter := aconfig.AdapterFor(zapcore.ParseLevel, zapcore.Level.String)
+//	cfg.Adapters = map[reflect.Type]aconfig.Adapter{typ: adapter}
+//
+// format can be nil.
+func AdapterFor[T any](parse func(s string) (T, error), format func(v T) string) (reflect.Type, Adapter) {
+	adapter := Adapter{
+		Parse: func(s string) (any, error) {
+			return parse(s)
+		},
+	}
+	if format != nil {
+		adapter.Format = func(v any) string {
+			return format(v.(T))
+		}
+	}
+	return reflect.TypeOf((*T)(nil)).Elem(), adapter
+}
+
+// hasAdapter reports whether the type or the type it points to has an adapter.
+func hasAdapter(adapters map[reflect.Type]Adapter, typ reflect.Type) bool {
+	for {
+		if _, ok := adapters[typ]; ok {
+			return true
+		}
+		if typ.Kind() != reflect.Ptr {
+			return false
+		}
+		typ = typ.Elem()
+	}
+}
+
+// adaptValue returns a value of the type parsed by the adapter.
+// Values that already have the type are returned as is, empty strings give zero values.
+func adaptValue(adapter Adapter, typ reflect.Type, value any) (any, error) {
+	if value == nil || reflect.TypeOf(value) == typ {
+		return value, nil
+	}
+	s, ok := value.(string)
+	if !ok {
+		s = fmt.Sprint(value)
+	}
+	if s == "" {
+		return reflect.Zero(typ).Interface(), nil
+	}
+
+	parsed, err := adapter.Parse(s)
+	if err != nil {
+		return nil, err
+	}
+	if reflect.TypeOf(parsed) != typ {
+		return nil, fmt.Errorf("adapter for %s returned %T", typ, parsed)
+	}
+	return parsed, nil
+}
+
+// setAdapted sets the field with an adapter from Config.Adapters.
+// Reports false when the field type has no adapter.
+func (l *Loader) setAdapted(field *fieldData, value any) (bool, error) {
+	if !hasAdapter(l.config.Adapters, field.value.Type()) {
+		return false, nil
+	}
+
+	v := field.value
+	adapter, ok := l.config.Adapters[v.Type()]
+	for !ok {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+		adapter, ok = l.config.Adapters[v.Type()]
+	}
+
+	parsed, err := adaptValue(adapter, v.Type(), value)
+	if err != nil || parsed == nil {
+		return true, err
+	}
+	v.Set(reflect.ValueOf(parsed))
+	return true, nil
+}
+
+// adapterHook is the decode hook of the new parser with Config.Adapters.
+func (sp *structParser) adapterHook(from, to reflect.Type, data any) (any, error) {
+	if from == fieldType && hasAdapter(sp.cfg.Adapters, to) {
+		for {
+			if adapter, ok := sp.cfg.Adapters[to]; ok {
+				// pointers are set by the decoder.
+				return adaptValue(adapter, to, data.(*parsedField).value)
+			}
+			to = to.Elem()
+		}
+	}
+	return sp.hook(from, to, data)
+}
+
+// render returns a value for encoding like renderValue, formatted with Config.Adapters.
+func (l *Loader) render(v reflect.Value) any {
+	return renderValue(v, l.config.Adapters)
+}
@@ -0,0 +1,80 @@
+package aconfig
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+type hostPort struct {
+	Host string
+	Port int
+}
+
+func parseHostPort(s string) (hostPort, error) {
+	host, port, ok := strings.Cut(s, ":")
+	if !ok {
+		return hostPort{}, fmt.Errorf("want host:port, got %q", s)
+	}
+	n, err := strconv.Atoi(port)
+	if err != nil {
+		return hostPort{}, err
+	}
+	return hostPort{Host: host, Port: n}, nil
+}
+
+func (hp hostPort) String() string {
+	return hp.Host + ":" + strconv.Itoa(hp.Port)
+}
+
+func TestAdapters(t *testing.T) {
+	type TestConfig struct {
+		Listen  hostPort `default:"localhost:8080"`
+		DB      hostPort
+		Cache   *hostPort
+		Metrics hostPort
+	}
+
+	typ, adapter := AdapterFor(parseHostPort, hostPort.String)
+	adapters := map[reflect.Type]Adapter{typ: adapter}
+
+	var cfg TestConfig
+	loader := LoaderFor(&cfg, Config{
+		NewParser: newParser,
+		Adapters:  adapters,
+		Envs:      []string{"DB=db:5432"},
+		Args:      []string{"-cache=cache:6379"},
+		Files:     []string{"config.json"},
+		FileSystem: fstest.MapFS{
+			"config.json": {Data: []byte(`{"metrics": "0.0.0.0:9090"}`)},
+		},
+	})
+	failIfErr(t, loader.Load())
+
+	want := TestConfig{
+		Listen:  hostPort{Host: "localhost", Port: 8080},
+		DB:      hostPort{Host: "db", Port: 5432},
+		Cache:   &hostPort{Host: "cache", Port: 6379},
+		Metrics: hostPort{Host: "0.0.0.0", Port: 9090},
+	}
+	mustEqual(t, cfg, want)
+
+	if !newParser {
+		var buf bytes.Buffer
+		failIfErr(t, loader.Dump(&buf, "json"))
+		mustEqual(t, strings.Contains(buf.String(), `"listen": "localhost:8080"`), true)
+		mustEqual(t, strings.Contains(buf.String(), `"cache": "cache:6379"`), true)
+	}
+
+	loader = LoaderFor(&TestConfig{}, Config{
+		NewParser: newParser,
+		Adapters:  adapters,
+		SkipFlags: true,
+		Envs:      []string{"DB=db"},
+	})
+	failIfOk(t, loader.Load())
+}
@@ -0,0 +1,45 @@
+package aconfig
+
+import (
+	"reflect"
+)
+
+// FieldChange is a field changed by a reload. See Config.OnChange.
+type FieldChange struct {
+	Path   string      // Path of the field like DB.User.
+	Old    any         // Old value in a human-readable form like in Dump, "<redacted>" for secrets.
+	New    any         // New value, the same way as Old.
+	Source ValueSource // Source of the new value.
+}
+
+// diff returns fields with different values in the previous and the current configurations.
+// fields must describe the current configuration, their sources are reported.
+func (l *Loader) diff(prev, curr reflect.Value, fields []Field) []FieldChange {
+	var changes []FieldChange
+	for _, field := range fields {
+		_, index := fieldInfo(field)
+		oldValue, newValue := valueByIndex(prev, index), valueByIndex(curr, index)
+		if l.equal(oldValue, newValue) {
+			continue
+		}
+
+		change := FieldChange{
+			Path:   field.Name(),
+			Old:    l.render(reflect.ValueOf(oldValue)),
+			New:    l.render(reflect.ValueOf(newValue)),
+			Source: field.Source(),
+		}
+		if isSecret(field) {
+			change.Old, change.New = redacted, redacted
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// notifyChanges calls Config.OnChange when there are changes.
+func (l *Loader) notifyChanges(changes []FieldChange) {
+	if len(changes) != 0 && l.config.OnChange != nil {
+		l.config.OnChange(changes)
+	}
+}
@@ -0,0 +1,77 @@
+package aconfig
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// ParserDiff is a difference in behavior between the default and the new parser.
+type ParserDiff struct {
+	Field string // Field path like `Auth.User`, empty when Load results differ.
+	Old   string // Old is a value or an error with the default parser.
+	New   string // New is a value or an error with Config.NewParser.
+}
+
+// CompareParsers loads the configuration with both parsers into fresh values of dst type
+// and reports fields that got different values. Helps to migrate to Config.NewParser.
+// dst isn't modified. Set Config.Envs and Config.Args to not read them from the process.
+func CompareParsers(dst any, cfg Config) []ParserDiff {
+	typ := reflect.TypeOf(dst).Elem()
+	oldDst := reflect.New(typ).Interface()
+	newDst := reflect.New(typ).Interface()
+
+	oldCfg, newCfg := cfg, cfg
+	oldCfg.NewParser, newCfg.NewParser = false, true
+	// both loaders get the same decoders and file systems, so they see the same files.
+	oldLoader := LoaderFor(oldDst, oldCfg)
+	newLoader := LoaderFor(newDst, newCfg)
+
+	oldErr, newErr := oldLoader.Load(), newLoader.Load()
+	if oldErr != nil || newErr != nil {
+		if fmt.Sprint(oldErr) == fmt.Sprint(newErr) {
+			return nil
+		}
+		return []ParserDiff{{Old: fmt.Sprint(oldErr), New: fmt.Sprint(newErr)}}
+	}
+
+	var res []ParserDiff
+	newFields := oldLoader.rebindFields(newDst)
+	for i, field := range oldLoader.fields {
+		// pointer fields might be already dereferenced by the loader.
+		oldValue, newValue := indirect(field.value), indirect(newFields[i].value)
+		if sameValues(oldValue, newValue) {
+			continue
+		}
+		res = append(res, ParserDiff{
+			Field: field.name,
+			Old:   formatValue(oldValue),
+			New:   formatValue(newValue),
+		})
+	}
+	return res
+}
+
+// sameValues is reflect.DeepEqual but nil and empty slices and maps are equal.
+func sameValues(a, b reflect.Value) bool {
+	switch a.Kind() {
+	case reflect.Slice, reflect.Map:
+		if a.Len() == 0 && b.Len() == 0 {
+			return true
+		}
+	}
+	return reflect.DeepEqual(a.Interface(), b.Interface())
+}
+
+func indirect(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	return v
+}
+
+func formatValue(v reflect.Value) string {
+	if v.Kind() == reflect.Ptr {
+		return "<nil>"
+	}
+	return fmt.Sprintf("%v", v.Interface())
+}
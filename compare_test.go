@@ -0,0 +1,82 @@
+package aconfig
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCompareParsers(t *testing.T) {
+	type CompareConfig struct {
+		Str  string `default:"str"`
+		Int  int    `default:"10"`
+		Bool bool
+		Sub  struct {
+			Name string
+		}
+	}
+
+	cfg := Config{
+		EnvPrefix: "APP",
+		Envs:      []string{"APP_SUB_NAME=name"},
+		Args:      []string{"-int=20"},
+	}
+	mustEqual(t, len(CompareParsers(&CompareConfig{}, cfg)), 0)
+
+	type NestedConfig struct {
+		Port *int
+		Sub  struct {
+			Ints []int
+		}
+	}
+	cfg = Config{
+		EnvPrefix: "APP",
+		Envs:      []string{"APP_PORT=80"},
+		Args:      []string{},
+	}
+	mustEqual(t, len(CompareParsers(&NestedConfig{}, cfg)), 0)
+}
+
+func TestParsersParity(t *testing.T) {
+	type ParityConfig struct {
+		One      []string          `default:"a"`
+		Many     []int             `default:"1, 2"`
+		Map      map[string]string `default:"k:v"`
+		Maps     map[string]int    `default:"a:1,b:2"`
+		DurPtr   *time.Duration    `default:"5s"`
+		Durs     []time.Duration   `default:"1s,2m"`
+		EnvPtr   *time.Duration
+		EnvDurs  []time.Duration
+		EnvMap   map[string]int
+		EnvSlice []string
+	}
+	cfg := Config{
+		EnvPrefix: "APP",
+		Envs:      []string{"APP_ENV_PTR=3s", "APP_ENV_DURS=1s,4s", "APP_ENV_MAP=x:1,y:2", "APP_ENV_SLICE=z,w"},
+		Args:      []string{},
+	}
+	mustEqual(t, len(CompareParsers(&ParityConfig{}, cfg)), 0)
+
+	var have ParityConfig
+	cfg.NewParser = true
+	failIfErr(t, LoaderFor(&have, cfg).Load())
+
+	fiveSec, threeSec := 5*time.Second, 3*time.Second
+	want := ParityConfig{
+		One:      []string{"a"},
+		Many:     []int{1, 2},
+		Map:      map[string]string{"k": "v"},
+		Maps:     map[string]int{"a": 1, "b": 2},
+		DurPtr:   &fiveSec,
+		Durs:     []time.Duration{time.Second, 2 * time.Minute},
+		EnvPtr:   &threeSec,
+		EnvDurs:  []time.Duration{time.Second, 4 * time.Second},
+		EnvMap:   map[string]int{"x": 1, "y": 2},
+		EnvSlice: []string{"z", "w"},
+	}
+	mustEqual(t, have, want)
+
+	type BadMapConfig struct {
+		Map map[string]string `default:"k"`
+	}
+	mustEqual(t, len(CompareParsers(&BadMapConfig{}, Config{Envs: []string{}, Args: []string{}})), 0)
+}
@@ -0,0 +1,33 @@
+package aconfig
+
+import (
+	"reflect"
+)
+
+// Comparer returns a key and a function for Config.Comparers from a typed equality function:
+//
+//	typ, eq := aconfig.Comparer(func(a, b *regexp.Regexp) bool {
+//		return a.String() == b.String()
+//	})
+//	cfg.Comparers = map[reflect.Type]func(a, b any) bool{typ: eq}
+func Comparer[T any](eq func(a, b T) bool) (reflect.Type, func(a, b any) bool) {
+	return reflect.TypeOf((*T)(nil)).Elem(), func(a, b any) bool {
+		return eq(a.(T), b.(T))
+	}
+}
+
+// equal reports whether field values are equal, with Config.Comparers or reflect.DeepEqual.
+func (l *Loader) equal(a, b any) bool {
+	ta, tb := reflect.TypeOf(a), reflect.TypeOf(b)
+	if ta != nil && ta == tb && !isNilPtr(a) && !isNilPtr(b) {
+		if eq, ok := l.config.Comparers[ta]; ok {
+			return eq(a, b)
+		}
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func isNilPtr(v any) bool {
+	rv := reflect.ValueOf(v)
+	return rv.Kind() == reflect.Ptr && rv.IsNil()
+}
@@ -0,0 +1,48 @@
+package aconfig
+
+import (
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestComparers(t *testing.T) {
+	type TestConfig struct {
+		Hosts []string `reload:"false"`
+	}
+
+	f := func(comparers map[reflect.Type]func(a, b any) bool, want []string) {
+		t.Helper()
+		file := filepath.Join(t.TempDir(), "config.json")
+		writeFile(t, file, `{"hosts": ["a", "b"]}`)
+
+		var restart []string
+		loader := LoaderFor(&TestConfig{}, Config{
+			NewParser: newParser,
+			SkipEnv:   true,
+			SkipFlags: true,
+			Files:     []string{file},
+			Comparers: comparers,
+			OnRestartRequired: func(fields []string) {
+				restart = fields
+			},
+		})
+		failIfErr(t, loader.Load())
+
+		writeFile(t, file, `{"hosts": ["b", "a"]}`)
+		failIfErr(t, loader.reload())
+		mustEqual(t, restart, want)
+	}
+
+	f(nil, []string{"Hosts"})
+
+	// order of hosts doesn't matter.
+	typ, eq := Comparer(func(a, b []string) bool {
+		a, b = append([]string{}, a...), append([]string{}, b...)
+		sort.Strings(a)
+		sort.Strings(b)
+		return reflect.DeepEqual(a, b)
+	})
+	f(map[reflect.Type]func(a, b any) bool{typ: eq}, nil)
+}
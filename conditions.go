@@ -0,0 +1,148 @@
+package aconfig
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// whenKey is a key in file sections with a condition, see Config.Vars.
+	whenKey = "when"
+
+	// overridesKey is a top-level key in config files with a list of conditional sections.
+	overridesKey = "overrides"
+)
+
+// applyConditions removes file sections which conditions are false
+// and merges top-level overrides which conditions are true over the rest of the file.
+func (l *Loader) applyConditions(values map[string]interface{}) (map[string]interface{}, error) {
+	if l.config.Vars == nil {
+		return values, nil
+	}
+
+	raw, hasOverrides := values[overridesKey]
+	delete(values, overridesKey)
+
+	if _, err := l.resolveConditions(values); err != nil {
+		return nil, err
+	}
+	if !hasOverrides {
+		return values, nil
+	}
+
+	overrides, ok := raw.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%q must be a list, got %T", overridesKey, raw)
+	}
+	for i, override := range overrides {
+		section, ok := asMap(override)
+		if !ok {
+			return nil, fmt.Errorf("%q item %d must be an object, got %T", overridesKey, i, override)
+		}
+		if _, ok := section[whenKey]; !ok {
+			return nil, fmt.Errorf("%q item %d must have %q key", overridesKey, i, whenKey)
+		}
+
+		keep, err := l.resolveConditions(section)
+		if err != nil {
+			return nil, err
+		}
+		if keep {
+			values = mergeMaps(values, section)
+		}
+	}
+	return values, nil
+}
+
+// resolveConditions evaluates conditions in the section and its children.
+// False child sections are removed, returns false if the section itself must be removed.
+func (l *Loader) resolveConditions(section map[string]interface{}) (bool, error) {
+	if raw, ok := section[whenKey]; ok {
+		cond, ok := raw.(string)
+		if !ok {
+			return false, fmt.Errorf("%q must be a string, got %T", whenKey, raw)
+		}
+		ok, err := evalCondition(cond, l.config.Vars)
+		if err != nil {
+			return false, fmt.Errorf("condition %q: %w", cond, err)
+		}
+		if !ok {
+			return false, nil
+		}
+		delete(section, whenKey)
+	}
+
+	for key, value := range section {
+		switch value := value.(type) {
+		case map[string]interface{}, map[interface{}]interface{}:
+			sub, _ := asMap(value)
+			keep, err := l.resolveConditions(sub)
+			if err != nil {
+				return false, err
+			}
+			if !keep {
+				delete(section, key)
+				continue
+			}
+			section[key] = sub
+
+		case []interface{}:
+			items := value[:0]
+			for _, item := range value {
+				if sub, ok := asMap(item); ok {
+					keep, err := l.resolveConditions(sub)
+					if err != nil {
+						return false, err
+					}
+					if !keep {
+						continue
+					}
+					item = sub
+				}
+				items = append(items, item)
+			}
+			section[key] = items
+		}
+	}
+	return true, nil
+}
+
+// evalCondition evaluates expressions like `env == "prod" && region != eu`.
+// Operands are variables or values (quoted or not), && has higher precedence than ||.
+func evalCondition(cond string, vars map[string]string) (bool, error) {
+	for _, or := range strings.Split(cond, "||") {
+		res := true
+		for _, and := range strings.Split(or, "&&") {
+			ok, err := evalComparison(strings.TrimSpace(and), vars)
+			if err != nil {
+				return false, err
+			}
+			res = res && ok
+		}
+		if res {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func evalComparison(expr string, vars map[string]string) (bool, error) {
+	op := "=="
+	idx := strings.Index(expr, op)
+	if idx == -1 {
+		op = "!="
+		idx = strings.Index(expr, op)
+	}
+	if idx == -1 {
+		return false, fmt.Errorf("expression %q must be a comparison with == or !=", expr)
+	}
+
+	name := strings.TrimSpace(expr[:idx])
+	value := strings.Trim(strings.TrimSpace(expr[idx+len(op):]), `"'`)
+
+	v, ok := vars[name]
+	if !ok {
+		return false, fmt.Errorf("unknown variable %q", name)
+	}
+	return (v == value) == (op == "=="), nil
+}
@@ -0,0 +1,103 @@
+package aconfig
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestConditions(t *testing.T) {
+	type TestConfig struct {
+		LogLevel string
+		Debug    struct {
+			Addr string
+		}
+		Replicas []struct {
+			Host string
+		}
+	}
+
+	file := `{
+		"log_level": "info",
+		"debug": {"when": "env == dev", "addr": ":6060"},
+		"replicas": [
+			{"host": "a"},
+			{"host": "b", "when": "region == \"us\""}
+		],
+		"overrides": [
+			{"when": "env == \"prod\" && region != eu", "log_level": "warn"},
+			{"when": "env == dev || env == test", "log_level": "debug"}
+		]
+	}`
+
+	f := func(vars map[string]string, want TestConfig) {
+		t.Helper()
+
+		var cfg TestConfig
+		loader := LoaderFor(&cfg, Config{
+			NewParser:    newParser,
+			SkipDefaults: true,
+			SkipEnv:      true,
+			SkipFlags:    true,
+			Vars:         vars,
+			Files:        []string{"config.json"},
+			FileSystem: fstest.MapFS{
+				"config.json": &fstest.MapFile{Data: []byte(file)},
+			},
+		})
+		failIfErr(t, loader.Load())
+		mustEqual(t, cfg, want)
+	}
+
+	want := TestConfig{LogLevel: "warn"}
+	want.Replicas = append(want.Replicas, struct{ Host string }{"a"}, struct{ Host string }{"b"})
+	f(map[string]string{"env": "prod", "region": "us"}, want)
+
+	want = TestConfig{LogLevel: "info"}
+	want.Replicas = append(want.Replicas, struct{ Host string }{"a"})
+	f(map[string]string{"env": "prod", "region": "eu"}, want)
+
+	want.LogLevel = "debug"
+	want.Debug.Addr = ":6060"
+	f(map[string]string{"env": "dev", "region": "eu"}, want)
+}
+
+func TestConditionsUnknownVariable(t *testing.T) {
+	loader := LoaderFor(&TestConfig{}, Config{
+		NewParser:    newParser,
+		SkipDefaults: true,
+		SkipEnv:      true,
+		SkipFlags:    true,
+		Vars:         map[string]string{"env": "prod"},
+		Files:        []string{"config.json"},
+		FileSystem: fstest.MapFS{
+			"config.json": &fstest.MapFile{Data: []byte(`{"overrides": [{"when": "zone == a", "str": "a"}]}`)},
+		},
+	})
+
+	err := loader.Load()
+	failIfOk(t, err)
+	mustEqual(t, err.Error(), `load config: load files: file config.json: condition "zone == a": unknown variable "zone"`)
+}
+
+func TestEvalCondition(t *testing.T) {
+	vars := map[string]string{"env": "prod", "region": "eu"}
+
+	f := func(cond string, want bool) {
+		t.Helper()
+
+		ok, err := evalCondition(cond, vars)
+		failIfErr(t, err)
+		mustEqual(t, ok, want)
+	}
+
+	f(`env == "prod"`, true)
+	f(`env == 'prod'`, true)
+	f(`env == prod`, true)
+	f(`env != prod`, false)
+	f(`env == prod && region == us`, false)
+	f(`env == dev || region == eu`, true)
+	f(`env == dev || env == prod && region != eu`, false)
+
+	_, err := evalCondition(`env`, vars)
+	failIfOk(t, err)
+}
@@ -0,0 +1,169 @@
+package aconfig
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// checkConstraint checks value against `min`, `max` and `oneof` tags.
+// Numbers are compared by value, strings, slices and maps by length.
+// Durations in tags are written as for time.ParseDuration.
+// Values for `oneof` are separated by spaces: `oneof:"debug info warn"`.
+func checkConstraint(value reflect.Value, minTag, maxTag, oneofTag string) error {
+	if oneofTag != "" {
+		got := fmt.Sprint(value.Interface())
+		allowed := strings.Fields(oneofTag)
+		found := false
+		for _, v := range allowed {
+			if v == got {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("must be one of [%s], got %q", strings.Join(allowed, " "), got)
+		}
+	}
+
+	if minTag == "" && maxTag == "" {
+		return nil
+	}
+
+	what := "value"
+	var got float64
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		got = float64(value.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		got = float64(value.Uint())
+	case reflect.Float32, reflect.Float64:
+		got = value.Float()
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		what = "length"
+		got = float64(value.Len())
+	default:
+		return fmt.Errorf("min and max tags aren't supported for %s", value.Type())
+	}
+	isDuration := value.Type() == reflect.TypeOf(time.Duration(0))
+
+	if minTag != "" {
+		limit, err := parseLimit(minTag, isDuration)
+		if err != nil {
+			return fmt.Errorf("incorrect min tag: %w", err)
+		}
+		if got < limit {
+			return fmt.Errorf("%s must be at least %s, got %s", what, minTag, formatLimit(value, what))
+		}
+	}
+	if maxTag != "" {
+		limit, err := parseLimit(maxTag, isDuration)
+		if err != nil {
+			return fmt.Errorf("incorrect max tag: %w", err)
+		}
+		if got > limit {
+			return fmt.Errorf("%s must be at most %s, got %s", what, maxTag, formatLimit(value, what))
+		}
+	}
+	return nil
+}
+
+// checkKeys checks keys of a map against `keys` tag. Tag is a rule with an argument:
+// `keys:"oneof=us-east-1 eu-west-1"` or `keys:"regex=^[a-z]+$"`.
+func checkKeys(value reflect.Value, tag string) error {
+	if value.Kind() != reflect.Map {
+		return fmt.Errorf("keys tag isn't supported for %s", value.Type())
+	}
+
+	rule, arg, ok := strings.Cut(tag, "=")
+	if !ok {
+		return fmt.Errorf("incorrect keys tag %q", tag)
+	}
+
+	var check func(key string) bool
+	var want string
+	switch rule {
+	case "oneof":
+		allowed := strings.Fields(arg)
+		check = func(key string) bool {
+			for _, v := range allowed {
+				if v == key {
+					return true
+				}
+			}
+			return false
+		}
+		want = fmt.Sprintf("be one of [%s]", strings.Join(allowed, " "))
+	case "regex":
+		re, err := regexp.Compile(arg)
+		if err != nil {
+			return fmt.Errorf("incorrect keys tag: %w", err)
+		}
+		check = re.MatchString
+		want = fmt.Sprintf("match %q", arg)
+	default:
+		return fmt.Errorf("incorrect keys tag: unknown rule %q", rule)
+	}
+
+	keys := make([]string, 0, value.Len())
+	for _, key := range value.MapKeys() {
+		keys = append(keys, fmt.Sprint(key.Interface()))
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if !check(key) {
+			return fmt.Errorf("key %q must %s", key, want)
+		}
+	}
+	return nil
+}
+
+// checkItems checks number of items in a slice or a map against `minitems` and `maxitems` tags.
+func checkItems(value reflect.Value, minTag, maxTag string) error {
+	switch value.Kind() {
+	case reflect.Slice, reflect.Map, reflect.Array:
+	default:
+		return fmt.Errorf("minitems and maxitems tags aren't supported for %s", value.Type())
+	}
+	got := value.Len()
+
+	if minTag != "" {
+		limit, err := strconv.Atoi(minTag)
+		if err != nil {
+			return fmt.Errorf("incorrect minitems tag: %w", err)
+		}
+		if got < limit {
+			return fmt.Errorf("must have at least %d items, got %d", limit, got)
+		}
+	}
+	if maxTag != "" {
+		limit, err := strconv.Atoi(maxTag)
+		if err != nil {
+			return fmt.Errorf("incorrect maxitems tag: %w", err)
+		}
+		if got > limit {
+			return fmt.Errorf("must have at most %d items, got %d", limit, got)
+		}
+	}
+	return nil
+}
+
+func parseLimit(tag string, isDuration bool) (float64, error) {
+	if isDuration {
+		d, err := time.ParseDuration(tag)
+		return float64(d), err
+	}
+	return strconv.ParseFloat(tag, 64)
+}
+
+func formatLimit(value reflect.Value, what string) string {
+	if what == "length" {
+		return strconv.Itoa(value.Len())
+	}
+	return fmt.Sprint(value.Interface())
+}
@@ -0,0 +1,143 @@
+package aconfig
+
+import (
+	"reflect"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestConstraints(t *testing.T) {
+	type TestConfig struct {
+		Port    int           `default:"8080" min:"1" max:"65535"`
+		Level   string        `default:"info" oneof:"debug info warn"`
+		Timeout time.Duration `default:"5s" min:"1s" max:"1m" env:"-"`
+		Ratio   *float64      `min:"0" max:"1"`
+		Workers int           `min:"1"` // not set, so not checked
+	}
+
+	cfg := Config{
+		NewParser: newParser,
+		SkipFiles: true,
+		SkipFlags: true,
+		EnvPrefix: "APP",
+		Envs:      []string{"APP_RATIO=0.5"},
+	}
+	failIfErr(t, LoaderFor(&TestConfig{}, cfg).Load())
+
+	cfg.Envs = []string{
+		"APP_PORT=70000",
+		"APP_LEVEL=trace",
+		"APP_RATIO=1.5",
+	}
+	err := LoaderFor(&TestConfig{}, cfg).Load()
+	failIfOk(t, err)
+
+	want := "load config: fields have invalid values: " +
+		"Port value must be at most 65535, got 70000; " +
+		`Level must be one of [debug info warn], got "trace"; ` +
+		"Ratio value must be at most 1, got 1.5"
+	mustEqual(t, err.Error(), want)
+
+	type DefaultsConfig struct {
+		Timeout time.Duration `default:"100ms" min:"1s"`
+		Tags    []string      `default:"a,b,c" max:"2"`
+	}
+	cfg.Envs = []string{}
+	err = LoaderFor(&DefaultsConfig{}, cfg).Load()
+	failIfOk(t, err)
+
+	want = "load config: fields have invalid values: " +
+		"Timeout value must be at least 1s, got 100ms; " +
+		"Tags length must be at most 2, got 3"
+	mustEqual(t, err.Error(), want)
+}
+
+func TestKeysConstraint(t *testing.T) {
+	type TestConfig struct {
+		Regions map[string]int    `keys:"oneof=us-east-1 eu-west-1"`
+		Labels  map[string]string `keys:"regex=^[a-z]+$"`
+	}
+
+	f := func(data string) error {
+		t.Helper()
+
+		return LoaderFor(&TestConfig{}, Config{
+			NewParser:    newParser,
+			SkipDefaults: true,
+			SkipEnv:      true,
+			SkipFlags:    true,
+			Files:        []string{"config.json"},
+			FileSystem: fstest.MapFS{
+				"config.json": {Data: []byte(data)},
+			},
+		}).Load()
+	}
+
+	failIfErr(t, f(`{"regions": {"us-east-1": 1, "eu-west-1": 2}, "labels": {"team": "core"}}`))
+
+	err := f(`{"regions": {"us-east-1": 1, "mars-1": 2}, "labels": {"Team": "core"}}`)
+	failIfOk(t, err)
+
+	want := "load config: fields have invalid values: " +
+		`Regions key "mars-1" must be one of [us-east-1 eu-west-1]; ` +
+		`Labels key "Team" must match "^[a-z]+$"`
+	mustEqual(t, err.Error(), want)
+}
+
+func TestItemsConstraint(t *testing.T) {
+	type TestConfig struct {
+		Hosts  []string       `minitems:"2" maxitems:"3"`
+		Limits map[string]int `maxitems:"2"`
+	}
+
+	f := func(data string) error {
+		t.Helper()
+
+		return LoaderFor(&TestConfig{}, Config{
+			NewParser:    newParser,
+			SkipDefaults: true,
+			SkipEnv:      true,
+			SkipFlags:    true,
+			Files:        []string{"config.json"},
+			FileSystem: fstest.MapFS{
+				"config.json": {Data: []byte(data)},
+			},
+		}).Load()
+	}
+
+	failIfErr(t, f(`{"hosts": ["a", "b"], "limits": {"x": 1}}`))
+
+	err := f(`{"hosts": ["a"], "limits": {"x": 1, "y": 2, "z": 3}}`)
+	failIfOk(t, err)
+
+	want := "load config: fields have invalid values: " +
+		"Hosts must have at least 2 items, got 1; " +
+		"Limits must have at most 2 items, got 3"
+	mustEqual(t, err.Error(), want)
+
+	err = f(`{"hosts": ["a", "b", "c", "d"]}`)
+	failIfOk(t, err)
+	mustEqual(t, err.Error(), "load config: fields have invalid values: Hosts must have at most 3 items, got 4")
+}
+
+func TestCheckKeys(t *testing.T) {
+	f := func(value any, tag, want string) {
+		t.Helper()
+
+		err := checkKeys(reflect.ValueOf(value), tag)
+		if want == "" {
+			failIfErr(t, err)
+			return
+		}
+		failIfOk(t, err)
+		mustEqual(t, err.Error(), want)
+	}
+
+	f(map[int]string{1: "a", 2: "b"}, "oneof=1 2 3", "")
+	f(map[int]string{4: "a"}, "oneof=1 2 3", `key "4" must be one of [1 2 3]`)
+	f(map[string]int{"a": 1}, "regex=[", "incorrect keys tag: error parsing regexp: missing closing ]: `[`")
+	f(map[string]int{"a": 1}, "oneof", `incorrect keys tag "oneof"`)
+	f(map[string]int{"a": 1}, "len=1", `incorrect keys tag: unknown rule "len"`)
+	f([]string{"a"}, "oneof=a", "keys tag isn't supported for []string")
+}
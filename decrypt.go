@@ -0,0 +1,61 @@
+package aconfig
+
+import (
+	"fmt"
+	"strings"
+)
+
+// encryptedPrefix marks encrypted values in files and sources, see Config.Decrypt.
+const encryptedPrefix = "enc:v1:"
+
+// decryptValues replaces encrypted string values with the plaintext. See Config.Decrypt.
+func (l *Loader) decryptValues(values map[string]interface{}) (map[string]interface{}, error) {
+	if l.config.Decrypt == nil {
+		return values, nil
+	}
+	res, err := l.decryptValue("", values)
+	if err != nil {
+		return nil, err
+	}
+	return res.(map[string]interface{}), nil
+}
+
+func (l *Loader) decryptValue(key string, value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		if !strings.HasPrefix(v, encryptedPrefix) {
+			return v, nil
+		}
+		plaintext, err := l.config.Decrypt(strings.TrimPrefix(v, encryptedPrefix))
+		if err != nil {
+			return nil, fmt.Errorf("decrypt %s: %w", key, err)
+		}
+		return plaintext, nil
+	case []interface{}:
+		for i := range v {
+			val, err := l.decryptValue(fmt.Sprintf("%s[%d]", key, i), v[i])
+			if err != nil {
+				return nil, err
+			}
+			v[i] = val
+		}
+		return v, nil
+	default:
+		m, ok := asMap(v)
+		if !ok {
+			return v, nil
+		}
+		for k, val := range m {
+			name := k
+			if key != "" {
+				name = key + "." + k
+			}
+			val, err := l.decryptValue(name, val)
+			if err != nil {
+				return nil, err
+			}
+			m[k] = val
+		}
+		return m, nil
+	}
+}
@@ -0,0 +1,71 @@
+package aconfig
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestDecrypt(t *testing.T) {
+	type TestConfig struct {
+		User     string
+		Password string
+		Tokens   []string
+		DB       struct {
+			Password string
+		}
+		APIKey string
+	}
+
+	enc := func(s string) string {
+		return encryptedPrefix + base64.StdEncoding.EncodeToString([]byte(s))
+	}
+
+	fsys := fstest.MapFS{
+		"config.json": &fstest.MapFile{Data: []byte(`{
+			"user": "admin",
+			"password": "` + enc("secret") + `",
+			"tokens": ["plain", "` + enc("token") + `"],
+			"db": {"password": "` + enc("db-secret") + `"}
+		}`)},
+		"broken.json": &fstest.MapFile{Data: []byte(`{"db": {"password": "enc:v1:???"}}`)},
+	}
+	src := &mapSource{name: "kv", format: "json", values: map[string]any{"api_key": enc("key")}}
+
+	load := func(file string) (TestConfig, error) {
+		var cfg TestConfig
+		err := LoaderFor(&cfg, Config{
+			NewParser:    newParser,
+			SkipDefaults: true,
+			SkipEnv:      true,
+			SkipFlags:    true,
+			Files:        []string{file},
+			FileSystem:   fsys,
+			Sources:      []Source{src},
+			Decrypt: func(ciphertext string) (string, error) {
+				data, err := base64.StdEncoding.DecodeString(ciphertext)
+				return string(data), err
+			},
+		}).Load()
+		return cfg, err
+	}
+
+	cfg, err := load("config.json")
+	failIfErr(t, err)
+
+	want := TestConfig{
+		User:     "admin",
+		Password: "secret",
+		Tokens:   []string{"plain", "token"},
+		APIKey:   "key",
+	}
+	want.DB.Password = "db-secret"
+	mustEqual(t, cfg, want)
+
+	_, err = load("broken.json")
+	failIfOk(t, err)
+	if !strings.Contains(err.Error(), "decrypt db.password") {
+		t.Fatalf("have: %v", err)
+	}
+}
@@ -0,0 +1,60 @@
+package aconfig
+
+import (
+	"errors"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// dirFiles returns config files from Config.Dirs in sorted order, nested directories included.
+// Hidden files and directories (starting with a dot), backups (ending with ~)
+// and files without a registered decoder are skipped. Missing directories are returned separately.
+func (l *Loader) dirFiles() (files, missing []string, err error) {
+	for _, dir := range l.config.Dirs {
+		err := fs.WalkDir(l.fsys, dir, func(name string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			base := d.Name()
+			if name != dir && strings.HasPrefix(base, ".") {
+				if d.IsDir() {
+					return fs.SkipDir
+				}
+				return nil
+			}
+			if d.IsDir() || strings.HasSuffix(base, "~") {
+				return nil
+			}
+			ext := strings.ToLower(path.Ext(base))
+			if _, ok := l.config.FileDecoders[ext]; ok {
+				files = append(files, name)
+			}
+			return nil
+		})
+		switch {
+		case err == nil:
+		case errors.Is(err, fs.ErrNotExist) && !l.config.FailOnFileNotFound:
+			missing = append(missing, dir)
+		default:
+			return nil, nil, err
+		}
+	}
+	return files, missing, nil
+}
+
+// loadDirs loads and merges files from Config.Dirs.
+func (l *Loader) loadDirs() error {
+	files, missing, err := l.dirFiles()
+	if err != nil {
+		return err
+	}
+	l.missingFiles = append(l.missingFiles, missing...)
+
+	for _, file := range files {
+		if err := l.loadFile(FileEntry{Path: file, FileSystem: l.fsys}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package aconfig
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestDirs(t *testing.T) {
+	type TestConfig struct {
+		Name  string
+		Port  int
+		Level string
+		Extra string
+	}
+
+	fsys := fstest.MapFS{
+		"base.json":                &fstest.MapFile{Data: []byte(`{"name": "base", "level": "info"}`)},
+		"conf.d/10-a.json":         &fstest.MapFile{Data: []byte(`{"name": "a", "port": 10}`)},
+		"conf.d/20-b.json":         &fstest.MapFile{Data: []byte(`{"port": 20}`)},
+		"conf.d/15/extra.json":     &fstest.MapFile{Data: []byte(`{"extra": "nested", "port": 15}`)},
+		"conf.d/30-c.json~":        &fstest.MapFile{Data: []byte(`{"name": "backup"}`)},
+		"conf.d/.40-hidden.json":   &fstest.MapFile{Data: []byte(`{"name": "hidden"}`)},
+		"conf.d/.git/config.json":  &fstest.MapFile{Data: []byte(`{"name": "git"}`)},
+		"conf.d/50-readme.txt":     &fstest.MapFile{Data: []byte(`not a config`)},
+		"conf.d/60-broken.unknown": &fstest.MapFile{Data: []byte(`{`)},
+	}
+
+	var cfg TestConfig
+	loader := LoaderFor(&cfg, Config{
+		NewParser:    newParser,
+		SkipDefaults: true,
+		SkipEnv:      true,
+		SkipFlags:    true,
+		Files:        []string{"base.json"},
+		Dirs:         []string{"conf.d", "missing.d"},
+		FileSystem:   fsys,
+	})
+	failIfErr(t, loader.Load())
+
+	want := TestConfig{
+		Name:  "a",
+		Port:  20,
+		Level: "info",
+		Extra: "nested",
+	}
+	mustEqual(t, cfg, want)
+	mustEqual(t, loader.MissingFiles(), []string{"missing.d"})
+}
+
+func TestDirsFailOnNotFound(t *testing.T) {
+	type TestConfig struct {
+		Name string
+	}
+
+	var cfg TestConfig
+	err := LoaderFor(&cfg, Config{
+		NewParser:          newParser,
+		SkipDefaults:       true,
+		SkipEnv:            true,
+		SkipFlags:          true,
+		FailOnFileNotFound: true,
+		Dirs:               []string{"missing.d"},
+		FileSystem:         fstest.MapFS{},
+	}).Load()
+	failIfOk(t, err)
+}
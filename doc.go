@@ -5,8 +5,50 @@
 // Defaults are defined in structure tags (`default` tag). For files JSON, YAML, TOML and .Env are supported.
 //
 // Environment variables and flag parameters can have an optional prefix to separate them from other entries.
+// Repeated flags of slice and map fields accumulate: `-tag=a -tag=b` is the same as `-tag=a,b`
+// and `-label=k=v -label=k2=v2` is the same as `-label=k:v,k2:v2`.
 //
 // Also, aconfig is dependency-free, file decoders are used as separate modules (submodules to be exact) and are added to your go.mod only when used.
 //
+// Empty values are treated as not provided. To set a field to an empty string explicitly
+// (and to satisfy `required` tag with it) mark the field with `aconfig:",allowempty"` tag.
+//
+// Field of map[string]string type with `aconfig:",envremain"` tag gets all env vars with Config.EnvPrefix
+// that match no other field, keys are names without the prefix. Useful to pass env vars to plugins or subprocesses.
+// Config.EnvPrefix is required for such a field, otherwise it would get the whole environment.
+//
+// Field with `aconfig:"-"` tag is ignored by the loader: it isn't loaded from any source,
+// has no generated names and isn't listed in docs, dumps or required checks.
+//
+// Values can be constrained with `min`, `max` and `oneof` tags, e.g. `min:"1" max:"65535"` or `oneof:"debug info"`.
+// Numbers are compared by value, strings, slices and maps by length. Only set fields are checked.
+// Map keys are checked with `keys` tag: `keys:"oneof=us-east-1 eu-west-1"` or `keys:"regex=^[a-z]+$"`.
+// Number of items in slices and maps is limited with `minitems` and `maxitems` tags, e.g. `maxitems:"100"`.
+//
+// `usage` tag can be a text/template with {{.Default}}, {{.Env}}, {{.Flag}} and {{.Name}} fields,
+// like `usage:"listen address (env {{.Env}})"`, it's expanded for flags, help and docs.
+//
+// Numbers for time.Duration fields are nanoseconds unless the field has `unit` tag:
+// with `unit:"s"` value 30 from a file or env is 30 seconds. Supported units are ns, us, ms, s, m and h.
+//
+// time.Time fields are parsed with RFC 3339 or with a layout from `layout` tag,
+// like `layout:"2006-01-02"`, the same for defaults, files, env and flags.
+//
+// `order` tag changes the order of fields in WalkFields, help and docs without reordering the struct:
+// fields are sorted by it, fields without the tag have order 0 and keep the declaration order.
+//
+// Types from other modules that the loader can't set, like zap.AtomicLevel, are supported with Config.Adapters,
+// adapters for common types are in submodules like aconfigzap.
+//
+// Defaults can differ per environment: with Config.Environment set to "prod"
+// a field with `default:"10" default.prod:"100"` tags gets 100.
+//
+// Values of fields with `secret:"true"` tag (or inside of a struct with it) are redacted
+// when the config is printed with Config.PrintConfigFlag.
+//
+// Fields of Dynamic type, like Dynamic[string] for a log level, are updated in place
+// by Loader.Watch and Loader.ReloadOn, read them with Load.
+// To read the whole configuration from many goroutines while it's reloaded use Value[T], see NewValue.
+//
 // Loader configuration (`Config` type) has different ways to configure loader, to skip some sources, define prefixes, fail on unknown params.
 package aconfig
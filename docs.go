@@ -0,0 +1,81 @@
+package aconfig
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// GenerateDocs writes a Markdown table that describes configuration fields.
+// Each row has field path, env var, flag, default value, required mark and usage.
+// Rows are in the declaration order, fields with `order` tag are sorted by it.
+// Env and flag columns are omitted when Config.SkipEnv and Config.SkipFlags are set.
+func (l *Loader) GenerateDocs(w io.Writer) error {
+	l.stateMu.RLock()
+	defer l.stateMu.RUnlock()
+
+	if l.errInit != nil {
+		return fmt.Errorf("init loader: %w", l.errInit)
+	}
+
+	header := []string{"Field"}
+	if !l.config.SkipEnv {
+		header = append(header, "Env")
+	}
+	if !l.config.SkipFlags {
+		header = append(header, "Flag")
+	}
+	header = append(header, "Default", "Required", "Usage")
+
+	var sb strings.Builder
+	writeDocsRow(&sb, header)
+
+	sep := make([]string, len(header))
+	for i := range sep {
+		sep[i] = "---"
+	}
+	writeDocsRow(&sb, sep)
+
+	fields := l.allFields()
+	sortByOrderTag(fields)
+	for _, field := range fields {
+		row := []string{"`" + field.Name() + "`"}
+		if !l.config.SkipEnv {
+			row = append(row, docsCode(l.sourceName(field, "env")))
+		}
+		if !l.config.SkipFlags {
+			name := l.sourceName(field, "flag")
+			if name != "" {
+				name = "-" + name
+			}
+			row = append(row, docsCode(name))
+		}
+
+		required := ""
+		if isFieldRequired(field) {
+			required = "yes"
+		}
+		row = append(row, docsCode(field.Tag("default")), required, field.Tag("usage"))
+		writeDocsRow(&sb, row)
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func writeDocsRow(sb *strings.Builder, cells []string) {
+	sb.WriteString("|")
+	for _, cell := range cells {
+		sb.WriteString(" ")
+		sb.WriteString(strings.ReplaceAll(cell, "|", `\|`))
+		sb.WriteString(" |")
+	}
+	sb.WriteString("\n")
+}
+
+func docsCode(s string) string {
+	if s == "" {
+		return ""
+	}
+	return "`" + s + "`"
+}
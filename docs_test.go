@@ -0,0 +1,78 @@
+package aconfig
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestGenerateDocs(t *testing.T) {
+	type DocsConfig struct {
+		HTTPPort int `default:"8080" usage:"port to listen"`
+		Auth     struct {
+			User string `required:"true" usage:"user name"`
+		}
+		Modes []string `default:"a|b" usage:"one of a|b"`
+	}
+
+	loader := LoaderFor(&DocsConfig{}, Config{
+		NewParser: newParser,
+		SkipFiles: true,
+		EnvPrefix: "APP",
+		Args:      []string{},
+	})
+
+	var buf bytes.Buffer
+	failIfErr(t, loader.GenerateDocs(&buf))
+
+	want := "| Field | Env | Flag | Default | Required | Usage |\n" +
+		"| --- | --- | --- | --- | --- | --- |\n" +
+		"| `HTTPPort` | `APP_HTTP_PORT` | `-http_port` | `8080` |  | port to listen |\n" +
+		"| `Auth.User` | `APP_AUTH_USER` | `-auth.user` |  | yes | user name |\n" +
+		"| `Modes` | `APP_MODES` | `-modes` | `a\\|b` |  | one of a\\|b |\n"
+	mustEqual(t, buf.String(), want)
+}
+
+func TestFieldDefaultAndEnumValues(t *testing.T) {
+	type TestConfig struct {
+		Port    int           `default:"8080"`
+		Timeout time.Duration `default:"5s"`
+		Level   string        `default:"info" oneof:"debug info warn"`
+		Hosts   []string      `default:"a,b"`
+		Ratio   *float64      `default:"0.5"`
+		Name    string
+	}
+
+	loader := LoaderFor(&TestConfig{}, Config{
+		NewParser: newParser,
+		SkipFiles: true,
+		Args:      []string{},
+	})
+
+	ratio := 0.5
+	wantDefaults := map[string]any{
+		"Port":    8080,
+		"Timeout": 5 * time.Second,
+		"Level":   "info",
+		"Hosts":   []string{"a", "b"},
+		"Ratio":   &ratio,
+		"Name":    nil,
+	}
+	seen := 0
+	loader.WalkFields(func(f Field) bool {
+		seen++
+		want, ok := wantDefaults[f.Name()]
+		if !ok {
+			t.Fatalf("unexpected field %s", f.Name())
+		}
+		mustEqual(t, f.DefaultValue(), want)
+
+		if f.Name() == "Level" {
+			mustEqual(t, f.EnumValues(), []string{"debug", "info", "warn"})
+		} else if f.EnumValues() != nil {
+			t.Fatalf("want no enum values for %s, got %v", f.Name(), f.EnumValues())
+		}
+		return true
+	})
+	mustEqual(t, seen, len(wantDefaults))
+}
@@ -0,0 +1,104 @@
+package aconfig
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DotenvOptions configure Loader.WriteDotenv.
+type DotenvOptions struct {
+	// Export set to true writes lines as `export NAME=value`, so the file can be sourced by a shell.
+	Export bool
+
+	// SkipUnset set to true doesn't write fields that weren't set by any source.
+	SkipUnset bool
+}
+
+// WriteDotenv writes the loaded configuration as a .env file with env names of the loader,
+// one field per line. It can be passed to a child process, so it loads the same configuration.
+// Fields with `secret:"true"` tag and fields without env name are skipped.
+func (l *Loader) WriteDotenv(w io.Writer, opts DotenvOptions) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.stateMu.RLock()
+	defer l.stateMu.RUnlock()
+
+	root := reflect.ValueOf(l.dst).Elem()
+
+	bw := bufio.NewWriter(w)
+	for _, field := range l.allFields() {
+		name := l.sourceName(field, "env")
+		if name == "" || isSecret(field) {
+			continue
+		}
+		if opts.SkipUnset && !isFieldSet(field) {
+			continue
+		}
+
+		_, index := fieldInfo(field)
+		value := envValue(reflect.ValueOf(valueByIndex(root, index)), l.config.SliceSeparator)
+		if opts.Export {
+			bw.WriteString("export ")
+		}
+		fmt.Fprintf(bw, "%s=%s\n", name, quoteDotenv(value))
+	}
+	return bw.Flush()
+}
+
+// envValue formats the value the way the loader parses it from env:
+// slices are joined with sep and maps are written as `key:value,key2:value2`.
+func envValue(v reflect.Value, sep string) string {
+	if !v.IsValid() {
+		return ""
+	}
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	if v.Type() == reflect.TypeOf(time.Duration(0)) {
+		return formatDuration(time.Duration(v.Int()))
+	}
+	if m, ok := textMarshaler(v); ok {
+		if text, err := m.MarshalText(); err == nil {
+			return string(text)
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+			return string(v.Bytes())
+		}
+		items := make([]string, v.Len())
+		for i := range items {
+			items[i] = envValue(v.Index(i), sep)
+		}
+		return strings.Join(items, sep)
+	case reflect.Map:
+		items := make([]string, 0, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			items = append(items, envValue(iter.Key(), sep)+":"+envValue(iter.Value(), sep))
+		}
+		sort.Strings(items)
+		return strings.Join(items, ",")
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+}
+
+// quoteDotenv quotes the value with double quotes if it has spaces or special characters.
+func quoteDotenv(value string) string {
+	if !strings.ContainsAny(value, " \t\n\r#\"'\\$`") {
+		return value
+	}
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "$", `\$`, "`", "\\`")
+	return `"` + r.Replace(value) + `"`
+}
@@ -0,0 +1,76 @@
+package aconfig
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestWriteDotenv(t *testing.T) {
+	type TestConfig struct {
+		Port     int           `default:"8080"`
+		Timeout  time.Duration `default:"90s"`
+		Hosts    []string      `default:"a,b"`
+		Limits   map[string]int
+		Greeting string
+		Skipped  string `env:"-"`
+		Token    string `secret:"true"`
+		Unset    string
+	}
+
+	var cfg TestConfig
+	loader := LoaderFor(&cfg, Config{
+		NewParser: newParser,
+		SkipFlags: true,
+		EnvPrefix: "APP",
+		Files:     []string{"config.json"},
+		FileSystem: fstest.MapFS{
+			"config.json": {Data: []byte(`{"limits": {"rps": 10, "burst": 20}}`)},
+		},
+		Envs: []string{
+			`APP_GREETING=hello "world"`,
+			"APP_TOKEN=secret",
+		},
+	})
+	failIfErr(t, loader.Load())
+
+	var out strings.Builder
+	failIfErr(t, loader.WriteDotenv(&out, DotenvOptions{SkipUnset: true}))
+
+	want := "APP_PORT=8080\n" +
+		"APP_TIMEOUT=1m30s\n" +
+		"APP_HOSTS=a,b\n" +
+		"APP_LIMITS=burst:20,rps:10\n" +
+		`APP_GREETING="hello \"world\""` + "\n"
+	mustEqual(t, out.String(), want)
+
+	out.Reset()
+	failIfErr(t, loader.WriteDotenv(&out, DotenvOptions{Export: true}))
+	if !strings.HasPrefix(out.String(), "export APP_PORT=8080\n") || !strings.Contains(out.String(), "export APP_UNSET=\n") {
+		t.Fatalf("have: %s", out.String())
+	}
+
+	if newParser {
+		// new parser doesn't read maps from env.
+		return
+	}
+
+	// the output is loaded back into the same configuration.
+	var envs []string
+	out.Reset()
+	failIfErr(t, loader.WriteDotenv(&out, DotenvOptions{}))
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		envs = append(envs, strings.Replace(line, `"hello \"world\""`, `hello "world"`, 1))
+	}
+	var cfg2 TestConfig
+	failIfErr(t, LoaderFor(&cfg2, Config{
+		NewParser: newParser,
+		SkipFiles: true,
+		SkipFlags: true,
+		EnvPrefix: "APP",
+		Envs:      envs,
+	}).Load())
+	cfg.Token = ""
+	mustEqual(t, cfg2, cfg)
+}
@@ -0,0 +1,53 @@
+package aconfig
+
+import "sort"
+
+// DriftReport describes differences between the configuration structure and the loaded files.
+type DriftReport struct {
+	UnknownKeys   []FileKey `json:"unknown_keys"`   // UnknownKeys are file keys that match no field.
+	MissingFields []string  `json:"missing_fields"` // MissingFields are fields that no loaded file provides.
+}
+
+// FileKey is a key in a configuration file.
+type FileKey struct {
+	File string `json:"file"` // File is a path to the file.
+	Key  string `json:"key"`  // Key is a dotted path like `db.host`.
+}
+
+// IsEmpty reports whether there is no drift.
+func (r DriftReport) IsEmpty() bool {
+	return len(r.UnknownKeys) == 0 && len(r.MissingFields) == 0
+}
+
+// Drift reports file keys that match no field and fields that no loaded file provides.
+// Unknown keys are reported even when Config.AllowUnknownFields is set.
+// Should be called after Load, helps to keep sample configs in sync with the code.
+// Fields are in the declaration order, keys are sorted by file and key.
+func (l *Loader) Drift() DriftReport {
+	l.stateMu.RLock()
+	defer l.stateMu.RUnlock()
+
+	var report DriftReport
+
+	for _, key := range l.unknownKeys {
+		report.UnknownKeys = append(report.UnknownKeys, FileKey{File: key.file, Key: key.key})
+	}
+	sort.Slice(report.UnknownKeys, func(i, j int) bool {
+		a, b := report.UnknownKeys[i], report.UnknownKeys[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		return a.Key < b.Key
+	})
+
+	fileFields := l.fileFields
+	if l.config.NewParser && l.parser != nil {
+		fileFields = l.parser.fileFields
+	}
+	for _, field := range l.allFields() {
+		if _, ok := fileFields[field.Name()]; !ok {
+			report.MissingFields = append(report.MissingFields, field.Name())
+		}
+	}
+	return report
+}
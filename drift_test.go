@@ -0,0 +1,41 @@
+package aconfig
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestDrift(t *testing.T) {
+	type TestConfig struct {
+		Host string
+		Port int
+		DB   struct {
+			Name string
+			User string
+		}
+	}
+
+	loader := LoaderFor(&TestConfig{}, Config{
+		NewParser:          newParser,
+		SkipFlags:          true,
+		SkipEnv:            true,
+		AllowUnknownFields: true,
+		MergeFiles:         true,
+		Files:              []string{"base.json", "local.json"},
+		FileSystem: fstest.MapFS{
+			"base.json":  {Data: []byte(`{"host": "localhost", "db": {"name": "app"}, "timeout": "5s"}`)},
+			"local.json": {Data: []byte(`{"host": "127.0.0.1", "debug": true}`)},
+		},
+	})
+	failIfErr(t, loader.Load())
+
+	want := DriftReport{
+		UnknownKeys: []FileKey{
+			{File: "base.json", Key: "timeout"},
+			{File: "local.json", Key: "debug"},
+		},
+		MissingFields: []string{"Port", "DB.User"},
+	}
+	mustEqual(t, loader.Drift(), want)
+	mustEqual(t, loader.Drift().IsEmpty(), false)
+}
@@ -0,0 +1,135 @@
+package aconfig
+
+import (
+	"encoding"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// FileEncoder is an optional interface for FileDecoder to support Loader.Dump.
+type FileEncoder interface {
+	Encode(w io.Writer, values map[string]any) error
+}
+
+// Dump writes the loaded configuration to w in a given file format like "json" or "yaml".
+// Names are the same as the loader uses for the format, nested keys become nested objects.
+// JSON is supported out of the box, other formats require a FileDecoder that implements FileEncoder.
+// Values of fields with `secret:"true"` tag are redacted like in Config.PrintConfigFlag.
+func (l *Loader) Dump(w io.Writer, format string) error {
+	var enc FileEncoder
+	for _, dec := range l.config.FileDecoders {
+		if dec.Format() != format {
+			continue
+		}
+		if e, ok := dec.(FileEncoder); ok {
+			enc = e
+			break
+		}
+	}
+	if enc == nil {
+		return fmt.Errorf("format %q doesn't support encoding", format)
+	}
+
+	// mu guards dst during reloads and is taken before stateMu like in reload.
+	l.mu.Lock()
+	l.stateMu.RLock()
+	values := l.dumpValues(format)
+	l.stateMu.RUnlock()
+	l.mu.Unlock()
+
+	return enc.Encode(w, values)
+}
+
+// dumpValues returns field values as nested maps keyed by names for the given format.
+func (l *Loader) dumpValues(format string) map[string]any {
+	res := map[string]any{}
+	root := reflect.ValueOf(l.dst).Elem()
+	for _, field := range l.allFields() {
+		name := l.sourceName(field, format)
+		if name == "" {
+			continue
+		}
+		if isSecret(field) {
+			setNested(res, strings.Split(name, "."), redacted)
+			continue
+		}
+		_, index := fieldInfo(field)
+		setNested(res, strings.Split(name, "."), l.render(reflect.ValueOf(valueByIndex(root, index))))
+	}
+	return res
+}
+
+// renderValue returns a value in a human-readable form suitable for encoding:
+// durations like 5m, TextMarshaler values as text, byte slices as strings
+// and values with a Format in adapters as formatted text.
+func renderValue(v reflect.Value, adapters map[reflect.Type]Adapter) any {
+	if !v.IsValid() {
+		return nil
+	}
+	if adapter, ok := adapters[v.Type()]; ok && adapter.Format != nil && !isNilPtr(v.Interface()) {
+		return adapter.Format(v.Interface())
+	}
+	if v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		return renderValue(v.Elem(), adapters)
+	}
+
+	if v.Type() == reflect.TypeOf(time.Duration(0)) {
+		return formatDuration(time.Duration(v.Int()))
+	}
+	if m, ok := textMarshaler(v); ok {
+		if text, err := m.MarshalText(); err == nil {
+			return string(text)
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 && v.Kind() == reflect.Slice {
+			return string(v.Bytes())
+		}
+		res := make([]any, v.Len())
+		for i := range res {
+			res[i] = renderValue(v.Index(i), adapters)
+		}
+		return res
+	case reflect.Map:
+		res := make(map[string]any, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			key := renderValue(iter.Key(), adapters)
+			res[fmt.Sprint(key)] = renderValue(iter.Value(), adapters)
+		}
+		return res
+	default:
+		return v.Interface()
+	}
+}
+
+func textMarshaler(v reflect.Value) (encoding.TextMarshaler, bool) {
+	if m, ok := v.Interface().(encoding.TextMarshaler); ok {
+		return m, true
+	}
+	if v.CanAddr() {
+		m, ok := v.Addr().Interface().(encoding.TextMarshaler)
+		return m, ok
+	}
+	return nil, false
+}
+
+// formatDuration is time.Duration.String without zero units at the end: 5m instead of 5m0s.
+func formatDuration(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = s[:len(s)-2]
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = s[:len(s)-2]
+	}
+	return s
+}
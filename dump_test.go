@@ -0,0 +1,98 @@
+package aconfig
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDump(t *testing.T) {
+	type DumpConfig struct {
+		HTTPPort int `default:"8080"`
+		Auth     struct {
+			User  string `default:"root"`
+			Pass  string `json:"password"`
+			Token string `secret:"true"`
+		}
+		Tags []string `default:"a,b"`
+	}
+
+	var cfg DumpConfig
+	loader := LoaderFor(&cfg, Config{
+		NewParser: newParser,
+		SkipFiles: true,
+		SkipFlags: true,
+		EnvPrefix: "APP",
+		Envs:      []string{"APP_AUTH_PASS=secret", "APP_AUTH_TOKEN=token"},
+	})
+	failIfErr(t, loader.Load())
+
+	var buf bytes.Buffer
+	failIfErr(t, loader.Dump(&buf, "json"))
+
+	want := `{
+  "auth": {
+    "password": "secret",
+    "token": "<redacted>",
+    "user": "root"
+  },
+  "http_port": 8080,
+  "tags": [
+    "a",
+    "b"
+  ]
+}
+`
+	mustEqual(t, buf.String(), want)
+
+	failIfOk(t, loader.Dump(&buf, "yaml"))
+}
+
+func TestDumpHumanReadable(t *testing.T) {
+	type DumpConfig struct {
+		Timeout  time.Duration   `default:"5m"`
+		Interval *time.Duration  `default:"1h30m"`
+		MaxSize  byteSize        `default:"512MiB"`
+		Token    []byte          `default:"secret"`
+		Limits   []time.Duration `default:"1s,2m"`
+	}
+
+	loader := LoaderFor(&DumpConfig{}, Config{
+		NewParser: newParser,
+		SkipFiles: true,
+		SkipFlags: true,
+		Envs:      []string{},
+	})
+	failIfErr(t, loader.Load())
+
+	var buf bytes.Buffer
+	failIfErr(t, loader.Dump(&buf, "json"))
+
+	want := `{
+  "interval": "1h30m",
+  "limits": [
+    "1s",
+    "2m"
+  ],
+  "max_size": "512MiB",
+  "timeout": "5m",
+  "token": "secret"
+}
+`
+	mustEqual(t, buf.String(), want)
+}
+
+// byteSize is a size in bytes written like 512MiB.
+type byteSize int64
+
+func (b byteSize) MarshalText() ([]byte, error) {
+	return []byte(strconv.FormatInt(int64(b)>>20, 10) + "MiB"), nil
+}
+
+func (b *byteSize) UnmarshalText(text []byte) error {
+	n, err := strconv.ParseInt(strings.TrimSuffix(string(text), "MiB"), 10, 64)
+	*b = byteSize(n << 20)
+	return err
+}
@@ -0,0 +1,192 @@
+package aconfig
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// Dynamic is a field that is updated in place by reloads, like a log level that can be changed without a restart.
+// Loader.Watch and Loader.ReloadOn store new values into Dynamic fields of the destination,
+// so code that keeps a pointer to the field (or to the whole config) reads the latest value with Load.
+//
+//	type Config struct {
+//		LogLevel aconfig.Dynamic[string] `default:"info"`
+//	}
+//
+// T can be a string, a bool, a number, time.Duration or a type that implements encoding.TextUnmarshaler.
+// Fields with `reload:"false"` tag aren't updated. Dynamic must not be copied after the first load.
+type Dynamic[T any] struct {
+	v atomic.Value // of dynamicBox[T], so nil interfaces can be stored too.
+}
+
+type dynamicBox[T any] struct {
+	value T
+}
+
+// dynamicField is implemented by Dynamic fields.
+type dynamicField interface {
+	storeFrom(src any)
+}
+
+var dynamicFieldType = reflect.TypeOf((*dynamicField)(nil)).Elem()
+
+var (
+	_ encoding.TextUnmarshaler = (*Dynamic[string])(nil)
+	_ encoding.TextMarshaler   = (*Dynamic[string])(nil)
+	_ dynamicField             = (*Dynamic[string])(nil)
+)
+
+// Load returns the current value.
+func (d *Dynamic[T]) Load() T {
+	box, _ := d.v.Load().(dynamicBox[T])
+	return box.value
+}
+
+// Store sets the value.
+func (d *Dynamic[T]) Store(value T) {
+	d.v.Store(dynamicBox[T]{value: value})
+}
+
+// String returns the current value formatted with fmt.Sprint.
+func (d *Dynamic[T]) String() string {
+	return fmt.Sprint(d.Load())
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (d *Dynamic[T]) MarshalText() ([]byte, error) {
+	value := d.Load()
+	if m, ok := any(&value).(encoding.TextMarshaler); ok {
+		return m.MarshalText()
+	}
+	if v, ok := any(value).(time.Duration); ok {
+		return []byte(formatDuration(v)), nil
+	}
+	return []byte(fmt.Sprint(value)), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. Empty text is the zero value.
+func (d *Dynamic[T]) UnmarshalText(text []byte) error {
+	var value T
+	if len(text) != 0 {
+		if err := parseText(reflect.ValueOf(&value).Elem(), string(text)); err != nil {
+			return err
+		}
+	}
+	d.Store(value)
+	return nil
+}
+
+func (d *Dynamic[T]) storeFrom(src any) {
+	d.Store(src.(*Dynamic[T]).Load())
+}
+
+// parseText sets a scalar value from the text.
+func parseText(v reflect.Value, text string) error {
+	if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(text))
+	}
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(text)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(text)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(text)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(text, 0, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(text, 0, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(text, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	default:
+		return fmt.Errorf("type %s isn't supported", v.Type())
+	}
+	return nil
+}
+
+// isDynamicType reports whether the type is Dynamic.
+func isDynamicType(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Struct && reflect.PtrTo(typ).Implements(dynamicFieldType)
+}
+
+// hasDynamic reports whether the struct has Dynamic fields, directly or in nested structs and pointers to them.
+func hasDynamic(typ reflect.Type) bool {
+	return hasDynamicSeen(typ, map[reflect.Type]bool{})
+}
+
+func hasDynamicSeen(typ reflect.Type, seen map[reflect.Type]bool) bool {
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	// recursive types like a linked list are checked once.
+	if typ.Kind() != reflect.Struct || seen[typ] {
+		return false
+	}
+	seen[typ] = true
+
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if isDynamicType(field.Type) || hasDynamicSeen(field.Type, seen) {
+			return true
+		}
+	}
+	return false
+}
+
+// setConfig copies src into dst, Dynamic fields are updated in place with Store.
+// When there are Dynamic fields, unexported fields of dst keep their values.
+func setConfig(dst, src reflect.Value) {
+	if !hasDynamic(dst.Type()) {
+		dst.Set(src)
+		return
+	}
+	for i := 0; i < dst.NumField(); i++ {
+		df, sf := dst.Field(i), src.Field(i)
+		switch {
+		case !df.CanSet():
+		case isDynamicType(df.Type()):
+			df.Addr().Interface().(dynamicField).storeFrom(sf.Addr().Interface())
+		case df.Kind() == reflect.Ptr && hasDynamic(df.Type()):
+			// a new or a removed struct has no fields to update in place.
+			if df.IsNil() || sf.IsNil() {
+				df.Set(sf)
+			} else {
+				setConfig(df.Elem(), sf.Elem())
+			}
+		case hasDynamic(df.Type()):
+			setConfig(df, sf)
+		default:
+			df.Set(sf)
+		}
+	}
+}
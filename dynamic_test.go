@@ -0,0 +1,103 @@
+package aconfig
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDynamic(t *testing.T) {
+	type TestConfig struct {
+		Port     int
+		LogLevel Dynamic[string]        `default:"info"`
+		Limit    Dynamic[int]           `default:"10"`
+		Timeout  Dynamic[time.Duration] `default:"1s"`
+		Sub      struct {
+			Debug Dynamic[bool]
+		}
+	}
+
+	file := filepath.Join(t.TempDir(), "config.json")
+	writeFile(t, file, `{"port": 1111, "limit": 20}`)
+
+	var cfg TestConfig
+	loader := LoaderFor(&cfg, Config{
+		NewParser:     newParser,
+		SkipEnv:       true,
+		SkipFlags:     true,
+		Files:         []string{file},
+		WatchInterval: 10 * time.Millisecond,
+	})
+	failIfErr(t, loader.Load())
+	mustEqual(t, cfg.LogLevel.Load(), "info")
+	mustEqual(t, cfg.Limit.Load(), 20)
+	mustEqual(t, cfg.Timeout.Load(), time.Second)
+	mustEqual(t, cfg.Sub.Debug.Load(), false)
+
+	// readers keep a pointer to the field and never lock.
+	level := &cfg.LogLevel
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for ctx.Err() == nil {
+			_ = level.Load()
+		}
+	}()
+
+	changes := make(chan error, 1)
+	go loader.Watch(ctx, func(err error) {
+		changes <- err
+	})
+
+	// let the watcher take the first snapshot.
+	time.Sleep(50 * time.Millisecond)
+
+	writeFile(t, file, `{"port": 1111, "log_level": "debug", "timeout": "5s", "sub": {"debug": true}}`)
+	failIfErr(t, waitChange(t, changes))
+
+	mustEqual(t, level.Load(), "debug")
+	mustEqual(t, cfg.Limit.Load(), 10)
+	mustEqual(t, cfg.Timeout.Load(), 5*time.Second)
+	mustEqual(t, cfg.Sub.Debug.Load(), true)
+
+	text, err := cfg.Timeout.MarshalText()
+	failIfErr(t, err)
+	mustEqual(t, string(text), "5s")
+
+	cancel()
+	wg.Wait()
+}
+
+func TestDynamicInPointer(t *testing.T) {
+	type TestConfig struct {
+		Sub *struct {
+			Level Dynamic[string] `default:"info"`
+		}
+	}
+
+	file := filepath.Join(t.TempDir(), "config.json")
+	writeFile(t, file, `{"sub": {"level": "warn"}}`)
+
+	var cfg TestConfig
+	loader := LoaderFor(&cfg, Config{
+		NewParser: newParser,
+		SkipEnv:   true,
+		SkipFlags: true,
+		Files:     []string{file},
+	})
+	failIfErr(t, loader.Load())
+	mustEqual(t, cfg.Sub.Level.Load(), "warn")
+
+	// readers keep a pointer to the field.
+	level := &cfg.Sub.Level
+	writeFile(t, file, `{"sub": {"level": "debug"}}`)
+	failIfErr(t, loader.Reload())
+	mustEqual(t, level.Load(), "debug")
+	mustEqual(t, cfg.Sub.Level.Load(), "debug")
+}
@@ -0,0 +1,78 @@
+package aconfig
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// envRemainFields returns fields with `aconfig:",envremain"` tag.
+func (l *Loader) envRemainFields() []Field {
+	var res []Field
+	for _, field := range l.allFields() {
+		sf, _ := fieldInfo(field)
+		if _, opts := parseAconfigTag(sf.Tag.Get("aconfig")); opts["envremain"] {
+			res = append(res, field)
+		}
+	}
+	return res
+}
+
+// checkEnvRemain checks types of fields with `aconfig:",envremain"` tag and that Config.EnvPrefix is set,
+// otherwise the field would get the whole environment of the process.
+// Unknown env vars aren't an error when there is such a field.
+func (l *Loader) checkEnvRemain() error {
+	fields := l.envRemainFields()
+	for _, field := range fields {
+		sf, _ := fieldInfo(field)
+		if sf.Type != reflect.TypeOf(map[string]string{}) {
+			return fmt.Errorf("field %s: envremain requires map[string]string, got %s", field.Name(), sf.Type)
+		}
+		if l.config.EnvPrefix == "" && !l.config.SkipEnv {
+			return fmt.Errorf("field %s: envremain requires EnvPrefix", field.Name())
+		}
+	}
+	if len(fields) != 0 {
+		l.config.AllowUnknownEnvs = true
+		if l.parser != nil {
+			l.parser.cfg.AllowUnknownEnvs = true
+		}
+	}
+	return nil
+}
+
+// loadEnvRemain sets fields with `aconfig:",envremain"` tag to env vars with EnvPrefix that match no field.
+// Keys are env names without the prefix.
+func (l *Loader) loadEnvRemain() error {
+	fields := l.envRemainFields()
+	if len(fields) == 0 {
+		return nil
+	}
+
+	known := map[string]bool{}
+	for _, field := range l.allFields() {
+		if name := l.sourceName(field, "env"); name != "" {
+			known[name] = true
+		}
+	}
+
+	values := map[string]any{}
+	for _, env := range l.config.Envs {
+		name, value, ok := cut(env, "=")
+		if !ok || known[name] || !strings.HasPrefix(name, l.config.EnvPrefix) {
+			continue
+		}
+		values[strings.TrimPrefix(name, l.config.EnvPrefix)] = value
+	}
+	if len(values) == 0 {
+		return nil
+	}
+
+	source := ValueSource{Kind: "env", Name: l.config.EnvPrefix + "*"}
+	for _, field := range fields {
+		if err := l.setFieldValue(field, values, source); err != nil {
+			return fmt.Errorf("field %s: %w", field.Name(), err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package aconfig
+
+import (
+	"testing"
+)
+
+func TestEnvRemain(t *testing.T) {
+	type TestConfig struct {
+		Port   int
+		Plugin struct {
+			Name string
+		}
+		Extra map[string]string `aconfig:",envremain"`
+	}
+
+	var cfg TestConfig
+	loader := LoaderFor(&cfg, Config{
+		NewParser:    newParser,
+		SkipDefaults: true,
+		SkipFiles:    true,
+		SkipFlags:    true,
+		EnvPrefix:    "APP",
+		Envs: []string{
+			"APP_PORT=80",
+			"APP_PLUGIN_NAME=auth",
+			"APP_PLUGIN_TOKEN=secret",
+			"APP_LOG_FORMAT=json",
+			"HOME=/root",
+		},
+	})
+	failIfErr(t, loader.Load())
+
+	mustEqual(t, cfg.Port, 80)
+	mustEqual(t, cfg.Plugin.Name, "auth")
+	mustEqual(t, cfg.Extra, map[string]string{"PLUGIN_TOKEN": "secret", "LOG_FORMAT": "json"})
+	mustEqual(t, loader.Explain("Extra"), "Extra: env APP_*")
+}
+
+func TestEnvRemainWrongType(t *testing.T) {
+	type TestConfig struct {
+		Extra map[string]int `aconfig:",envremain"`
+	}
+
+	err := LoaderFor(&TestConfig{}, Config{
+		NewParser: newParser,
+		SkipFiles: true,
+		SkipFlags: true,
+	}).Load()
+	failIfOk(t, err)
+	mustEqual(t, err.Error(), "init loader: field Extra: envremain requires map[string]string, got map[string]int")
+}
+
+func TestEnvRemainNoPrefix(t *testing.T) {
+	type TestConfig struct {
+		Extra map[string]string `aconfig:",envremain"`
+	}
+
+	err := LoaderFor(&TestConfig{}, Config{
+		NewParser: newParser,
+		SkipFiles: true,
+		SkipFlags: true,
+		Envs:      []string{"PATH=/bin", "TOKEN=secret"},
+	}).Load()
+	failIfOk(t, err)
+	mustEqual(t, err.Error(), "init loader: field Extra: envremain requires EnvPrefix")
+}
@@ -0,0 +1,52 @@
+package aconfig
+
+import (
+	"os"
+	"strings"
+)
+
+// expandEnv replaces env vars in string values of a decoded file. See Config.ExpandEnvInFiles.
+func (l *Loader) expandEnv(values map[string]interface{}) map[string]interface{} {
+	if !l.config.ExpandEnvInFiles {
+		return values
+	}
+	envs := getEnv(l.config.Envs)
+	return expandValue(values, envs).(map[string]interface{})
+}
+
+func expandValue(value interface{}, envs map[string]interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		return expandString(v, envs)
+	case []interface{}:
+		for i := range v {
+			v[i] = expandValue(v[i], envs)
+		}
+		return v
+	default:
+		m, ok := asMap(v)
+		if !ok {
+			return v
+		}
+		for key, val := range m {
+			m[key] = expandValue(val, envs)
+		}
+		return m
+	}
+}
+
+// expandString expands $VAR, ${VAR} and ${VAR:-default}, where default is used for unset or empty VAR.
+// $$ is a literal $.
+func expandString(s string, envs map[string]interface{}) string {
+	return os.Expand(s, func(name string) string {
+		if name == "$" {
+			return "$"
+		}
+		name, def, hasDef := strings.Cut(name, ":-")
+		value, _ := envs[name].(string)
+		if value == "" && hasDef {
+			return def
+		}
+		return value
+	})
+}
@@ -0,0 +1,68 @@
+package aconfig
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestExpandEnvInFiles(t *testing.T) {
+	type TestConfig struct {
+		DSN      string
+		Password string
+		Region   string
+		Price    string
+		Hosts    []string
+		DB       struct {
+			User string
+		}
+	}
+
+	fsys := fstest.MapFS{
+		"config.json": &fstest.MapFile{Data: []byte(`{
+			"dsn": "postgres://${DB_HOST:-localhost}:${DB_PORT:-5432}/app",
+			"password": "${DB_PASSWORD}",
+			"region": "${REGION:-eu}",
+			"price": "$$10",
+			"hosts": ["$HOST_A", "${HOST_B}"],
+			"db": {"user": "${DB_USER:-admin}"}
+		}`)},
+	}
+
+	load := func(expand bool) TestConfig {
+		t.Helper()
+
+		var cfg TestConfig
+		err := LoaderFor(&cfg, Config{
+			NewParser:        newParser,
+			SkipDefaults:     true,
+			SkipEnv:          true,
+			SkipFlags:        true,
+			ExpandEnvInFiles: expand,
+			Files:            []string{"config.json"},
+			FileSystem:       fsys,
+			Envs: []string{
+				"DB_HOST=db.local",
+				"DB_PASSWORD=secret",
+				"REGION=",
+				"HOST_A=a.local",
+				"HOST_B=b.local",
+			},
+		}).Load()
+		failIfErr(t, err)
+		return cfg
+	}
+
+	cfg := load(true)
+	want := TestConfig{
+		DSN:      "postgres://db.local:5432/app",
+		Password: "secret",
+		Region:   "eu",
+		Price:    "$10",
+		Hosts:    []string{"a.local", "b.local"},
+	}
+	want.DB.User = "admin"
+	mustEqual(t, cfg, want)
+
+	cfg = load(false)
+	mustEqual(t, cfg.Password, "${DB_PASSWORD}")
+}
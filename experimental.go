@@ -0,0 +1,173 @@
+package aconfig
+
+import (
+	"encoding"
+	"flag"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// Experiment is a set of experimental behaviors, see Config.Experimental.
+// Experiments might change or become the default in next releases.
+type Experiment uint
+
+const (
+	// ExperimentDeepMerge merges map fields from different sources key by key
+	// instead of replacing the whole map with the last one.
+	// Maps from several files are always merged, see Config.MergeFiles.
+	ExperimentDeepMerge Experiment = 1 << iota
+
+	// ExperimentTypedFlags registers flags with the type of the field:
+	// bool flags can be passed without a value and invalid numbers fail while flags are parsed.
+	ExperimentTypedFlags
+
+	// ExperimentStrictCoercion fails when a value in a file or a source has a different type than the field,
+	// like "8080" string for an int field.
+	ExperimentStrictCoercion
+)
+
+// Has reports whether all the experiments in x are enabled.
+func (e Experiment) Has(x Experiment) bool {
+	return e&x == x
+}
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	timeType     = reflect.TypeOf(time.Time{})
+)
+
+// checkStrictType returns an error if the value from a file has a different type than the field.
+func checkStrictType(typ reflect.Type, value any) error {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if value == nil || typ == durationType || reflect.PtrTo(typ).Implements(textUnmarshalerType) {
+		return nil
+	}
+
+	var want string
+	kind := reflect.ValueOf(value).Kind()
+	switch typ.Kind() {
+	case reflect.Bool:
+		if kind != reflect.Bool {
+			want = "bool"
+		}
+	case reflect.String:
+		if kind != reflect.String {
+			want = "string"
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		if !isNumberKind(kind) {
+			want = "number"
+		}
+	}
+
+	if want != "" {
+		return fmt.Errorf("must be %s, got %T %v", want, value, value)
+	}
+	return nil
+}
+
+func isNumberKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
+
+// mergeMapValue adds entries of the old map which are missing in the new one,
+// nested maps are merged recursively. See ExperimentDeepMerge.
+func mergeMapValue(old, new reflect.Value) {
+	if old.Kind() != reflect.Map || old.IsNil() {
+		return
+	}
+	iter := old.MapRange()
+	for iter.Next() {
+		curr := new.MapIndex(iter.Key())
+		if !curr.IsValid() {
+			new.SetMapIndex(iter.Key(), iter.Value())
+			continue
+		}
+		mergeMapValue(elemValue(iter.Value()), elemValue(curr))
+	}
+}
+
+// elemValue returns the value inside of an interface.
+func elemValue(v reflect.Value) reflect.Value {
+	if v.Kind() == reflect.Interface {
+		return v.Elem()
+	}
+	return v
+}
+
+// typedFlag is a flag that checks values with the type of the field. See ExperimentTypedFlags.
+type typedFlag struct {
+	typ    reflect.Type
+	unit   string
+	layout string
+	value  string
+}
+
+var _ flag.Value = (*typedFlag)(nil)
+
+func newTypedFlag(field reflect.StructField, value string) *typedFlag {
+	typ := field.Type
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return &typedFlag{typ: typ, unit: field.Tag.Get("unit"), layout: field.Tag.Get("layout"), value: value}
+}
+
+func (f *typedFlag) String() string {
+	if f == nil {
+		return ""
+	}
+	return f.value
+}
+
+func (f *typedFlag) IsBoolFlag() bool {
+	return f.typ.Kind() == reflect.Bool
+}
+
+func (f *typedFlag) Set(value string) error {
+	if err := f.check(value); err != nil {
+		return err
+	}
+	f.value = value
+	return nil
+}
+
+func (f *typedFlag) check(value string) error {
+	if f.typ == durationType {
+		_, err := parseDuration(value, f.unit)
+		return err
+	}
+	if f.typ == timeType {
+		_, err := parseTime(value, f.layout)
+		return err
+	}
+	if _, ok := reflect.New(f.typ).Interface().(encoding.TextUnmarshaler); ok {
+		return nil
+	}
+
+	var err error
+	switch f.typ.Kind() {
+	case reflect.Bool:
+		_, err = strconv.ParseBool(value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		_, err = strconv.ParseInt(value, 0, f.typ.Bits())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		_, err = strconv.ParseUint(value, 0, f.typ.Bits())
+	case reflect.Float32, reflect.Float64:
+		_, err = strconv.ParseFloat(value, f.typ.Bits())
+	}
+	return err
+}
@@ -0,0 +1,110 @@
+package aconfig
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestExperimentDeepMerge(t *testing.T) {
+	type TestConfig struct {
+		Limits map[string]int `default:"a:1,b:2"`
+	}
+
+	f := func(exp Experiment, want map[string]int) {
+		t.Helper()
+
+		var cfg TestConfig
+		loader := LoaderFor(&cfg, Config{
+			NewParser:    newParser,
+			SkipEnv:      true,
+			SkipFlags:    true,
+			MergeFiles:   true,
+			Experimental: exp,
+			Files:        []string{"base.json", "prod.json"},
+			FileSystem: fstest.MapFS{
+				"base.json": {Data: []byte(`{"limits": {"b": 20, "c": 30}}`)},
+				"prod.json": {Data: []byte(`{"limits": {"c": 300}}`)},
+			},
+		})
+		failIfErr(t, loader.Load())
+		mustEqual(t, cfg.Limits, want)
+	}
+
+	// files are always merged, default is replaced.
+	f(0, map[string]int{"b": 20, "c": 300})
+	f(ExperimentDeepMerge, map[string]int{"a": 1, "b": 20, "c": 300})
+}
+
+func TestExperimentTypedFlags(t *testing.T) {
+	type TestConfig struct {
+		Debug   bool
+		Port    int           `default:"8080"`
+		Timeout time.Duration `default:"1s"`
+	}
+
+	f := func(exp Experiment, args []string) (TestConfig, error) {
+		t.Helper()
+
+		var cfg TestConfig
+		loader := LoaderFor(&cfg, Config{
+			NewParser:    newParser,
+			SkipFiles:    true,
+			SkipEnv:      true,
+			Experimental: exp,
+			Args:         args,
+		})
+		loader.Flags().SetOutput(&strings.Builder{})
+		return cfg, loader.Load()
+	}
+
+	// without the experiment bool flag takes the next argument as its value.
+	_, err := f(0, []string{"-debug", "-port=80"})
+	failIfOk(t, err)
+
+	cfg, err := f(ExperimentTypedFlags, []string{"-debug", "-port=80", "-timeout=5s"})
+	failIfErr(t, err)
+	mustEqual(t, cfg, TestConfig{Debug: true, Port: 80, Timeout: 5 * time.Second})
+
+	_, err = f(ExperimentTypedFlags, []string{"-port=eighty"})
+	failIfOk(t, err)
+	mustEqual(t, err.Error(), `load config: invalid value "eighty" for flag -port: strconv.ParseInt: parsing "eighty": invalid syntax`)
+}
+
+func TestExperimentStrictCoercion(t *testing.T) {
+	type TestConfig struct {
+		Port    int
+		Debug   bool
+		Name    string
+		Timeout time.Duration
+	}
+
+	f := func(exp Experiment, data string) error {
+		t.Helper()
+
+		loader := LoaderFor(&TestConfig{}, Config{
+			NewParser:    newParser,
+			SkipDefaults: true,
+			SkipEnv:      true,
+			SkipFlags:    true,
+			Experimental: exp,
+			Files:        []string{"config.json"},
+			FileSystem: fstest.MapFS{
+				"config.json": {Data: []byte(data)},
+			},
+		})
+		return loader.Load()
+	}
+
+	failIfErr(t, f(0, `{"port": "8080", "debug": "true", "name": 1}`))
+	failIfErr(t, f(ExperimentStrictCoercion, `{"port": 8080, "debug": true, "name": "app", "timeout": "1s"}`))
+
+	err := f(ExperimentStrictCoercion, `{"port": "8080"}`)
+	failIfOk(t, err)
+	mustEqual(t, strings.HasSuffix(err.Error(), "field Port: must be number, got string 8080"), true)
+
+	err = f(ExperimentStrictCoercion, `{"debug": "true"}`)
+	failIfOk(t, err)
+	mustEqual(t, strings.HasSuffix(err.Error(), "field Debug: must be bool, got string true"), true)
+}
@@ -0,0 +1,87 @@
+package aconfig
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// orderTag returns the value of `order` tag, fields without the tag have order 0.
+func orderTag(f Field) int {
+	sf, _ := fieldInfo(f)
+	order, _ := strconv.Atoi(sf.Tag.Get("order"))
+	return order
+}
+
+// sortByOrderTag sorts fields by `order` tag for WalkFields, help and docs.
+// Fields with the same order keep the declaration order.
+func sortByOrderTag[F Field](fields []F) {
+	sort.SliceStable(fields, func(i, j int) bool {
+		return orderTag(fields[i]) < orderTag(fields[j])
+	})
+}
+
+// checkOrderTags returns an error when `order` tag isn't a number.
+func (l *Loader) checkOrderTags() error {
+	for _, field := range l.allFields() {
+		sf, _ := fieldInfo(field)
+		tag, ok := sf.Tag.Lookup("order")
+		if !ok {
+			continue
+		}
+		if _, err := strconv.Atoi(tag); err != nil {
+			return fmt.Errorf("field %s: order tag must be a number, got %q", field.Name(), tag)
+		}
+	}
+	return nil
+}
+
+// orderedFlags returns flag names of the fields sorted by `order` tag.
+// Returns nil when no field has the tag, then help keeps flags in the lexical order.
+func (l *Loader) orderedFlags() []string {
+	fields := l.allFields()
+	tagged := false
+	for _, field := range fields {
+		sf, _ := fieldInfo(field)
+		if _, ok := sf.Tag.Lookup("order"); ok {
+			tagged = true
+			break
+		}
+	}
+	if !tagged {
+		return nil
+	}
+
+	sortByOrderTag(fields)
+	names := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if name := l.sourceName(field, "flag"); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// printFlagDefaults prints flags like flag.FlagSet.PrintDefaults but in the given order,
+// flags that aren't listed are printed after them in the lexical order.
+func printFlagDefaults(fs *flag.FlagSet, names []string) {
+	printed := map[string]bool{}
+	print := func(f *flag.Flag) {
+		if f == nil || printed[f.Name] {
+			return
+		}
+		printed[f.Name] = true
+
+		// a set with a single flag is printed the same way as in fs.
+		one := flag.NewFlagSet("", flag.ContinueOnError)
+		one.SetOutput(fs.Output())
+		one.Var(f.Value, f.Name, f.Usage)
+		one.Lookup(f.Name).DefValue = f.DefValue
+		one.PrintDefaults()
+	}
+	for _, name := range names {
+		print(fs.Lookup(name))
+	}
+	fs.VisitAll(print)
+}
@@ -0,0 +1,77 @@
+package aconfig
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestOrderTag(t *testing.T) {
+	type TestConfig struct {
+		Debug bool   `usage:"debug mode" order:"10"`
+		Port  int    `default:"8080" usage:"port to listen" order:"-1"`
+		Host  string `usage:"host to bind"`
+		Name  string `usage:"app name"`
+	}
+
+	loader := LoaderFor(&TestConfig{}, Config{
+		NewParser: newParser,
+		SkipFiles: true,
+		EnvPrefix: "APP",
+		Args:      []string{"-help"},
+	})
+
+	var names []string
+	loader.WalkFields(func(f Field) bool {
+		names = append(names, f.Name())
+		return true
+	})
+	mustEqual(t, names, []string{"Port", "Host", "Name", "Debug"})
+
+	var out strings.Builder
+	loader.Flags().Init("app", 0)
+	loader.Flags().SetOutput(&out)
+
+	err := loader.Load()
+	mustEqual(t, errors.Is(err, ErrHelp), true)
+
+	want := "Usage of app:\n" +
+		"  -port string\n" +
+		"    \tport to listen (default \"8080\")\n" +
+		"  -host string\n" +
+		"    \thost to bind\n" +
+		"  -name string\n" +
+		"    \tapp name\n" +
+		"  -debug string\n" +
+		"    \tdebug mode\n" +
+		"\n" +
+		"Environment variables:\n" +
+		"  APP_PORT   port to listen\n" +
+		"  APP_HOST   host to bind\n" +
+		"  APP_NAME   app name\n" +
+		"  APP_DEBUG  debug mode\n"
+	mustEqual(t, out.String(), want)
+
+	var buf bytes.Buffer
+	failIfErr(t, loader.GenerateDocs(&buf))
+	rows := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	mustEqual(t, len(rows), 6)
+	mustEqual(t, strings.HasPrefix(rows[2], "| `Port`"), true)
+	mustEqual(t, strings.HasPrefix(rows[5], "| `Debug`"), true)
+}
+
+func TestOrderTagInvalid(t *testing.T) {
+	type TestConfig struct {
+		Port int `order:"first"`
+	}
+
+	loader := LoaderFor(&TestConfig{}, Config{
+		NewParser: newParser,
+		SkipFiles: true,
+		Args:      []string{},
+	})
+	err := loader.Load()
+	failIfOk(t, err)
+	mustEqual(t, err.Error(), `init loader: field Port: order tag must be a number, got "first"`)
+}
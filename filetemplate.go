@@ -0,0 +1,51 @@
+package aconfig
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"text/template"
+)
+
+// renderFile executes the file as a text/template and returns a file system with the result.
+// See Config.FileTemplates.
+func (l *Loader) renderFile(fsys fs.FS, name string) (fs.FS, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+
+	envs := make(map[string]string, len(l.config.Envs))
+	for key, value := range getEnv(l.config.Envs) {
+		envs[key] = value.(string)
+	}
+	hostname, _ := os.Hostname()
+
+	funcs := template.FuncMap{
+		"env":      func(key string) string { return envs[key] },
+		"hostname": func() string { return hostname },
+	}
+	for key, fn := range l.config.TemplateFuncs {
+		funcs[key] = fn
+	}
+
+	tmpl, err := template.New(name).Funcs(funcs).Option("missingkey=error").Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("file %s: %w", name, err)
+	}
+
+	var tmplData any = map[string]any{
+		"Env":      envs,
+		"Hostname": hostname,
+	}
+	if l.config.TemplateData != nil {
+		tmplData = l.config.TemplateData
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, tmplData); err != nil {
+		return nil, fmt.Errorf("file %s: %w", name, err)
+	}
+	return memFS{name: buf.Bytes()}, nil
+}
@@ -0,0 +1,85 @@
+package aconfig
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"text/template"
+)
+
+func TestFileTemplates(t *testing.T) {
+	type TestConfig struct {
+		Name     string
+		Region   string
+		Host     string
+		Replicas int
+	}
+
+	fsys := fstest.MapFS{
+		"config.json": &fstest.MapFile{Data: []byte(`{
+			"name": "{{ upper (env "APP") }}",
+			"region": "{{ .Env.REGION }}",
+			"host": "{{ hostname }}",
+			{{ if eq .Env.REGION "eu" }}"replicas": 3{{ else }}"replicas": 1{{ end }}
+		}`)},
+		"missing.json": &fstest.MapFile{Data: []byte(`{"name": "{{ .Env.NOPE }}"}`)},
+	}
+
+	load := func(file string) (TestConfig, error) {
+		var cfg TestConfig
+		err := LoaderFor(&cfg, Config{
+			NewParser:     newParser,
+			SkipDefaults:  true,
+			SkipEnv:       true,
+			SkipFlags:     true,
+			FileTemplates: true,
+			TemplateFuncs: template.FuncMap{"upper": strings.ToUpper},
+			Files:         []string{file},
+			FileSystem:    fsys,
+			Envs:          []string{"APP=billing", "REGION=eu"},
+		}).Load()
+		return cfg, err
+	}
+
+	cfg, err := load("config.json")
+	failIfErr(t, err)
+
+	hostname, _ := os.Hostname()
+	want := TestConfig{
+		Name:     "BILLING",
+		Region:   "eu",
+		Host:     hostname,
+		Replicas: 3,
+	}
+	mustEqual(t, cfg, want)
+
+	_, err = load("missing.json")
+	failIfOk(t, err)
+}
+
+func TestFileTemplatesData(t *testing.T) {
+	type TestConfig struct {
+		Name string
+	}
+
+	var cfg TestConfig
+	loader := LoaderFor(&cfg, Config{
+		NewParser:     newParser,
+		SkipDefaults:  true,
+		SkipEnv:       true,
+		SkipFlags:     true,
+		FileTemplates: true,
+		RecordInputs:  true,
+		TemplateData:  struct{ Service string }{Service: "billing"},
+		Files:         []string{"config.json"},
+		FileSystem: fstest.MapFS{
+			"config.json": &fstest.MapFile{Data: []byte(`{"name": "{{ .Service }}"}`)},
+		},
+	})
+	failIfErr(t, loader.Load())
+	mustEqual(t, cfg.Name, "billing")
+
+	// inputs keep the template.
+	mustEqual(t, string(loader.Inputs().Files["config.json"]), `{"name": "{{ .Service }}"}`)
+}
@@ -0,0 +1,66 @@
+package aconfig
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// MergeFlagSets combines flags of several loaders into a single FlagSet with a single help output.
+// Useful when independent packages have their own loaders, usually with different flag prefixes.
+// Loaders use the merged FlagSet after this call, so it must be called before Load.
+// The first Load parses command-line args from its Config.Args.
+// Returns an error when a flag name is defined by more than one loader.
+func MergeFlagSets(name string, loaders ...*Loader) (*flag.FlagSet, error) {
+	merged := flag.NewFlagSet(name, flag.ContinueOnError)
+	owners := map[string]int{}
+
+	for i, l := range loaders {
+		if l.errInit != nil {
+			return nil, fmt.Errorf("loader %d: %w", i, l.errInit)
+		}
+
+		var err error
+		l.flagSet.VisitAll(func(f *flag.Flag) {
+			if err != nil {
+				return
+			}
+			if owner, ok := owners[f.Name]; ok {
+				err = fmt.Errorf("flag %q is defined by loaders %d and %d", f.Name, owner, i)
+				return
+			}
+			owners[f.Name] = i
+			merged.Var(f.Value, f.Name, f.Usage)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	merged.Usage = func() {
+		w := merged.Output()
+		name := merged.Name()
+		if name == "" {
+			name = filepath.Base(os.Args[0])
+		}
+		fmt.Fprintf(w, "Usage of %s:\n", name)
+		var names []string
+		for _, l := range loaders {
+			names = append(names, l.orderedFlags()...)
+		}
+		if names != nil {
+			printFlagDefaults(merged, names)
+		} else {
+			merged.PrintDefaults()
+		}
+		printEnvUsage(w, loaders...)
+	}
+
+	for _, l := range loaders {
+		l.stateMu.Lock()
+		l.flagSet = merged
+		l.stateMu.Unlock()
+	}
+	return merged, nil
+}
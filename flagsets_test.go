@@ -0,0 +1,93 @@
+package aconfig
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestMergeFlagSets(t *testing.T) {
+	type DBConfig struct {
+		Host string `default:"localhost" usage:"database host"`
+	}
+	type HTTPConfig struct {
+		Port int `default:"8080" usage:"port to listen"`
+	}
+
+	args := []string{"-db.host=db.local", "-http.port=80"}
+
+	var db DBConfig
+	dbLoader := LoaderFor(&db, Config{
+		NewParser:  newParser,
+		SkipFiles:  true,
+		SkipEnv:    true,
+		FlagPrefix: "db",
+		Args:       args,
+	})
+	var http HTTPConfig
+	httpLoader := LoaderFor(&http, Config{
+		NewParser:  newParser,
+		SkipFiles:  true,
+		EnvPrefix:  "HTTP",
+		FlagPrefix: "http",
+		Args:       args,
+	})
+
+	merged, err := MergeFlagSets("app", dbLoader, httpLoader)
+	failIfErr(t, err)
+
+	failIfErr(t, dbLoader.Load())
+	failIfErr(t, httpLoader.Load())
+	mustEqual(t, db.Host, "db.local")
+	mustEqual(t, http.Port, 80)
+	mustEqual(t, merged.Parsed(), true)
+
+	var out strings.Builder
+	merged.SetOutput(&out)
+	merged.Usage()
+
+	want := "Usage of app:\n" +
+		"  -db.host string\n" +
+		"    \tdatabase host (default \"localhost\")\n" +
+		"  -http.port string\n" +
+		"    \tport to listen (default \"8080\")\n" +
+		"\n" +
+		"Environment variables:\n" +
+		"  HTTP_PORT  port to listen\n"
+	mustEqual(t, out.String(), want)
+}
+
+func TestMergeFlagSetsHelp(t *testing.T) {
+	type TestConfig struct {
+		Port int `usage:"port to listen"`
+	}
+
+	loader := LoaderFor(&TestConfig{}, Config{
+		NewParser: newParser,
+		SkipFiles: true,
+		SkipEnv:   true,
+		Args:      []string{"-help"},
+	})
+	merged, err := MergeFlagSets("app", loader)
+	failIfErr(t, err)
+
+	var out strings.Builder
+	merged.SetOutput(&out)
+
+	err = loader.Load()
+	mustEqual(t, errors.Is(err, ErrHelp), true)
+	mustEqual(t, out.String(), "Usage of app:\n  -port string\n    \tport to listen\n")
+}
+
+func TestMergeFlagSetsCollision(t *testing.T) {
+	type TestConfig struct {
+		Port int
+	}
+
+	a := LoaderFor(&TestConfig{}, Config{NewParser: newParser, SkipFiles: true})
+	b := LoaderFor(&TestConfig{}, Config{NewParser: newParser, SkipFiles: true})
+
+	_, err := MergeFlagSets("app", a, b)
+	failIfOk(t, err)
+	mustEqual(t, err.Error(), `flag "port" is defined by loaders 0 and 1`)
+}
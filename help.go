@@ -0,0 +1,115 @@
+package aconfig
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+)
+
+// ErrHelp is returned by Load when -h, -help, -version or Config.PrintConfigFlag flag is passed.
+// Help, version or config is already printed to Loader.Flags().Output(), so the application can exit with code 0:
+//
+//	if err := loader.Load(); errors.Is(err, aconfig.ErrHelp) {
+//		os.Exit(0)
+//	}
+var ErrHelp = flag.ErrHelp
+
+const versionFlag = "version"
+
+// printUsage prints flags with their defaults and env vars of the fields.
+func (l *Loader) printUsage() {
+	w := l.flagSet.Output()
+
+	name := l.flagSet.Name()
+	if name == "" {
+		name = filepath.Base(os.Args[0])
+	}
+	if l.config.Version != "" {
+		name += " " + l.config.Version
+	}
+	fmt.Fprintf(w, "Usage of %s:\n", name)
+	if names := l.orderedFlags(); names != nil {
+		printFlagDefaults(l.flagSet, names)
+	} else {
+		l.flagSet.PrintDefaults()
+	}
+	printEnvUsage(w, l)
+}
+
+// printEnvUsage prints env vars of the fields of all the loaders with their usage.
+func printEnvUsage(w io.Writer, loaders ...*Loader) {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	header := false
+	for _, l := range loaders {
+		if l.config.SkipEnv {
+			continue
+		}
+		fields := l.allFields()
+		sortByOrderTag(fields)
+		for _, field := range fields {
+			env := l.sourceName(field, "env")
+			if env == "" {
+				continue
+			}
+			if !header {
+				fmt.Fprintln(w, "\nEnvironment variables:")
+				header = true
+			}
+			fmt.Fprintf(tw, "  %s\t%s\n", env, field.Tag("usage"))
+		}
+	}
+	tw.Flush()
+}
+
+// usageData is data for `usage` tag templates. See expandUsage.
+type usageData struct {
+	Name    string // Name is a field path like DB.User.
+	Default string // Default is the value of `default` tag.
+	Env     string // Env is the full env var name, empty if the field has no env var.
+	Flag    string // Flag is the full flag name without a dash, empty if the field has no flag.
+}
+
+// expandUsage executes usage as a template when it has "{{", like
+// `listen address (default {{.Default}}, env {{.Env}})`, so help and docs show actual names.
+func expandUsage(usage string, data usageData) (string, error) {
+	if !strings.Contains(usage, "{{") {
+		return usage, nil
+	}
+	tmpl, err := template.New("usage").Option("missingkey=error").Parse(usage)
+	if err != nil {
+		return "", fmt.Errorf("field %s: usage template: %w", data.Name, err)
+	}
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("field %s: usage template: %w", data.Name, err)
+	}
+	return sb.String(), nil
+}
+
+// expandUsages expands `usage` tag templates of the fields for the old parser.
+// The new parser does it on parse.
+func (l *Loader) expandUsages() error {
+	for _, field := range l.fields {
+		data := usageData{Name: field.name, Default: field.Tag("default")}
+		if !l.config.SkipEnv {
+			data.Env = l.fieldName(field, "env")
+		}
+		if !l.config.SkipFlags {
+			data.Flag = l.fieldName(field, "flag")
+		}
+		usage, err := expandUsage(field.Tag("usage"), data)
+		if err != nil {
+			return err
+		}
+		// fields are shared with reloads, so they are changed only on the first expansion.
+		if usage != field.Tag("usage") {
+			field.tags["usage"] = usage
+		}
+	}
+	return nil
+}
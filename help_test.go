@@ -0,0 +1,107 @@
+package aconfig
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestHelp(t *testing.T) {
+	type TestConfig struct {
+		Port int    `default:"8080" usage:"port to listen"`
+		Host string `usage:"host to bind"`
+	}
+
+	loader := LoaderFor(&TestConfig{}, Config{
+		NewParser: newParser,
+		SkipFiles: true,
+		EnvPrefix: "APP",
+		Version:   "v1.2.3",
+		Args:      []string{"-help"},
+	})
+	var out strings.Builder
+	loader.Flags().Init("app", 0)
+	loader.Flags().SetOutput(&out)
+
+	err := loader.Load()
+	failIfOk(t, err)
+	mustEqual(t, errors.Is(err, ErrHelp), true)
+
+	want := "Usage of app v1.2.3:\n" +
+		"  -host string\n" +
+		"    \thost to bind\n" +
+		"  -port string\n" +
+		"    \tport to listen (default \"8080\")\n" +
+		"  -version\n" +
+		"    \tprint version and exit\n" +
+		"\n" +
+		"Environment variables:\n" +
+		"  APP_PORT  port to listen\n" +
+		"  APP_HOST  host to bind\n"
+	mustEqual(t, out.String(), want)
+}
+
+func TestVersion(t *testing.T) {
+	type TestConfig struct {
+		Port int `default:"8080"`
+	}
+
+	loader := LoaderFor(&TestConfig{}, Config{
+		NewParser: newParser,
+		SkipFiles: true,
+		SkipEnv:   true,
+		Version:   "v1.2.3",
+		Args:      []string{"-version"},
+	})
+	var out strings.Builder
+	loader.Flags().SetOutput(&out)
+
+	err := loader.Load()
+	mustEqual(t, errors.Is(err, ErrHelp), true)
+	mustEqual(t, out.String(), "v1.2.3\n")
+
+	// without the flag the version isn't printed.
+	loader = LoaderFor(&TestConfig{}, Config{
+		NewParser: newParser,
+		SkipFiles: true,
+		SkipEnv:   true,
+		Version:   "v1.2.3",
+		Args:      []string{"-port=80"},
+	})
+	failIfErr(t, loader.Load())
+}
+
+func TestUsageTemplate(t *testing.T) {
+	type TestConfig struct {
+		DB struct {
+			Addr string `default:"localhost:5432" usage:"database address (default {{.Default}}, env {{.Env}}, flag -{{.Flag}})"`
+		}
+		Secret string `flag:"-" usage:"{{.Name}} is read from {{.Env}}{{if not .Flag}} only{{end}}"`
+	}
+
+	loader := LoaderFor(&TestConfig{}, Config{
+		NewParser:  newParser,
+		SkipFiles:  true,
+		EnvPrefix:  "APP",
+		FlagPrefix: "app",
+	})
+
+	usages := map[string]string{}
+	loader.WalkFields(func(f Field) bool {
+		usages[f.Name()] = f.Tag("usage")
+		return true
+	})
+	mustEqual(t, usages, map[string]string{
+		"DB.Addr": "database address (default localhost:5432, env APP_DB_ADDR, flag -app.db.addr)",
+		"Secret":  "Secret is read from APP_SECRET only",
+	})
+	mustEqual(t, loader.Flags().Lookup("app.db.addr").Usage, usages["DB.Addr"])
+
+	err := LoaderFor(&struct {
+		Port int `usage:"port {{.Nope}}"`
+	}{}, Config{NewParser: newParser, SkipFlags: true}).Load()
+	failIfOk(t, err)
+	if !strings.Contains(err.Error(), "field Port: usage template") {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,201 @@
+package aconfig
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+// HTTPFiles configures loading of files with http:// and https:// URLs in Config.Files.
+type HTTPFiles struct {
+	// Client for requests. When set Timeout and TLSConfig are ignored.
+	Client *http.Client
+
+	// Headers are added to every request, like Authorization.
+	Headers http.Header
+
+	// Timeout of a request. Default is 10 seconds.
+	Timeout time.Duration
+
+	// TLSConfig for https URLs. Default is nil (system settings).
+	TLSConfig *tls.Config
+}
+
+// contentTypes maps well-known media types to file extensions, so FileDecoders are used for them.
+// See Config.MIMEDecoders.
+var contentTypes = map[string]string{
+	"application/json":   ".json",
+	"application/yaml":   ".yaml",
+	"application/x-yaml": ".yaml",
+	"text/yaml":          ".yaml",
+	"text/x-yaml":        ".yaml",
+	"application/toml":   ".toml",
+}
+
+// FileFetcher downloads config files with URLs of other schemes, like s3://bucket/key.
+// See Config.FileFetchers.
+type FileFetcher interface {
+	// Fetch returns the file content. Error for a missing file must wrap fs.ErrNotExist.
+	Fetch(ctx context.Context, url string) ([]byte, error)
+}
+
+// ContentTypeFetcher is an optional interface for FileFetcher that returns a MIME type of the file
+// with its content, like Content-Type of an HTTP or gRPC response. See Config.MIMEDecoders.
+type ContentTypeFetcher interface {
+	FetchWithType(ctx context.Context, url string) (data []byte, contentType string, err error)
+}
+
+// urlScheme returns scheme of the URL like "https" or empty string if the path isn't a URL.
+func urlScheme(path string) string {
+	scheme, _, ok := strings.Cut(path, "://")
+	if !ok || strings.ContainsAny(scheme, "/\\.") {
+		return ""
+	}
+	return scheme
+}
+
+// urlFS is a file system where file names are URLs, every Open makes a request.
+type urlFS struct {
+	client   *http.Client
+	headers  http.Header
+	fetchers map[string]FileFetcher
+	decoders map[string]FileDecoder
+	maxSize  int64
+}
+
+// isURL reports whether the path is a URL that can be fetched.
+func (u *urlFS) isURL(path string) bool {
+	switch scheme := urlScheme(path); scheme {
+	case "http", "https":
+		return true
+	default:
+		_, ok := u.fetchers[scheme]
+		return ok
+	}
+}
+
+func newURLFS(cfg HTTPFiles, fetchers map[string]FileFetcher, decoders map[string]FileDecoder) *urlFS {
+	client := cfg.Client
+	if client == nil {
+		timeout := cfg.Timeout
+		if timeout == 0 {
+			timeout = 10 * time.Second
+		}
+		client = &http.Client{Timeout: timeout}
+		if cfg.TLSConfig != nil {
+			transport := http.DefaultTransport.(*http.Transport).Clone()
+			transport.TLSClientConfig = cfg.TLSConfig
+			client.Transport = transport
+		}
+	}
+	return &urlFS{
+		client:   client,
+		headers:  cfg.Headers,
+		fetchers: fetchers,
+		decoders: decoders,
+	}
+}
+
+func (u *urlFS) Open(name string) (fs.File, error) {
+	file, err := u.fetch(context.Background(), name)
+	if err != nil {
+		return nil, err
+	}
+	return file.Open(name)
+}
+
+// fetch downloads the file, 404 is reported as fs.ErrNotExist.
+func (u *urlFS) fetch(ctx context.Context, name string) (*urlFile, error) {
+	if fetcher, ok := u.fetchers[urlScheme(name)]; ok {
+		var data []byte
+		var contentType string
+		var err error
+		if f, ok := fetcher.(ContentTypeFetcher); ok {
+			data, contentType, err = f.FetchWithType(ctx, name)
+		} else {
+			data, err = fetcher.Fetch(ctx, name)
+		}
+		if err != nil {
+			return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+		}
+		return &urlFile{memFS: memFS{name: data}, ext: u.ext(name), contentType: contentType}, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, name, nil)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	for key, values := range u.headers {
+		req.Header[key] = values
+	}
+
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	case resp.StatusCode != http.StatusOK:
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fmt.Errorf("unexpected status %s", resp.Status)}
+	}
+
+	body := io.Reader(resp.Body)
+	if u.maxSize > 0 {
+		// one byte more to let the loader see that the file is too large.
+		body = io.LimitReader(body, u.maxSize+1)
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: err}
+	}
+	return &urlFile{
+		memFS:       memFS{name: data},
+		ext:         u.ext(name),
+		contentType: resp.Header.Get("Content-Type"),
+	}, nil
+}
+
+// ext returns extension from URL path if there is a decoder for it.
+func (u *urlFS) ext(name string) string {
+	parsed, err := url.Parse(name)
+	if err != nil {
+		return ""
+	}
+	ext := strings.ToLower(path.Ext(parsed.Path))
+	if _, ok := u.decoders[ext]; ok {
+		return ext
+	}
+	return ""
+}
+
+// mimeDecoder returns a decoder for the content type from Config.MIMEDecoders
+// or from Config.FileDecoders for a well-known media type.
+func (l *Loader) mimeDecoder(contentType string) (FileDecoder, bool) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, false
+	}
+	if dec, ok := l.config.MIMEDecoders[mediaType]; ok {
+		return dec, true
+	}
+	dec, ok := l.config.FileDecoders[contentTypes[mediaType]]
+	return dec, ok
+}
+
+// urlFile is a downloaded file with the extension and the content type for its format.
+type urlFile struct {
+	memFS
+	ext         string
+	contentType string
+}
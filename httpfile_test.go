@@ -0,0 +1,227 @@
+package aconfig
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHTTPFiles(t *testing.T) {
+	type TestConfig struct {
+		Str  string
+		Port int
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/config.json":
+			fmt.Fprint(w, `{"str": "from-url", "port": 8080}`)
+		case "/config":
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			fmt.Fprint(w, `{"port": 9090}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	f := func(files []string, want TestConfig) {
+		t.Helper()
+
+		var cfg TestConfig
+		loader := LoaderFor(&cfg, Config{
+			NewParser:    newParser,
+			SkipDefaults: true,
+			SkipEnv:      true,
+			SkipFlags:    true,
+			MergeFiles:   true,
+			Files:        files,
+			HTTPFiles: HTTPFiles{
+				Headers: http.Header{"Authorization": {"Bearer token"}},
+			},
+		})
+		failIfErr(t, loader.Load())
+		mustEqual(t, cfg, want)
+		mustEqual(t, loader.Explain("Port"), fmt.Sprintf("Port: file %s (key port)", files[len(files)-1]))
+	}
+
+	f([]string{srv.URL + "/config.json"}, TestConfig{Str: "from-url", Port: 8080})
+	f([]string{srv.URL + "/config.json", srv.URL + "/config"}, TestConfig{Str: "from-url", Port: 9090})
+
+	loader := LoaderFor(&TestConfig{}, Config{
+		NewParser:    newParser,
+		SkipDefaults: true,
+		SkipEnv:      true,
+		SkipFlags:    true,
+		Files:        []string{srv.URL + "/missing.json"},
+		HTTPFiles: HTTPFiles{
+			Headers: http.Header{"Authorization": {"Bearer token"}},
+		},
+	})
+	failIfErr(t, loader.Load())
+	mustEqual(t, loader.MissingFiles(), []string{srv.URL + "/missing.json"})
+
+	// without the header.
+	loader = LoaderFor(&TestConfig{}, Config{
+		NewParser:    newParser,
+		SkipDefaults: true,
+		SkipEnv:      true,
+		SkipFlags:    true,
+		Files:        []string{srv.URL + "/config.json"},
+	})
+	err := loader.Load()
+	failIfOk(t, err)
+	mustEqual(t, err.Error(), "load config: load files: open "+srv.URL+"/config.json: unexpected status 401 Unauthorized")
+}
+
+func TestHTTPFilesTLS(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"str": "secure"}`)
+	}))
+	defer srv.Close()
+
+	pool := x509.NewCertPool()
+	pool.AddCert(srv.Certificate())
+
+	var cfg TestConfig
+	loader := LoaderFor(&cfg, Config{
+		NewParser:    newParser,
+		SkipDefaults: true,
+		SkipEnv:      true,
+		SkipFlags:    true,
+		Files:        []string{srv.URL + "/config.json"},
+		HTTPFiles: HTTPFiles{
+			TLSConfig: &tls.Config{RootCAs: pool},
+		},
+	})
+	failIfErr(t, loader.Load())
+	mustEqual(t, cfg.Str, "secure")
+}
+
+type mapFetcher map[string]string
+
+func (f mapFetcher) Fetch(ctx context.Context, url string) ([]byte, error) {
+	data, ok := f[url]
+	if !ok {
+		return nil, fs.ErrNotExist
+	}
+	return []byte(data), nil
+}
+
+func TestFileFetchers(t *testing.T) {
+	var cfg TestConfig
+	loader := LoaderFor(&cfg, Config{
+		NewParser:    newParser,
+		SkipDefaults: true,
+		SkipEnv:      true,
+		SkipFlags:    true,
+		MergeFiles:   true,
+		Files:        []string{"s3://bucket/config.json", "s3://bucket/missing.json"},
+		FileFetchers: map[string]FileFetcher{
+			"s3": mapFetcher{"s3://bucket/config.json": `{"str": "from-s3"}`},
+		},
+	})
+	failIfErr(t, loader.Load())
+	mustEqual(t, cfg.Str, "from-s3")
+	mustEqual(t, loader.LoadedFiles(), []string{"s3://bucket/config.json"})
+	mustEqual(t, loader.MissingFiles(), []string{"s3://bucket/missing.json"})
+}
+
+func TestMIMEDecoders(t *testing.T) {
+	type TestConfig struct {
+		Str  string
+		Port int
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/x-lines; charset=utf-8")
+		fmt.Fprint(w, "str=from-url\nport=8080")
+	}))
+	defer srv.Close()
+
+	load := func(file string, fetchers map[string]FileFetcher) (TestConfig, error) {
+		var cfg TestConfig
+		err := LoaderFor(&cfg, Config{
+			NewParser:    newParser,
+			SkipDefaults: true,
+			SkipEnv:      true,
+			SkipFlags:    true,
+			Files:        []string{file},
+			FileFetchers: fetchers,
+			MIMEDecoders: map[string]FileDecoder{"text/x-lines": lineDecoder{}},
+		}).Load()
+		return cfg, err
+	}
+
+	cfg, err := load(srv.URL+"/config", nil)
+	failIfErr(t, err)
+	mustEqual(t, cfg, TestConfig{Str: "from-url", Port: 8080})
+
+	fetchers := map[string]FileFetcher{
+		"grpc": typedFetcher{
+			"grpc://config/app":  {"text/x-lines", "str=from-grpc"},
+			"grpc://config/json": {"application/json", `{"port": 9090}`},
+			"grpc://config/bin":  {"application/octet-stream", "???"},
+		},
+	}
+	cfg, err = load("grpc://config/app", fetchers)
+	failIfErr(t, err)
+	mustEqual(t, cfg.Str, "from-grpc")
+
+	cfg, err = load("grpc://config/json", fetchers)
+	failIfErr(t, err)
+	mustEqual(t, cfg.Port, 9090)
+
+	_, err = load("grpc://config/bin", fetchers)
+	failIfOk(t, err)
+}
+
+type typedFetcher map[string][2]string
+
+func (f typedFetcher) Fetch(ctx context.Context, url string) ([]byte, error) {
+	return nil, errors.New("Fetch must not be called")
+}
+
+func (f typedFetcher) FetchWithType(ctx context.Context, url string) ([]byte, string, error) {
+	file, ok := f[url]
+	if !ok {
+		return nil, "", fs.ErrNotExist
+	}
+	return []byte(file[1]), file[0], nil
+}
+
+func TestHTTPFilesContext(t *testing.T) {
+	done := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-done:
+		}
+	}))
+	defer srv.Close()
+	defer close(done)
+
+	loader := LoaderFor(&struct{ Port int }{}, Config{
+		NewParser: newParser,
+		SkipFlags: true,
+		Files:     []string{srv.URL + "/config.json"},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := loader.LoadContext(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v, want deadline exceeded", err)
+	}
+}
@@ -0,0 +1,353 @@
+package aconfig
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Inputs are the raw inputs consumed by the loader: file contents, environment variables and flags.
+// Can be saved into a bundle to reproduce exactly the same configuration later.
+type Inputs struct {
+	Files  map[string][]byte `json:"files"`            // Files contents by a file path.
+	Hashes map[string]string `json:"hashes,omitempty"` // Hashes of files without Config.RecordInputs or when secrets in a file can't be redacted.
+	Envs   []string          `json:"envs"`             // Envs related to the configuration in "KEY=value" form.
+	Args   []string          `json:"args"`             // Args are the flags set by the user in "-name=value" form.
+}
+
+// Inputs returns the raw inputs consumed by the last Load.
+// File contents are kept only with Config.RecordInputs, otherwise Inputs have only hashes of the files.
+//
+// Values of fields with `secret:"true"` tag and env vars with names like *_PASSWORD or *_TOKEN
+// are redacted in envs and args. Files with values of secret fields are encoded again
+// with the values redacted when the format supports encoding (see FileEncoder),
+// otherwise only their hashes are kept. A replayed configuration has placeholders instead of secrets.
+func (l *Loader) Inputs() Inputs {
+	l.stateMu.RLock()
+	defer l.stateMu.RUnlock()
+
+	secrets := l.secretNames()
+	in := Inputs{
+		Files:  make(map[string][]byte, len(l.inputs.Files)),
+		Hashes: make(map[string]string, len(l.inputs.Hashes)),
+	}
+	for file, hash := range l.inputs.Hashes {
+		in.Hashes[file] = hash
+	}
+	for file, data := range l.inputs.Files {
+		data, ok := l.redactFile(file, data)
+		if !ok {
+			sum := sha256.Sum256(l.inputs.Files[file])
+			in.Hashes[file] = hex.EncodeToString(sum[:])
+			continue
+		}
+		in.Files[file] = data
+	}
+	for _, env := range l.inputs.Envs {
+		name, _, _ := cut(env, "=")
+		if secrets[name] || isSecretEnv(name) {
+			env = name + "=" + redacted
+		}
+		in.Envs = append(in.Envs, env)
+	}
+	for _, arg := range l.inputs.Args {
+		in.Args = append(in.Args, l.redactArg(arg, secrets))
+	}
+	return in
+}
+
+// Fingerprint returns a hash of all the raw inputs consumed by the last Load.
+// Equal fingerprints mean that the configuration was loaded from the same inputs.
+// Unlike Inputs secrets aren't redacted, so changed secrets change the fingerprint.
+func (l *Loader) Fingerprint() string {
+	l.stateMu.RLock()
+	defer l.stateMu.RUnlock()
+	return l.inputs.Fingerprint()
+}
+
+// secretEnvWords are parts of env var names that usually hold secrets.
+var secretEnvWords = []string{"PASSWORD", "PASSWD", "SECRET", "TOKEN", "CREDENTIAL", "PRIVATE_KEY", "API_KEY"}
+
+func isSecretEnv(name string) bool {
+	upper := strings.ToUpper(name)
+	for _, word := range secretEnvWords {
+		if strings.Contains(upper, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// secretNames returns env vars, flags and keys for Config.SetFlag of fields with `secret:"true"` tag.
+func (l *Loader) secretNames() map[string]bool {
+	names := map[string]bool{}
+	for _, field := range l.allFields() {
+		if !isSecret(field) {
+			continue
+		}
+		if env := l.sourceName(field, "env"); env != "" {
+			names[env] = true
+		}
+		if flag := l.sourceName(field, "flag"); flag != "" {
+			names["-"+flag] = true
+		}
+		names[strings.ToLower(field.Name())] = true
+		names[l.setKey(field.Name())] = true
+	}
+	return names
+}
+
+// redactArg redacts the value of a flag of a secret field, or values of secret fields in Config.SetFlag.
+func (l *Loader) redactArg(arg string, secrets map[string]bool) string {
+	name, value, _ := cut(arg, "=")
+	if secrets[name] {
+		return name + "=" + redacted
+	}
+	if l.config.SetFlag == "" || name != "-"+l.config.SetFlag {
+		return arg
+	}
+
+	entries := strings.Split(value, " ")
+	for i, entry := range entries {
+		key, _, _ := cut(entry, "=")
+		if secrets[strings.ToLower(key)] {
+			entries[i] = key + "=" + redacted
+		}
+	}
+	return name + "=" + strings.Join(entries, " ")
+}
+
+// redactFile returns the file with values of secret fields redacted.
+// Reports false when the file has secrets but can't be encoded again.
+func (l *Loader) redactFile(file string, data []byte) ([]byte, bool) {
+	format := l.fileFormat(file)
+	var keys [][]string
+	for _, field := range l.allFields() {
+		if !isSecret(field) {
+			continue
+		}
+		if name := l.sourceName(field, format); name != "" {
+			keys = append(keys, strings.Split(name, "."))
+		}
+	}
+	if len(keys) == 0 {
+		return data, true
+	}
+
+	dec, ok := l.config.FileDecoders[strings.ToLower(filepath.Ext(file))]
+	if !ok {
+		return nil, false
+	}
+	enc, ok := dec.(FileEncoder)
+	if !ok {
+		return nil, false
+	}
+	values, err := decodeWith(dec, memFS{file: data}, file)
+	if err != nil {
+		return nil, false
+	}
+
+	found := false
+	for _, key := range keys {
+		if redactNested(values, key) {
+			found = true
+		}
+	}
+	if !found {
+		return data, true
+	}
+
+	var buf bytes.Buffer
+	if err := enc.Encode(&buf, values); err != nil {
+		return nil, false
+	}
+	return buf.Bytes(), true
+}
+
+// redactNested replaces the value by the path of keys, keys are matched case-insensitively.
+func redactNested(m map[string]any, keys []string) bool {
+	found := false
+	for k, v := range m {
+		if !strings.EqualFold(k, keys[0]) {
+			continue
+		}
+		if len(keys) == 1 {
+			m[k] = redacted
+			found = true
+			continue
+		}
+		if sub, ok := asMap(v); ok && redactNested(sub, keys[1:]) {
+			m[k] = sub
+			found = true
+		}
+	}
+	return found
+}
+
+// Fingerprint returns a SHA-256 hash of the inputs in hex.
+func (in Inputs) Fingerprint() string {
+	h := sha256.New()
+
+	// files are hashed by contents, so it doesn't matter if contents or only hashes were kept.
+	hashes := make(map[string]string, len(in.Files)+len(in.Hashes))
+	for file, hash := range in.Hashes {
+		hashes[file] = hash
+	}
+	for file, data := range in.Files {
+		sum := sha256.Sum256(data)
+		hashes[file] = hex.EncodeToString(sum[:])
+	}
+	files := make([]string, 0, len(hashes))
+	for file := range hashes {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	for _, file := range files {
+		fmt.Fprintf(h, "file:%q:%s\n", file, hashes[file])
+	}
+	for _, env := range sortedCopy(in.Envs) {
+		fmt.Fprintf(h, "env:%q\n", env)
+	}
+	for _, arg := range sortedCopy(in.Args) {
+		fmt.Fprintf(h, "arg:%q\n", arg)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// WriteTo writes inputs as a JSON bundle, suitable for a bug report.
+func (in Inputs) WriteTo(w io.Writer) (int64, error) {
+	data, err := json.MarshalIndent(in, "", "\t")
+	if err != nil {
+		return 0, err
+	}
+	n, err := w.Write(data)
+	return int64(n), err
+}
+
+// ReadInputs reads a bundle written by Inputs.WriteTo.
+func ReadInputs(r io.Reader) (Inputs, error) {
+	var in Inputs
+	if err := json.NewDecoder(r).Decode(&in); err != nil {
+		return Inputs{}, fmt.Errorf("decode inputs: %w", err)
+	}
+	return in, nil
+}
+
+// Config returns a copy of the given config that reads files, envs and args from the inputs.
+// Only Config.FileSystem is replaced, so HTTP files, stdin, Config.FileEntries with their own
+// file system, Sources, FieldSources and values of Loader.AddSource are read from their origin again.
+func (in Inputs) Config(cfg Config) Config {
+	cfg.FileSystem = memFS(in.Files)
+	cfg.Envs = append([]string{}, in.Envs...)
+	cfg.Args = append([]string{}, in.Args...)
+	return cfg
+}
+
+func (l *Loader) recordInputs() {
+	if !l.config.SkipEnv {
+		for _, env := range l.config.Envs {
+			name, _, _ := cut(env, "=")
+			// the index has env names of both parsers, see indexEnvs.
+			if _, ok := l.envIndex[name]; ok ||
+				(l.config.EnvPrefix != "" && strings.HasPrefix(name, l.config.EnvPrefix)) {
+				l.inputs.Envs = append(l.inputs.Envs, env)
+			}
+		}
+	}
+
+	if !l.config.SkipFlags {
+		for name, value := range getFlags(l.flagSet) {
+			l.inputs.Args = append(l.inputs.Args, "-"+name+"="+fmt.Sprint(value))
+		}
+		sort.Strings(l.inputs.Args)
+	}
+}
+
+// teeFS writes everything read from the file name into w and raw when it's not nil.
+type teeFS struct {
+	fs.FS
+	name string
+	w    io.Writer
+	raw  *bytes.Buffer
+}
+
+func (t *teeFS) Open(name string) (fs.File, error) {
+	f, err := t.FS.Open(name)
+	if err != nil || name != t.name {
+		return f, err
+	}
+	w := t.w
+	if t.raw != nil {
+		t.raw.Reset()
+		w = io.MultiWriter(t.w, t.raw)
+	}
+	return &teeFile{File: f, w: w}, nil
+}
+
+type teeFile struct {
+	fs.File
+	w io.Writer
+}
+
+func (f *teeFile) Read(p []byte) (int, error) {
+	n, err := f.File.Read(p)
+	if n > 0 {
+		f.w.Write(p[:n])
+	}
+	return n, err
+}
+
+// Close reads the rest of the file, decoders might stop after the value without reading to EOF.
+func (f *teeFile) Close() error {
+	io.Copy(f.w, f.File)
+	return f.File.Close()
+}
+
+func sortedCopy(ss []string) []string {
+	res := append([]string{}, ss...)
+	sort.Strings(res)
+	return res
+}
+
+var _ fs.FS = memFS{}
+
+// memFS is an in-memory file system. Unlike fstest.MapFS accepts any file names.
+type memFS map[string][]byte
+
+func (m memFS) Open(name string) (fs.File, error) {
+	data, ok := m[name]
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return &memFile{
+		Reader: bytes.NewReader(data),
+		name:   path.Base(name),
+		size:   int64(len(data)),
+	}, nil
+}
+
+type memFile struct {
+	*bytes.Reader
+	name string
+	size int64
+}
+
+func (f *memFile) Stat() (fs.FileInfo, error) { return f, nil }
+func (f *memFile) Close() error               { return nil }
+
+func (f *memFile) Name() string       { return f.name }
+func (f *memFile) Size() int64        { return f.size }
+func (f *memFile) Mode() fs.FileMode  { return 0o444 }
+func (f *memFile) ModTime() time.Time { return time.Time{} }
+func (f *memFile) IsDir() bool        { return false }
+func (f *memFile) Sys() interface{}   { return nil }
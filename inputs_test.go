@@ -0,0 +1,149 @@
+package aconfig
+
+import (
+	"bytes"
+	"testing"
+	"testing/fstest"
+)
+
+func TestInputsReproduce(t *testing.T) {
+	type TestConfig struct {
+		Str  string
+		Port int
+		Sub  struct {
+			Float float64
+		}
+	}
+
+	cfg := Config{
+		EnvPrefix:    "APP",
+		RecordInputs: true,
+		Files:        []string{"config.json"},
+		FileSystem: fstest.MapFS{
+			"config.json": &fstest.MapFile{Data: []byte(`{"str": "file", "sub": {"float": 1.5}}`)},
+		},
+		Envs: []string{"APP_PORT=8080", "HOME=/root"},
+		Args: []string{"-str=flag"},
+	}
+
+	var first TestConfig
+	loader := LoaderFor(&first, cfg)
+	failIfErr(t, loader.Load())
+
+	in := loader.Inputs()
+	mustEqual(t, in.Envs, []string{"APP_PORT=8080"})
+	mustEqual(t, in.Args, []string{"-str=flag"})
+
+	var buf bytes.Buffer
+	_, err := in.WriteTo(&buf)
+	failIfErr(t, err)
+
+	restored, err := ReadInputs(&buf)
+	failIfErr(t, err)
+	mustEqual(t, restored.Fingerprint(), loader.Fingerprint())
+
+	var second TestConfig
+	loader2 := LoaderFor(&second, restored.Config(Config{
+		EnvPrefix: "APP",
+		Files:     []string{"config.json"},
+	}))
+	failIfErr(t, loader2.Load())
+
+	mustEqual(t, second, first)
+	mustEqual(t, loader2.Fingerprint(), loader.Fingerprint())
+}
+
+func TestFingerprintChanges(t *testing.T) {
+	type TestConfig struct {
+		Port int
+	}
+
+	for _, newParser := range []bool{false, true} {
+		load := func(envs ...string) *Loader {
+			loader := LoaderFor(&TestConfig{}, Config{
+				NewParser: newParser,
+				SkipFiles: true,
+				SkipFlags: true,
+				Envs:      envs,
+			})
+			failIfErr(t, loader.Load())
+			return loader
+		}
+		fingerprint := func(envs ...string) string {
+			return load(envs...).Fingerprint()
+		}
+
+		if fingerprint("PORT=1") == fingerprint("PORT=2") {
+			t.Fatalf("fingerprints must differ, new parser: %v", newParser)
+		}
+		mustEqual(t, fingerprint("PORT=1", "OTHER=1"), fingerprint("OTHER=2", "PORT=1"))
+		mustEqual(t, load("PORT=1", "OTHER=1").Inputs().Envs, []string{"PORT=1"})
+	}
+}
+
+func TestInputsRedactSecrets(t *testing.T) {
+	type TestConfig struct {
+		User     string
+		Password string `secret:"true"`
+		Token    string `secret:"true"`
+		DB       struct {
+			Pass string `secret:"true"`
+		}
+	}
+
+	var cfg TestConfig
+	loader := LoaderFor(&cfg, Config{
+		EnvPrefix:    "APP",
+		RecordInputs: true,
+		Files:        []string{"config.json"},
+		FileSystem: fstest.MapFS{
+			"config.json": &fstest.MapFile{Data: []byte(`{"user": "admin", "db": {"pass": "file-secret"}}`)},
+		},
+		Envs:    []string{"APP_PASSWORD=env-secret", "APP_USER=root"},
+		Args:    []string{"-token=flag-secret", "-set=user=admin", "-set=db.pass=set-secret"},
+		SetFlag: "set",
+	})
+	failIfErr(t, loader.Load())
+
+	in := loader.Inputs()
+	mustEqual(t, in.Envs, []string{"APP_PASSWORD=<redacted>", "APP_USER=root"})
+	mustEqual(t, in.Args, []string{"-set=user=admin db.pass=<redacted>", "-token=<redacted>"})
+
+	var buf bytes.Buffer
+	_, err := in.WriteTo(&buf)
+	failIfErr(t, err)
+	for _, secret := range []string{"env-secret", "file-secret", "flag-secret", "set-secret"} {
+		if bytes.Contains(buf.Bytes(), []byte(secret)) {
+			t.Fatalf("secret %q in inputs: %s", secret, buf.String())
+		}
+	}
+
+	// the loader state isn't changed.
+	mustEqual(t, string(loader.inputs.Files["config.json"]), `{"user": "admin", "db": {"pass": "file-secret"}}`)
+}
+
+func TestInputsRecordFiles(t *testing.T) {
+	type TestConfig struct {
+		Port int
+	}
+
+	load := func(record bool) *Loader {
+		loader := LoaderFor(&TestConfig{}, Config{
+			SkipFlags:    true,
+			RecordInputs: record,
+			Files:        []string{"config.json"},
+			FileSystem: fstest.MapFS{
+				"config.json": &fstest.MapFile{Data: []byte(`{"port": 80}` + "\n")},
+			},
+			Envs: []string{},
+		})
+		failIfErr(t, loader.Load())
+		return loader
+	}
+
+	hashed, recorded := load(false), load(true)
+	mustEqual(t, len(hashed.Inputs().Files), 0)
+	mustEqual(t, len(hashed.Inputs().Hashes["config.json"]), 64)
+	mustEqual(t, string(recorded.Inputs().Files["config.json"]), `{"port": 80}`+"\n")
+	mustEqual(t, hashed.Fingerprint(), recorded.Fingerprint())
+}
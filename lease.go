@@ -0,0 +1,179 @@
+package aconfig
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// Lease is a value with a limited lifetime, like Vault lease or STS credentials.
+// FieldSource.LoadField can return a Lease, then the field gets Value
+// and Loader.Watch loads the field again before TTL passes.
+type Lease struct {
+	Value any
+	TTL   time.Duration
+}
+
+// leasedField is a field loaded from a FieldSource with a Lease.
+type leasedField struct {
+	field     string
+	src       FieldSource
+	name      string // name of the source.
+	ref       string
+	refreshAt time.Time
+}
+
+// leaseRefreshAt returns when a lease with the TTL must be refreshed: after 2/3 of TTL.
+func leaseRefreshAt(now time.Time, ttl time.Duration) time.Time {
+	return now.Add(ttl - ttl/3)
+}
+
+// unwrapLease returns the value of a Lease and records the field for refresh.
+// Other values are returned as is.
+func (l *Loader) unwrapLease(value any, field Field, src FieldSource, name, ref string) any {
+	lease, ok := value.(Lease)
+	if !ok {
+		return value
+	}
+	if lease.TTL > 0 {
+		l.leases = append(l.leases, leasedField{
+			field:     field.Name(),
+			src:       src,
+			name:      name,
+			ref:       ref,
+			refreshAt: leaseRefreshAt(time.Now(), lease.TTL),
+		})
+	}
+	return lease.Value
+}
+
+// leasesDue reports whether any lease must be refreshed.
+func (l *Loader) leasesDue(now time.Time) bool {
+	l.stateMu.RLock()
+	defer l.stateMu.RUnlock()
+
+	for _, lease := range l.leases {
+		if !now.Before(lease.refreshAt) {
+			return true
+		}
+	}
+	return false
+}
+
+// refreshLeases loads fields with due leases again and copies new values into the destination.
+// Other fields are left untouched. On error the destination isn't changed.
+func (l *Loader) refreshLeases(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// leases are reset by Load, so they are guarded by stateMu like other results of the load.
+	l.stateMu.RLock()
+	fields := map[string]Field{}
+	for _, field := range l.allFields() {
+		fields[field.Name()] = field
+	}
+	leases := append([]leasedField{}, l.leases...)
+	l.stateMu.RUnlock()
+
+	dst := reflect.ValueOf(l.dst).Elem()
+	fresh := reflect.New(dst.Type()).Elem()
+	fresh.Set(dst)
+
+	now := time.Now()
+	for i, lease := range leases {
+		if now.Before(lease.refreshAt) {
+			continue
+		}
+
+		value, err := lease.src.LoadField(ctx, lease.ref)
+		if err != nil {
+			return fmt.Errorf("source %s: field %s: %w", lease.name, lease.field, err)
+		}
+		ttl := time.Duration(0)
+		if v, ok := value.(Lease); ok {
+			value, ttl = v.Value, v.TTL
+		}
+		if l.config.Decrypt != nil {
+			if value, err = l.decryptValue(lease.ref, value); err != nil {
+				return fmt.Errorf("source %s: field %s: %w", lease.name, lease.field, err)
+			}
+		}
+
+		field, ok := fields[lease.field]
+		if !ok {
+			return fmt.Errorf("source %s: unknown field %s", lease.name, lease.field)
+		}
+		sf, index := fieldInfo(field)
+		target := copyPath(fresh, index)
+		if err := l.setFieldData(l.newFieldData(sf, target, nil), value); err != nil {
+			return fmt.Errorf("source %s: field %s: %w", lease.name, lease.field, err)
+		}
+
+		// a value without TTL doesn't expire, so it's not refreshed anymore.
+		leases[i].refreshAt = time.Time{}
+		if ttl > 0 {
+			leases[i].refreshAt = leaseRefreshAt(now, ttl)
+		}
+	}
+
+	var remaining []leasedField
+	for _, lease := range leases {
+		if !lease.refreshAt.IsZero() {
+			remaining = append(remaining, lease)
+		}
+	}
+
+	var changes []FieldChange
+	if l.config.OnChange != nil {
+		l.stateMu.RLock()
+		changes = l.diff(dst, fresh, l.allFields())
+		l.stateMu.RUnlock()
+	}
+
+	l.swapMu.Lock()
+	setConfig(dst, fresh)
+	l.swapMu.Unlock()
+	l.publish(fresh.Addr())
+
+	l.stateMu.Lock()
+	l.leases = remaining
+	if !l.config.NewParser {
+		// pointers on the way to the leased fields are new.
+		fields := l.rebindFields(l.dst)
+		for i, field := range fields {
+			field.isSet = l.fields[i].isSet
+			field.source = l.fields[i].source
+		}
+		l.fields = fields
+	}
+	l.stateMu.Unlock()
+	l.notifyChanges(changes)
+	return nil
+}
+
+// copyPath returns the field by index like settableByIndex,
+// but pointers on the way are replaced with copies, so setting the field
+// doesn't change structures shared with the configuration value was copied from.
+func copyPath(value reflect.Value, index []int) reflect.Value {
+	for _, idx := range index {
+		if value.Kind() == reflect.Ptr {
+			value = copyPtr(value)
+		}
+		value = value.Field(idx)
+	}
+	if value.Kind() == reflect.Ptr {
+		value = copyPtr(value)
+	}
+	return value
+}
+
+// copyPtr sets the pointer to a copy of its value and returns the copy.
+func copyPtr(ptr reflect.Value) reflect.Value {
+	copied := reflect.New(ptr.Type().Elem())
+	if !ptr.IsNil() {
+		copied.Elem().Set(ptr.Elem())
+	}
+	ptr.Set(copied)
+	return copied.Elem()
+}
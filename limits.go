@@ -0,0 +1,95 @@
+package aconfig
+
+import (
+	"fmt"
+	"io/fs"
+)
+
+// checkFileSize returns an error if the file is larger than max bytes.
+func checkFileSize(fsys fs.FS, name string, max int64) error {
+	info, err := fs.Stat(fsys, name)
+	if err != nil {
+		// let the decoder report it.
+		return nil
+	}
+	if info.Size() > max {
+		return fmt.Errorf("file %s is too large: %d bytes, limit is %d", name, info.Size(), max)
+	}
+	return nil
+}
+
+// limitFS fails reading of the file name after max bytes.
+// Protects from files with unknown or changing size like pipes.
+type limitFS struct {
+	fs.FS
+	name string
+	max  int64
+}
+
+func (l *limitFS) Open(name string) (fs.File, error) {
+	f, err := l.FS.Open(name)
+	if err != nil || name != l.name {
+		return f, err
+	}
+	return &limitFile{File: f, name: name, left: l.max}, nil
+}
+
+type limitFile struct {
+	fs.File
+	name string
+	left int64
+}
+
+func (f *limitFile) Read(p []byte) (int, error) {
+	if f.left < 0 {
+		return 0, fmt.Errorf("file %s is too large", f.name)
+	}
+	if int64(len(p)) > f.left+1 {
+		p = p[:f.left+1]
+	}
+	n, err := f.File.Read(p)
+	f.left -= int64(n)
+	if f.left < 0 {
+		return n, fmt.Errorf("file %s is too large", f.name)
+	}
+	return n, err
+}
+
+// checkDepth returns an error if maps and slices in v are nested deeper than max levels.
+func checkDepth(v any, max int) error {
+	if depth(v, max) > max {
+		return fmt.Errorf("nesting depth exceeds %d", max)
+	}
+	return nil
+}
+
+// depth of nested maps and slices, stops counting after max.
+func depth(v any, max int) int {
+	if max < 0 {
+		return 0
+	}
+	res := 0
+	switch v := v.(type) {
+	case map[string]any:
+		for _, vv := range v {
+			if d := depth(vv, max-1); d > res {
+				res = d
+			}
+		}
+	case map[any]any:
+		for _, vv := range v {
+			if d := depth(vv, max-1); d > res {
+				res = d
+			}
+		}
+	case []any:
+		for _, vv := range v {
+			if d := depth(vv, max-1); d > res {
+				res = d
+			}
+		}
+	default:
+		return 0
+	}
+	return res + 1
+}
@@ -0,0 +1,91 @@
+package aconfig
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestMaxFileSize(t *testing.T) {
+	type TestConfig struct {
+		Name string
+	}
+
+	f := func(data string, stream bool) error {
+		t.Helper()
+
+		var cfg TestConfig
+		return LoaderFor(&cfg, Config{
+			NewParser:    newParser,
+			SkipDefaults: true,
+			SkipEnv:      true,
+			SkipFlags:    true,
+			StreamFiles:  stream,
+			MaxFileSize:  32,
+			Files:        []string{"config.json"},
+			FileSystem: fstest.MapFS{
+				"config.json": &fstest.MapFile{Data: []byte(data)},
+			},
+		}).Load()
+	}
+
+	failIfErr(t, f(`{"name": "short"}`, false))
+	failIfErr(t, f(`{"name": "short"}`, true))
+
+	long := `{"name": "` + strings.Repeat("a", 64) + `"}`
+	err := f(long, false)
+	failIfOk(t, err)
+	mustEqual(t, err.Error(), "load config: load files: file config.json is too large: 76 bytes, limit is 32")
+
+	failIfOk(t, f(long, true))
+}
+
+func TestMaxFileSizeLimitsReads(t *testing.T) {
+	fsys := &limitFS{
+		FS:   fstest.MapFS{"a.json": &fstest.MapFile{Data: []byte("0123456789")}},
+		name: "a.json",
+		max:  5,
+	}
+	_, err := fs.ReadFile(fsys, "a.json")
+	failIfOk(t, err)
+	mustEqual(t, err.Error(), "file a.json is too large")
+
+	fsys.max = 10
+	data, err := fs.ReadFile(fsys, "a.json")
+	failIfErr(t, err)
+	mustEqual(t, string(data), "0123456789")
+}
+
+func TestMaxDepth(t *testing.T) {
+	type TestConfig struct {
+		A struct {
+			B struct {
+				C string
+			}
+		}
+	}
+
+	f := func(data string) error {
+		t.Helper()
+
+		var cfg TestConfig
+		return LoaderFor(&cfg, Config{
+			NewParser:    newParser,
+			SkipDefaults: true,
+			SkipEnv:      true,
+			SkipFlags:    true,
+			MaxDepth:     3,
+			Files:        []string{"config.json"},
+			FileSystem: fstest.MapFS{
+				"config.json": &fstest.MapFile{Data: []byte(data)},
+			},
+		}).Load()
+	}
+
+	failIfErr(t, f(`{"a": {"b": {"c": "ok"}}}`))
+
+	err := f(`{"a": {"b": {"c": [[[["deep"]]]]}}}`)
+	failIfOk(t, err)
+	mustEqual(t, err.Error(), "load config: load files: file config.json: nesting depth exceeds 3")
+}
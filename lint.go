@@ -0,0 +1,94 @@
+package aconfig
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// maxLintDistance is the biggest Levenshtein distance for a name to be a near-miss.
+const maxLintDistance = 2
+
+// NearMiss is an environment variable or a file key which looks like a typo of a known name.
+type NearMiss struct {
+	Source string // Source is "env" or a path to the file.
+	Name   string // Name found in the source.
+	Known  string // Known is the closest name expected by the loader.
+}
+
+// Lint reports environment variables and file keys that are one typo away from known fields
+// (Levenshtein distance is 2 or less), even when unknown envs or fields are allowed.
+// Environment variables are checked only with Config.EnvPrefix, otherwise every variable
+// of the process would be a candidate.
+// Should be called after Load. Results are sorted by source and name.
+func (l *Loader) Lint() []NearMiss {
+	l.stateMu.RLock()
+	defer l.stateMu.RUnlock()
+	return l.lint()
+}
+
+func (l *Loader) lint() []NearMiss {
+	var res []NearMiss
+
+	if !l.config.SkipEnv && l.config.EnvPrefix != "" {
+		known := l.knownNames("env")
+		for env := range getEnv(l.config.Envs) {
+			// the prefix ends with the delimiter after init, so APP doesn't match APPLE_HOST.
+			if !strings.HasPrefix(env, l.config.EnvPrefix) {
+				continue
+			}
+			if near, ok := nearestName(env, known); ok {
+				res = append(res, NearMiss{Source: "env", Name: env, Known: near})
+			}
+		}
+	}
+
+	for _, key := range l.unknownKeys {
+		known := l.knownNames(l.fileFormat(key.file))
+		if near, ok := nearestName(key.key, known); ok {
+			res = append(res, NearMiss{Source: key.file, Name: key.key, Known: near})
+		}
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Source != res[j].Source {
+			return res[i].Source < res[j].Source
+		}
+		return res[i].Name < res[j].Name
+	})
+	return res
+}
+
+func (l *Loader) knownNames(tag string) map[string]struct{} {
+	names := make(map[string]struct{}, len(l.fields))
+	for _, field := range l.allFields() {
+		if name := l.sourceName(field, tag); name != "" {
+			names[name] = struct{}{}
+		}
+	}
+	return names
+}
+
+func (l *Loader) fileFormat(file string) string {
+	dec, ok := l.config.FileDecoders[strings.ToLower(filepath.Ext(file))]
+	if !ok {
+		return ""
+	}
+	return dec.Format()
+}
+
+// nearestName returns the closest known name if it's close enough, but not equal.
+func nearestName(name string, known map[string]struct{}) (string, bool) {
+	if _, ok := known[name]; ok {
+		return "", false
+	}
+
+	best, bestDist := "", maxLintDistance+1
+	for k := range known {
+		dist := levenshtein(name, k)
+		if dist < bestDist || (dist == bestDist && k < best) {
+			best, bestDist = k, dist
+		}
+	}
+	return best, bestDist <= maxLintDistance
+}
@@ -0,0 +1,51 @@
+package aconfig
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestLint(t *testing.T) {
+	type TestConfig struct {
+		DB struct {
+			Host string
+			Port int
+		}
+	}
+
+	loader := LoaderFor(&TestConfig{}, Config{
+		SkipFlags:          true,
+		EnvPrefix:          "APP",
+		AllowUnknownEnvs:   true,
+		AllowUnknownFields: true,
+		Envs:               []string{"APP_DB_HSOT=localhost", "APP_DB_PORT=5432", "APP_SOMETHING_ELSE=1", "APPLE_DB_HSOT=1", "HOME=/root"},
+		Files:              []string{"config.json"},
+		FileSystem: fstest.MapFS{
+			"config.json": &fstest.MapFile{Data: []byte(`{"db": {"prot": 5432, "host": "db"}, "other": 1}`)},
+		},
+	})
+	failIfErr(t, loader.Load())
+
+	want := []NearMiss{
+		{Source: "config.json", Name: "db.prot", Known: "db.port"},
+		{Source: "env", Name: "APP_DB_HSOT", Known: "APP_DB_HOST"},
+	}
+	mustEqual(t, loader.Lint(), want)
+}
+
+func TestLintNoPrefix(t *testing.T) {
+	type TestConfig struct {
+		Port int
+		Host string
+		Path string
+	}
+
+	loader := LoaderFor(&TestConfig{}, Config{
+		NewParser: newParser,
+		SkipFlags: true,
+		SkipFiles: true,
+		Envs:      []string{"PATH=/usr/bin", "HOME=/root", "PWD=/", "USER=root", "SHELL=/bin/sh"},
+	})
+	failIfErr(t, loader.Load())
+	mustEqual(t, len(loader.Lint()), 0)
+}
@@ -0,0 +1,40 @@
+package aconfig
+
+import (
+	"reflect"
+	"strings"
+)
+
+// EnvNameFor returns the env var name the loader generates for a field path like "Auth.UserName":
+// APP_AUTH_USER_NAME with Config.EnvPrefix "APP".
+// Names set with `env` tags aren't known without the structure and aren't taken into account.
+func EnvNameFor(cfg Config, fieldPath string) string {
+	return nameFor(cfg, fieldPath, "env")
+}
+
+// FlagNameFor returns the flag name (without a dash) the loader generates for a field path like "Auth.UserName":
+// app.auth.user_name with Config.FlagPrefix "app". Config.FlagDelimiter is respected.
+func FlagNameFor(cfg Config, fieldPath string) string {
+	return nameFor(cfg, fieldPath, "flag")
+}
+
+func nameFor(cfg Config, fieldPath, tag string) string {
+	sep, prefix := cfg.FlagDelimiter, cfg.FlagPrefix
+	if sep == "" {
+		sep = "."
+	}
+	if tag == "env" {
+		sep, prefix = "_", cfg.EnvPrefix
+	}
+
+	l := &Loader{config: cfg}
+	parts := strings.Split(fieldPath, ".")
+	for i, part := range parts {
+		parts[i] = l.makeTagValue(reflect.StructField{Name: part}, tag, cfg.WordSplit.Split(part))
+	}
+
+	if prefix != "" {
+		prefix += sep
+	}
+	return prefix + strings.Join(parts, sep)
+}
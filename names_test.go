@@ -0,0 +1,35 @@
+package aconfig
+
+import (
+	"testing"
+)
+
+func TestNameFor(t *testing.T) {
+	type TestConfig struct {
+		HTTPPort int
+		Auth     struct {
+			UserName string
+			APIKey   string
+		}
+	}
+
+	f := func(cfg Config) {
+		t.Helper()
+
+		cfg.NewParser = newParser
+		cfg.SkipFiles = true
+		loader := LoaderFor(&TestConfig{}, cfg)
+
+		for _, field := range loader.allFields() {
+			mustEqual(t, EnvNameFor(cfg, field.Name()), loader.sourceName(field, "env"))
+			mustEqual(t, FlagNameFor(cfg, field.Name()), loader.sourceName(field, "flag"))
+		}
+	}
+
+	f(Config{})
+	f(Config{EnvPrefix: "APP", FlagPrefix: "app"})
+	f(Config{FlagPrefix: "app", FlagDelimiter: "-"})
+
+	mustEqual(t, EnvNameFor(Config{EnvPrefix: "APP"}, "Auth.UserName"), "APP_AUTH_USER_NAME")
+	mustEqual(t, FlagNameFor(Config{FlagPrefix: "app"}, "Auth.UserName"), "app.auth.user_name")
+}
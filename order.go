@@ -0,0 +1,174 @@
+package aconfig
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// SourceKind is a kind of configuration source. See Config.Order.
+type SourceKind string
+
+const (
+	SourceDefaults SourceKind = "default" // SourceDefaults is `default` tags and embedded defaults.
+	SourceFiles    SourceKind = "file"    // SourceFiles is Config.Files, Config.Dirs and Config.FileGroups.
+	SourceCustom   SourceKind = "source"  // SourceCustom is Config.Sources and field sources.
+	SourceEnv      SourceKind = "env"     // SourceEnv is environment variables.
+	SourceFlags    SourceKind = "flag"    // SourceFlags is flag parameters.
+)
+
+var defaultOrder = []SourceKind{SourceDefaults, SourceFiles, SourceCustom, SourceEnv, SourceFlags}
+
+// checkOrder returns an error for an unknown or a repeated kind in Config.Order.
+func (l *Loader) checkOrder() error {
+	seen := map[SourceKind]bool{}
+	for _, kind := range l.config.Order {
+		switch kind {
+		case SourceDefaults, SourceFiles, SourceCustom, SourceEnv, SourceFlags:
+		default:
+			return fmt.Errorf("unknown source kind %q in order", kind)
+		}
+		if seen[kind] {
+			return fmt.Errorf("source kind %q is repeated in order", kind)
+		}
+		seen[kind] = true
+	}
+	return nil
+}
+
+// skipUnordered sets Skip* params for kinds that aren't listed in Config.Order.
+func (l *Loader) skipUnordered() {
+	if len(l.config.Order) == 0 {
+		return
+	}
+	listed := map[SourceKind]bool{}
+	for _, kind := range l.config.Order {
+		listed[kind] = true
+	}
+	l.config.SkipDefaults = l.config.SkipDefaults || !listed[SourceDefaults]
+	l.config.SkipFiles = l.config.SkipFiles || !listed[SourceFiles]
+	l.config.SkipEnv = l.config.SkipEnv || !listed[SourceEnv]
+	l.config.SkipFlags = l.config.SkipFlags || !listed[SourceFlags]
+}
+
+// loadOrder returns source kinds in the order they are loaded.
+func (l *Loader) loadOrder() []SourceKind {
+	if len(l.config.Order) == 0 {
+		return defaultOrder
+	}
+	return l.config.Order
+}
+
+// loadSource loads all the sources of the kind unless they are skipped by config.
+func (l *Loader) loadSource(ctx context.Context, kind SourceKind) error {
+	switch kind {
+	case SourceDefaults:
+		if l.config.SkipDefaults {
+			return nil
+		}
+		if l.config.EmbeddedDefaultsFirst {
+			if err := l.loadEmbeddedDefaults(); err != nil {
+				return fmt.Errorf("load embedded defaults: %w", err)
+			}
+		}
+		if err := l.loadDefaults(); err != nil {
+			return fmt.Errorf("load defaults: %w", err)
+		}
+		if !l.config.EmbeddedDefaultsFirst {
+			if err := l.loadEmbeddedDefaults(); err != nil {
+				return fmt.Errorf("load embedded defaults: %w", err)
+			}
+		}
+	case SourceFiles:
+		if l.config.SkipFiles {
+			return nil
+		}
+		if err := l.loadFiles(ctx); err != nil {
+			return fmt.Errorf("load files: %w", err)
+		}
+	case SourceCustom:
+		if err := l.loadCustomSources(ctx); err != nil {
+			return fmt.Errorf("load sources: %w", err)
+		}
+	case SourceEnv:
+		if l.config.SkipEnv {
+			return nil
+		}
+		if err := l.loadEnvironment(); err != nil {
+			return fmt.Errorf("load environment: %w", err)
+		}
+		if err := l.loadEnvRemain(); err != nil {
+			return fmt.Errorf("load environment: %w", err)
+		}
+	case SourceFlags:
+		if l.config.SkipFlags {
+			return nil
+		}
+		if err := l.loadFlags(); err != nil {
+			return fmt.Errorf("load flags: %w", err)
+		}
+	}
+	return nil
+}
+
+// addedSource is a source added with Loader.AddSource.
+type addedSource struct {
+	after  SourceKind
+	name   string
+	values map[string]any
+}
+
+// AddSource adds values keyed by field paths like "DB.UserName" that are loaded right after sources of the kind,
+// so they override it and are overridden by the next kinds in Config.Order.
+// Values are parsed like env values, ValueSource of such fields has "source" kind with the given name.
+// Unknown field paths fail Load. Call it before Load, sources are kept for reloads.
+func (l *Loader) AddSource(after SourceKind, name string, values map[string]any) {
+	l.stateMu.Lock()
+	defer l.stateMu.Unlock()
+	l.added = append(l.added, addedSource{after: after, name: name, values: values})
+}
+
+// loadAdded loads sources added with Loader.AddSource after the kind.
+func (l *Loader) loadAdded(kind SourceKind) error {
+	for _, src := range l.added {
+		if src.after != kind {
+			continue
+		}
+		if err := l.loadAddedSource(src); err != nil {
+			return fmt.Errorf("load source %s: %w", src.name, err)
+		}
+	}
+	return nil
+}
+
+func (l *Loader) loadAddedSource(src addedSource) error {
+	fields := map[string]Field{}
+	for _, field := range l.allFields() {
+		fields[field.Name()] = field
+	}
+
+	paths := make([]string, 0, len(src.values))
+	for path := range src.values {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		field, ok := fields[path]
+		if !ok {
+			return fmt.Errorf("unknown field %q", path)
+		}
+		value := src.values[path]
+		if l.config.Decrypt != nil {
+			var err error
+			if value, err = l.decryptValue(path, value); err != nil {
+				return fmt.Errorf("field %s: %w", path, err)
+			}
+		}
+		source := ValueSource{Kind: "source", Name: path, File: src.name}
+		if err := l.setFieldValue(field, value, source); err != nil {
+			return fmt.Errorf("field %s: %w", path, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package aconfig
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestOrder(t *testing.T) {
+	type TestConfig struct {
+		Host string `default:"localhost"`
+		Port int    `default:"8080"`
+		Name string
+	}
+
+	fsys := fstest.MapFS{
+		"config.json": {Data: []byte(`{"host": "file-host", "port": 1000, "name": "file-name"}`)},
+	}
+	load := func(order []SourceKind) (TestConfig, *Loader) {
+		t.Helper()
+		var cfg TestConfig
+		loader := LoaderFor(&cfg, Config{
+			NewParser:        newParser,
+			FileSystem:       fsys,
+			Files:            []string{"config.json"},
+			EnvPrefix:        "APP",
+			Envs:             []string{"APP_PORT=2000", "APP_NAME=env-name"},
+			FlagPrefix:       "app",
+			Args:             []string{"-app.port=3000"},
+			AllowUnknownEnvs: true,
+			Order:            order,
+		})
+		failIfErr(t, loader.Load())
+		return cfg, loader
+	}
+
+	cfg, _ := load(nil)
+	mustEqual(t, cfg, TestConfig{Host: "file-host", Port: 3000, Name: "env-name"})
+
+	cfg, loader := load([]SourceKind{SourceDefaults, SourceFiles, SourceFlags, SourceEnv})
+	mustEqual(t, cfg, TestConfig{Host: "file-host", Port: 2000, Name: "env-name"})
+	mustEqual(t, loader.Explain("Port"), "Port: env APP_PORT")
+
+	cfg, loader = load([]SourceKind{SourceFiles, SourceDefaults})
+	mustEqual(t, cfg, TestConfig{Host: "localhost", Port: 8080, Name: "file-name"})
+	mustEqual(t, loader.Explain("Host"), "Host: default")
+	mustEqual(t, loader.Explain("Name"), "Name: file config.json (key name)")
+
+	cfg, _ = load([]SourceKind{SourceEnv})
+	mustEqual(t, cfg, TestConfig{Port: 2000, Name: "env-name"})
+}
+
+func TestOrderInvalid(t *testing.T) {
+	f := func(order []SourceKind, want string) {
+		t.Helper()
+		loader := LoaderFor(&struct{ Port int }{}, Config{
+			NewParser: newParser,
+			SkipFlags: true,
+			Order:     order,
+		})
+		err := loader.Load()
+		failIfOk(t, err)
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("got %v, want %q", err, want)
+		}
+	}
+
+	f([]SourceKind{SourceEnv, "vault"}, `unknown source kind "vault"`)
+	f([]SourceKind{SourceEnv, SourceFiles, SourceEnv}, `source kind "env" is repeated`)
+}
+
+func TestAddSource(t *testing.T) {
+	type TestConfig struct {
+		Port int `default:"8080"`
+		Host string
+		DB   struct {
+			User string `default:"admin"`
+		}
+	}
+
+	var cfg TestConfig
+	loader := LoaderFor(&cfg, Config{
+		NewParser:  newParser,
+		SkipFiles:  true,
+		EnvPrefix:  "APP",
+		Envs:       []string{"APP_PORT=2000", "APP_HOST=env-host"},
+		FlagPrefix: "app",
+		Args:       []string{"-app.host=flag-host"},
+	})
+	loader.AddSource(SourceEnv, "runtime", map[string]any{"Port": 3000, "Host": "runtime-host"})
+	loader.AddSource(SourceDefaults, "features", map[string]any{"DB.User": "root", "Port": "1000"})
+	failIfErr(t, loader.Load())
+
+	mustEqual(t, cfg.Port, 3000)
+	mustEqual(t, cfg.Host, "flag-host")
+	mustEqual(t, cfg.DB.User, "root")
+	mustEqual(t, loader.Explain("Port"), "Port: source runtime (key Port)")
+	mustEqual(t, loader.Explain("DB.User"), "DB.User: source features (key DB.User)")
+
+	loader = LoaderFor(&cfg, Config{
+		NewParser: newParser,
+		SkipFiles: true,
+		SkipFlags: true,
+	})
+	loader.AddSource(SourceEnv, "runtime", map[string]any{"Nope": 1})
+	err := loader.Load()
+	failIfOk(t, err)
+	if !strings.Contains(err.Error(), `unknown field "Nope"`) {
+		t.Fatal(err)
+	}
+}
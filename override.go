@@ -0,0 +1,40 @@
+package aconfig
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+)
+
+// loadOverrideFile applies Config.OverrideFile over all the other sources.
+// A missing or an empty file is ignored.
+func (l *Loader) loadOverrideFile() error {
+	path := l.config.OverrideFile
+	if path == "" {
+		return nil
+	}
+
+	data, err := fs.ReadFile(l.fsys, path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return nil
+	case err != nil:
+		return err
+	case strings.TrimSpace(string(data)) == "":
+		return nil
+	}
+
+	file := FileEntry{Path: path, FileSystem: l.fsys}
+	values, tag, err := l.decodeFile(file)
+	if err != nil {
+		return err
+	}
+	values = l.expandEnv(values)
+	values, err = l.decryptValues(values)
+	if err != nil {
+		return fmt.Errorf("file %s: %w", path, err)
+	}
+	l.loadedFiles = append(l.loadedFiles, path)
+	return l.applyValues(ValueSource{Kind: "override", File: path}, tag, l.applySubtree(values))
+}
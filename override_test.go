@@ -0,0 +1,53 @@
+package aconfig
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestOverrideFile(t *testing.T) {
+	type TestConfig struct {
+		Port     int    `default:"8080"`
+		LogLevel string `default:"info"`
+		Host     string
+	}
+
+	f := func(override string, want TestConfig) *Loader {
+		t.Helper()
+		fsys := fstest.MapFS{
+			"config.json": {Data: []byte(`{"port": 1000, "host": "file-host"}`)},
+		}
+		if override != "" {
+			fsys["override.json"] = &fstest.MapFile{Data: []byte(override)}
+		}
+
+		var cfg TestConfig
+		loader := LoaderFor(&cfg, Config{
+			NewParser:    newParser,
+			FileSystem:   fsys,
+			Files:        []string{"config.json"},
+			OverrideFile: "override.json",
+			Envs:         []string{"LOG_LEVEL=debug"},
+			Args:         []string{"-port=2000"},
+		})
+		failIfErr(t, loader.Load())
+		mustEqual(t, cfg, want)
+		return loader
+	}
+
+	f("", TestConfig{Port: 2000, LogLevel: "debug", Host: "file-host"})
+	f("  \n", TestConfig{Port: 2000, LogLevel: "debug", Host: "file-host"})
+
+	loader := f(`{"port": 3000, "removed_field": true}`, TestConfig{Port: 3000, LogLevel: "debug", Host: "file-host"})
+	mustEqual(t, loader.Explain("Port"), "Port: override override.json (key port)")
+	mustEqual(t, loader.Explain("LogLevel"), "LogLevel: env LOG_LEVEL")
+
+	var cfg TestConfig
+	err := LoaderFor(&cfg, Config{
+		NewParser:    newParser,
+		SkipFlags:    true,
+		FileSystem:   fstest.MapFS{"override.json": {Data: []byte(`{"port": `)}},
+		OverrideFile: "override.json",
+	}).Load()
+	failIfOk(t, err)
+}
@@ -17,6 +17,12 @@ type structParser struct {
 	flagSet   *flag.FlagSet
 	envNames  map[string]struct{}
 	flagNames map[string]struct{}
+
+	// order of the leaf fields as they're declared, used to walk fields.
+	order []*parsedField
+
+	// fileFields are names of the fields found in the loaded files.
+	fileFields map[string]struct{}
 }
 
 func newStructParser(cfg Config) *structParser {
@@ -33,11 +39,121 @@ type parsedField struct {
 	namefull     string
 	value        any
 	defaultValue any
+	typedDefault any // default value parsed into the field type, see DefaultValue
 	parent       *parsedField
 	childs       map[string]any
 	tags         map[string]string
+	field        reflect.StructField
+	index        []int // index of the field in the destination struct
 	hasChilds    bool
 	isRequired   bool
+	allowEmpty   bool
+	isSet        bool
+	source       ValueSource
+}
+
+func (pf *parsedField) Name() string {
+	return pf.namefull
+}
+
+func (pf *parsedField) Tag(tag string) string {
+	switch tag {
+	case "env":
+		return pf.tags["env_name"]
+	case "flag":
+		return pf.tags["flag_name"]
+	}
+	if t, ok := pf.tags[tag]; ok {
+		return t
+	}
+	return pf.field.Tag.Get(tag)
+}
+
+func (pf *parsedField) AllTags() map[string]string {
+	generated := make(map[string]string, len(pf.tags))
+	for key, value := range pf.tags {
+		switch key {
+		case "env_name":
+			generated["env"] = value
+		case "flag_name":
+			generated["flag"] = value
+		case "env_full", "flag_full":
+		default:
+			generated[key] = value
+		}
+	}
+	return allTags(pf.field.Tag, generated)
+}
+
+func (pf *parsedField) Parent() (Field, bool) {
+	if pf.parent == nil {
+		return nil, false
+	}
+	return pf.parent, true
+}
+
+func (pf *parsedField) Source() ValueSource {
+	return pf.source
+}
+
+func (pf *parsedField) DefaultValue() any {
+	return pf.typedDefault
+}
+
+func (pf *parsedField) EnumValues() []string {
+	return enumValues(pf)
+}
+
+// fullName returns env var, flag or a dotted file key of the field.
+func (pf *parsedField) fullName(tag string) string {
+	switch tag {
+	case "env":
+		return pf.tags["env_full"]
+	case "flag":
+		return pf.tags["flag_full"]
+	}
+
+	name := pf.tags[tag]
+	if name == "" || name == "-" {
+		return ""
+	}
+	for p := pf.parent; p != nil; p = p.parent {
+		if t := p.tags[tag]; t != "-" {
+			name = t + "." + name
+		}
+	}
+	return name
+}
+
+// set value from a source, empty value doesn't mark the field as set. See fieldData.isProvided.
+func (sp *structParser) set(pf *parsedField, value any, source ValueSource) {
+	if pf.isSet && value != "" {
+		if merge := sliceMergeOf(pf.field, sp.cfg.SliceMerge); merge != SliceReplace {
+			value = mergeRaw(pf.value, value, sp.cfg.SliceSeparator, merge)
+		}
+	}
+	pf.value = value
+	if value != "" || pf.allowEmpty {
+		sp.markSet(pf, source, value)
+	}
+}
+
+// setFrom sets value from a file or a source.
+func (sp *structParser) setFrom(pf *parsedField, value any, from ValueSource, tag string) {
+	from.Name = pf.fullName(tag)
+	sp.set(pf, value, from)
+	if from.Kind == "file" {
+		sp.fileFields[pf.namefull] = struct{}{}
+	}
+}
+
+// markSet marks the field as set by the source and notifies Config.OnFieldSet.
+func (sp *structParser) markSet(pf *parsedField, source ValueSource, raw any) {
+	pf.isSet = true
+	pf.source = source
+	if sp.cfg.OnFieldSet != nil {
+		sp.cfg.OnFieldSet(pf, source.Kind, raw)
+	}
 }
 
 func (pf *parsedField) String() string {
@@ -58,7 +174,8 @@ func (sp *structParser) newParseField(parent *parsedField, field reflect.StructF
 		name = field.Name
 	}
 
-	newName := strings.ToLower(strings.Join(splitNameByWords(name), "_"))
+	words := sp.cfg.WordSplit.Split(name)
+	newName := strings.ToLower(strings.Join(words, "_"))
 
 	env := field.Tag.Get("env")
 	if env == "" {
@@ -67,35 +184,31 @@ func (sp *structParser) newParseField(parent *parsedField, field reflect.StructF
 
 	flag := field.Tag.Get("flag")
 	if flag == "" {
-		flag = newName
+		flag = sp.cfg.FlagStyle.join(words)
 	}
 
 	var parentName, parentEnv, parentFlag string
 	if parent != nil {
-		parentName = parent.namefull + "|"
-
-		for p := parent; p != nil; p = p.parent {
-			parentEnv = p.tags["env_name"]
-			if parentEnv != "-" {
-				break
-			}
+		parentName = parent.namefull + "."
+	}
+	for p := parent; p != nil; p = p.parent {
+		if name := p.tags["env_name"]; name != "-" {
+			parentEnv = name + sp.cfg.envDelimiter + parentEnv
 		}
-		for p := parent; p != nil; p = p.parent {
-			parentFlag = p.tags["flag_name"]
-			if parentFlag != "-" {
-				break
-			}
+		if name := p.tags["flag_name"]; name != "-" {
+			parentFlag = name + sp.cfg.FlagDelimiter + parentFlag
 		}
-
-		parentEnv += sp.cfg.envDelimiter
-		parentFlag += sp.cfg.FlagDelimiter
 	}
 
+	_, opts := parseAconfigTag(field.Tag.Get("aconfig"))
+
 	pfield := &parsedField{
 		name:     name,
 		namefull: parentName + name,
 		parent:   parent,
+		field:    field,
 		tags: map[string]string{
+			"default":   defaultTag(field, sp.cfg.Environment),
 			"usage":     field.Tag.Get("usage"),
 			"env_name":  env,
 			"env_full":  sp.cfg.EnvPrefix + parentEnv + env,
@@ -103,11 +216,25 @@ func (sp *structParser) newParseField(parent *parsedField, field reflect.StructF
 			"flag_full": sp.cfg.FlagPrefix + parentFlag + flag,
 		},
 		isRequired: requiredTag == "true",
+		allowEmpty: opts["allowempty"],
 	}
 
+	data := usageData{Name: pfield.namefull, Default: pfield.tags["default"]}
+	if !sp.cfg.SkipEnv && env != "-" {
+		data.Env = pfield.tags["env_full"]
+	}
+	if !sp.cfg.SkipFlags && flag != "-" {
+		data.Flag = pfield.tags["flag_full"]
+	}
+	usage, err := expandUsage(pfield.tags["usage"], data)
+	if err != nil {
+		return nil, err
+	}
+	pfield.tags["usage"] = usage
+
 	if !sp.cfg.SkipDefaults {
 		// TODO: must be typed?
-		pfield.defaultValue = field.Tag.Get("default")
+		pfield.defaultValue = pfield.tags["default"]
 	}
 
 	if env == "-" {
@@ -135,12 +262,23 @@ func (sp *structParser) newParseField(parent *parsedField, field reflect.StructF
 	if !sp.cfg.SkipFlags {
 		flagName := pfield.tags["flag_full"]
 		if flagName != "" {
-			if _, ok := sp.flagNames[flagName]; ok && !sp.cfg.AllowDuplicates {
-				return nil, fmt.Errorf("duplicate flag %q", flagName)
+			if _, ok := sp.flagNames[flagName]; ok {
+				if !sp.cfg.AllowDuplicates {
+					return nil, fmt.Errorf("duplicate flag %q", flagName)
+				}
+			} else {
+				sp.flagNames[flagName] = struct{}{}
+				// TODO: must be typed
+				value := repeatedFlag(field, pfield.tags["default"], sp.cfg.SliceSeparator)
+				switch {
+				case value != nil:
+					sp.flagSet.Var(value, flagName, pfield.tags["usage"])
+				case sp.cfg.Experimental.Has(ExperimentTypedFlags):
+					sp.flagSet.Var(newTypedFlag(field, pfield.tags["default"]), flagName, pfield.tags["usage"])
+				default:
+					sp.flagSet.String(flagName, pfield.tags["default"], pfield.tags["usage"])
+				}
 			}
-			sp.flagNames[flagName] = struct{}{}
-			// TODO: must be typed
-			sp.flagSet.String(flagName, field.Tag.Get("default"), field.Tag.Get("usage"))
 		}
 	}
 
@@ -164,7 +302,7 @@ func (sp *structParser) parseStruct(x any) error {
 		value = value.Elem()
 	}
 
-	fields, err := sp.parseStructHelper(nil, value, map[string]any{})
+	fields, err := sp.parseStructHelper(nil, nil, value, map[string]any{})
 	if err != nil {
 		return err
 	}
@@ -174,7 +312,7 @@ func (sp *structParser) parseStruct(x any) error {
 	return nil
 }
 
-func (sp *structParser) parseStructHelper(parent *parsedField, structValue reflect.Value, res map[string]any) (map[string]any, error) {
+func (sp *structParser) parseStructHelper(parent *parsedField, index []int, structValue reflect.Value, res map[string]any) (map[string]any, error) {
 	count := structValue.NumField()
 	structType := structValue.Type()
 
@@ -182,15 +320,16 @@ func (sp *structParser) parseStructHelper(parent *parsedField, structValue refle
 		field := structType.Field(i)
 		fieldValue := structValue.Field(i)
 		fieldType := fieldValue.Type()
-		if !fieldValue.CanSet() {
+		if !fieldValue.CanSet() || isIgnored(field) {
 			continue
 		}
 
-		defaultTagValue := field.Tag.Get("default")
 		pfield, err := sp.newParseField(parent, field)
 		if err != nil {
 			return nil, err
 		}
+		defaultTagValue := pfield.tags["default"]
+		pfield.index = append(index[:len(index):len(index)], i)
 
 		// do not set defaultValue for struct or pointer type without a default value
 		// if fieldType.Kind() == reflect.Struct ||
@@ -220,7 +359,13 @@ func (sp *structParser) parseStructHelper(parent *parsedField, structValue refle
 		// 	continue
 		// }
 
-		switch fieldType.Kind() {
+		kind := fieldType.Kind()
+		if isDynamicType(fieldType) || fieldType == timeType || hasAdapter(sp.cfg.Adapters, fieldType) {
+			// set as a whole with UnmarshalText, parseTime or an adapter.
+			kind = reflect.String
+		}
+
+		switch kind {
 		// case reflect.Array:
 		// TODO: same as slice + check len?
 
@@ -238,7 +383,7 @@ func (sp *structParser) parseStructHelper(parent *parsedField, structValue refle
 				parent = pfield.parent
 			}
 
-			values, err := sp.parseStructHelper(parent, fieldValue, param)
+			values, err := sp.parseStructHelper(parent, pfield.index, fieldValue, param)
 			if err != nil {
 				return nil, err
 			}
@@ -252,12 +397,12 @@ func (sp *structParser) parseStructHelper(parent *parsedField, structValue refle
 				if field.Type.Elem().Kind() == reflect.Uint8 {
 					value = []byte(defaultTagValue)
 				} else {
+					// like Loader.setSlice, a value without separators is a single item.
 					values := []any{}
-					if defaultTagValue != "" && !strings.Contains(defaultTagValue, ",") {
-						return nil, fmt.Errorf("incorrect default tag value for slice/array: %v", defaultTagValue)
-					}
-					for _, val := range strings.Split(defaultTagValue, ",") {
-						values = append(values, val)
+					if defaultTagValue != "" {
+						for _, val := range strings.Split(defaultTagValue, sp.cfg.SliceSeparator) {
+							values = append(values, strings.TrimSpace(val))
+						}
 					}
 					value = values
 				}
@@ -273,21 +418,17 @@ func (sp *structParser) parseStructHelper(parent *parsedField, structValue refle
 
 		case reflect.Map:
 			// if isPrimitive(field.Type.Elem()) {
+			// like Loader.setMap, a value without commas is a single entry.
 			values := map[string]any{}
-			parts := strings.Split(defaultTagValue, ",")
-			if defaultTagValue != "" && !strings.Contains(defaultTagValue, ",") {
-				return nil, fmt.Errorf("incorrect default tag value for map: %v", defaultTagValue)
-			}
-
-			if len(parts) > 1 {
-				for _, entry := range parts {
-					// fmt.Printf("parts: %+v\n", parts)
+			if defaultTagValue != "" {
+				for _, entry := range strings.Split(defaultTagValue, ",") {
 					entries := strings.SplitN(entry, ":", 2)
 					if len(entries) != 2 {
-						return nil, fmt.Errorf("want 2 parts got %d (%s)", len(entries), entries)
+						// reported by Loader.checkDefaults like for the default parser.
+						continue
 					}
 					// TODO: convert entry[1] to a primitive?
-					values[entries[0]] = entries[1]
+					values[strings.TrimSpace(entries[0])] = strings.TrimSpace(entries[1])
 				}
 			}
 			value = values
@@ -304,8 +445,8 @@ func (sp *structParser) parseStructHelper(parent *parsedField, structValue refle
 				// TODO: when WeaklyTypedInput will be false use decodePrimitive(...)
 				if !sp.cfg.SkipDefaults {
 					value = defaultTagValue
-					if fieldType == reflect.TypeOf(time.Second) {
-						val, err := time.ParseDuration(defaultTagValue)
+					if fieldType == reflect.TypeOf(time.Second) && defaultTagValue != "" {
+						val, err := parseDuration(defaultTagValue, field.Tag.Get("unit"))
 						if err != nil {
 							return nil, err
 						}
@@ -316,7 +457,7 @@ func (sp *structParser) parseStructHelper(parent *parsedField, structValue refle
 		}
 
 		// we should not overwrite struct because there are childs
-		if sp.cfg.SkipDefaults && fieldType.Kind() != reflect.Struct {
+		if sp.cfg.SkipDefaults && sp.isLeaf(fieldType) {
 			pfield.value = fieldValue.Interface()
 		} else {
 			pfield.value = value
@@ -324,34 +465,127 @@ func (sp *structParser) parseStructHelper(parent *parsedField, structValue refle
 
 		// fmt.Printf("def: %v %T '%+v'\n", fieldType.String(), value, value)
 		res[pfield.name] = pfield
+		if sp.isLeaf(fieldType) {
+			sp.order = append(sp.order, pfield)
+		}
 	}
 	return res, nil
 }
 
+// isLeaf reports whether a field of the type has a value rather than nested fields.
+func (sp *structParser) isLeaf(typ reflect.Type) bool {
+	return typ.Kind() != reflect.Struct || typ == timeType || hasAdapter(sp.cfg.Adapters, typ)
+}
+
 var fieldType = reflect.TypeOf(&parsedField{})
 
-var hook = mapstructure.DecodeHookFuncType(func(from, to reflect.Type, data any) (any, error) {
+// hook converts values of the fields like the default parser does: strings are split for slices and maps,
+// durations are parsed in pointers and slices too.
+func (sp *structParser) hook(from, to reflect.Type, data any) (any, error) {
 	if from != fieldType {
-		// fmt.Printf("hook: got %T (%+v) when %s\n", i, i, to.String())
+		// items of slices and maps, pointers are dereferenced by the decoder.
+		if s, ok := data.(string); ok && s != "" && to == durationType {
+			return parseDuration(s, "")
+		}
+		// fields of structs in pointers, like Dynamic, are decoded here too.
+		if s, ok := data.(string); ok && isDynamicType(to) {
+			v := reflect.New(to)
+			err := v.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+			return v.Interface(), err
+		}
 		return data, nil
 	}
 	field := data.(*parsedField)
 
+	target := to
+	if target.Kind() == reflect.Ptr {
+		target = target.Elem()
+	}
+	if s, ok := field.value.(string); ok && s != "" && !hasAdapter(sp.cfg.Adapters, to) && !isTextUnmarshaler(target) {
+		switch {
+		case target.Kind() == reflect.Slice && target.Elem().Kind() != reflect.Uint8:
+			return rawItems(s, sp.cfg.SliceSeparator), nil
+		case target.Kind() == reflect.Map:
+			return rawMap(s)
+		}
+	}
+
+	if target == durationType {
+		unit := field.field.Tag.Get("unit")
+		switch v := field.value.(type) {
+		case string:
+			if v != "" {
+				return parseDuration(v, unit)
+			}
+		case time.Duration, nil:
+		default:
+			if unit != "" {
+				return parseDuration(fmt.Sprint(v), unit)
+			}
+		}
+	}
+
+	if to == timeType || to == reflect.PtrTo(timeType) {
+		switch v := field.value.(type) {
+		case string:
+			if v == "" {
+				return time.Time{}, nil
+			}
+			return parseTime(v, field.field.Tag.Get("layout"))
+		case time.Time:
+			return v, nil
+		}
+	}
+
 	ifaceTo := reflect.New(to).Interface()
 	if unmarshaller, ok := ifaceTo.(encoding.TextUnmarshaler); ok {
-		// TODO: only string can be here?
-		b := []byte(field.value.(string))
+		b := []byte(fmt.Sprint(field.value))
+		if v, ok := field.value.(string); ok {
+			b = []byte(v)
+		}
 		err := unmarshaller.UnmarshalText(b)
 		return unmarshaller, err
 	}
 	// fmt.Printf("hook: when %s do '%+v' // %+v\n\n", to.String(), field.value, field)
 	return field.value, nil
-})
+}
+
+func isTextUnmarshaler(typ reflect.Type) bool {
+	_, ok := reflect.New(typ).Interface().(encoding.TextUnmarshaler)
+	return ok
+}
+
+// rawMap splits `key:value,key2:value2` like Loader.setMap, values are converted by the decoder.
+func rawMap(s string) (map[string]any, error) {
+	res := map[string]any{}
+	for _, entry := range strings.Split(s, ",") {
+		key, value, ok := cut(entry, ":")
+		if !ok {
+			return nil, fmt.Errorf("incorrect map item: %s", entry)
+		}
+		res[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return res, nil
+}
+
+// takeFactoryData moves values of interface fields with factories to data.
+// Such values can't be decoded by mapstructure and are handled by Loader.applyFactories.
+func (sp *structParser) takeFactoryData(data map[string]any) {
+	for _, pfield := range sp.order {
+		if pfield.field.Tag.Get("factory") == "" || pfield.field.Type.Kind() != reflect.Interface {
+			continue
+		}
+		if pfield.value != nil {
+			data[pfield.namefull] = pfield.value
+		}
+		pfield.value = nil
+	}
+}
 
 func (sp *structParser) apply(x any) error {
 	dec, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
 		Result:           x,
-		DecodeHook:       hook,
+		DecodeHook:       mapstructure.DecodeHookFuncType(sp.adapterHook),
 		WeaklyTypedInput: true, // TODO: temp fix?
 	})
 	if err != nil {
@@ -364,20 +598,44 @@ func (sp *structParser) apply(x any) error {
 	return nil
 }
 
-func (sp *structParser) applyLevel(tag string, values map[string]any) error {
-	if err := sp.applyLevelHelper2(sp.fields, tag, values); err != nil {
+// reset state of the fields before a new load.
+func (sp *structParser) reset() {
+	sp.fileFields = map[string]struct{}{}
+	for _, pfield := range sp.order {
+		pfield.isSet = false
+		pfield.source = ValueSource{}
+	}
+}
+
+// applyDefaults marks fields with a default value as set, values are assigned by parseStruct.
+// Fields already set by a source loaded before defaults (see Config.Order) get the default again.
+func (sp *structParser) applyDefaults() {
+	for _, pfield := range sp.order {
+		def := pfield.tags["default"]
+		switch {
+		case def == "":
+		case pfield.isSet:
+			sp.set(pfield, def, ValueSource{Kind: "default"})
+		default:
+			sp.markSet(pfield, ValueSource{Kind: "default"}, def)
+		}
+	}
+}
+
+func (sp *structParser) applyLevel(from ValueSource, tag string, values map[string]any) error {
+	if err := sp.applyLevelHelper2(sp.fields, from, tag, values); err != nil {
 		return err
 	}
 
-	if !sp.cfg.AllowUnknownFields {
+	if !sp.cfg.AllowUnknownFields && from.Kind != "override" {
 		for env, value := range values {
-			return fmt.Errorf("unknown field in file %q: %s=%v (see AllowUnknownFields config param)", "file", env, value)
+			return fmt.Errorf("unknown field in %s %q: %s=%v (see AllowUnknownFields config param)", from.Kind, from.File, env, value)
 		}
 	}
 	return nil
 }
 
-func (sp *structParser) applyLevelHelper2(fields map[string]any, tag string, values map[string]any) error {
+func (sp *structParser) applyLevelHelper2(fields map[string]any, from ValueSource, tag string, values map[string]any) error {
 	for _, field := range fields {
 		pfield, ok := field.(*parsedField)
 		if !ok {
@@ -401,15 +659,26 @@ func (sp *structParser) applyLevelHelper2(fields map[string]any, tag string, val
 					fmt.Printf("ouch %T (%+v)\n", pfield.value, pfield.value)
 					continue
 				}
-				err := sp.applyLevelHelper2(pfieldValue, tag, value)
+				err := sp.applyLevelHelper2(pfieldValue, from, tag, value)
 				if err != nil {
 					return err
 				}
 			} else {
-				pfield.value = value
+				// a file over a file is always merged, see Config.MergeFiles.
+				deepMerge := sp.cfg.Experimental.Has(ExperimentDeepMerge) ||
+					(from.Kind == "file" && pfield.source.isFile())
+				if old, ok := pfield.value.(map[string]any); ok && deepMerge {
+					value = mergeMaps(mergeMaps(map[string]any{}, old), value)
+				}
+				sp.setFrom(pfield, value, from, tag)
 			}
 		default:
-			pfield.value = value
+			if sp.cfg.Experimental.Has(ExperimentStrictCoercion) && !hasAdapter(sp.cfg.Adapters, pfield.field.Type) {
+				if err := checkStrictType(pfield.field.Type, value); err != nil {
+					return fmt.Errorf("field %s: %w", pfield.Name(), err)
+				}
+			}
+			sp.setFrom(pfield, value, from, tag)
 		}
 
 		delete(values, tagValue)
@@ -457,12 +726,15 @@ func (sp *structParser) applyLevelHelper(fields map[string]any, tag string, valu
 func (sp *structParser) applyFlat(tag string, values map[string]any) error {
 	allowUnknown := true
 	prefix := ""
+	unknownErr := "unknown " + tag + " %s"
 
 	switch tag {
 	case "env":
 		allowUnknown, prefix = sp.cfg.AllowUnknownEnvs, sp.cfg.EnvPrefix
+		unknownErr = "unknown environment var %s (see AllowUnknownEnvs config param)"
 	case "flag":
 		allowUnknown, prefix = sp.cfg.AllowUnknownFlags, sp.cfg.FlagPrefix
+		unknownErr = "unknown flag %s (see AllowUnknownFlags config param)"
 	}
 
 	dupls := map[string]struct{}{}
@@ -478,9 +750,9 @@ func (sp *structParser) applyFlat(tag string, values map[string]any) error {
 	for name := range dupls {
 		delete(values, name)
 	}
-	for key, value := range values {
+	for key := range values {
 		if strings.HasPrefix(key, prefix) {
-			return fmt.Errorf("unknown %s %s=%v (see AllowUnknownXXX config param)", tag, key, value)
+			return fmt.Errorf(unknownErr, key)
 		}
 	}
 	return nil
@@ -495,21 +767,20 @@ func (sp *structParser) applyFlatHelper(fields map[string]any, tag string, value
 		}
 
 		tagValue, ok := pfield.tags[tag+"_full"]
-		if !ok {
-			continue
-		}
-		value, ok := values[tagValue]
-		if !ok {
-			if !pfield.hasChilds {
+		value, found := values[tagValue]
+		if !ok || !found {
+			// children might have names even when the parent is skipped with "-".
+			childs, isMap := pfield.value.(map[string]any)
+			if !pfield.hasChilds || !isMap {
 				continue
 			}
-			if err := sp.applyFlatHelper(pfield.value.(map[string]any), tag, values); err != nil {
+			if err := sp.applyFlatHelper(childs, tag, values); err != nil {
 				return err
 			}
 			continue
 		}
 
-		pfield.value = value
+		sp.set(pfield, value, ValueSource{Kind: tag, Name: tagValue})
 		if !sp.cfg.AllowDuplicates {
 			delete(values, tagValue)
 		}
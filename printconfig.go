@@ -0,0 +1,46 @@
+package aconfig
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"text/tabwriter"
+)
+
+// redacted is printed instead of values of fields with `secret:"true"` tag.
+const redacted = "<redacted>"
+
+// printConfig prints values of the fields with their sources, one field per line.
+func (l *Loader) printConfig(w io.Writer) {
+	root := reflect.ValueOf(l.dst).Elem()
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, field := range l.allFields() {
+		_, index := fieldInfo(field)
+		value := fmt.Sprint(l.render(reflect.ValueOf(valueByIndex(root, index))))
+		if isSecret(field) {
+			value = redacted
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", field.Name(), value, field.Source())
+	}
+	tw.Flush()
+}
+
+// isSecret reports whether the field or any of its parents has `secret:"true"` tag.
+func isSecret(field Field) bool {
+	return hasTag(field, "secret", "true")
+}
+
+// hasTag reports whether the field or any of its parents has the tag with the value.
+func hasTag(field Field, tag, value string) bool {
+	for {
+		if field.Tag(tag) == value {
+			return true
+		}
+		parent, ok := field.Parent()
+		if !ok {
+			return false
+		}
+		field = parent
+	}
+}
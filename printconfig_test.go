@@ -0,0 +1,50 @@
+package aconfig
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestPrintConfigFlag(t *testing.T) {
+	type TestConfig struct {
+		Port    int    `default:"8080"`
+		Host    string `required:"true"`
+		Timeout string
+		Auth    struct {
+			User     string
+			Password string `secret:"true"`
+		}
+		Keys struct {
+			Private string
+		} `secret:"true"`
+	}
+
+	var cfg TestConfig
+	loader := LoaderFor(&cfg, Config{
+		NewParser:       newParser,
+		EnvPrefix:       "APP",
+		PrintConfigFlag: "print-config",
+		Envs:            []string{"APP_AUTH_PASSWORD=hunter2", "APP_KEYS_PRIVATE=key"},
+		Args:            []string{"-print-config", "-auth.user=admin"},
+		Files:           []string{"config.json"},
+		FileSystem: fstest.MapFS{
+			"config.json": &fstest.MapFile{Data: []byte(`{"port": 80}`)},
+		},
+	})
+	var out strings.Builder
+	loader.Flags().SetOutput(&out)
+
+	// required Host isn't checked, config is printed as is.
+	err := loader.Load()
+	mustEqual(t, errors.Is(err, ErrHelp), true)
+
+	want := "Port           80          file config.json (key port)\n" +
+		"Host                       not set\n" +
+		"Timeout                    not set\n" +
+		"Auth.User      admin       flag -auth.user\n" +
+		"Auth.Password  <redacted>  env APP_AUTH_PASSWORD\n" +
+		"Keys.Private   <redacted>  env APP_KEYS_PRIVATE\n"
+	mustEqual(t, out.String(), want)
+}
@@ -0,0 +1,54 @@
+package aconfig
+
+import (
+	"errors"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// Profile returns the selected profile: a value of Config.ProfileFlag, Config.ProfileEnv or Config.Profile,
+// the first non-empty one. Empty if no profile is selected.
+func (l *Loader) Profile() string {
+	l.stateMu.RLock()
+	defer l.stateMu.RUnlock()
+	return l.profile()
+}
+
+func (l *Loader) profile() string {
+	if l.config.ProfileFlag != "" {
+		if f := getActualFlag(l.config.ProfileFlag, l.flagSet); f != nil && f.Value.String() != "" {
+			return f.Value.String()
+		}
+	}
+	if l.config.ProfileEnv != "" {
+		if value, _ := getEnv(l.config.Envs)[l.config.ProfileEnv].(string); value != "" {
+			return value
+		}
+	}
+	return l.config.Profile
+}
+
+// profileEntry returns the profile overlay of the file: config.yaml for "prod" profile is config.prod.yaml.
+// Files from stdin and URLs don't have overlays.
+func (l *Loader) profileEntry(file FileEntry) (FileEntry, bool) {
+	profile := l.profile()
+	if profile == "" || file.Path == stdinName || urlScheme(file.Path) != "" {
+		return FileEntry{}, false
+	}
+	ext := path.Ext(file.Path)
+	overlay := strings.TrimSuffix(file.Path, ext) + "." + profile + ext
+	return FileEntry{Path: overlay, FileSystem: file.FileSystem}, true
+}
+
+// loadProfileFile loads the profile overlay of the file if it exists.
+func (l *Loader) loadProfileFile(file FileEntry) error {
+	overlay, ok := l.profileEntry(file)
+	if !ok {
+		return nil
+	}
+	if _, err := fs.Stat(overlay.FileSystem, overlay.Path); errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return l.loadFile(overlay)
+}
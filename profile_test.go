@@ -0,0 +1,67 @@
+package aconfig
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestProfileFiles(t *testing.T) {
+	type TestConfig struct {
+		Host  string
+		Port  int
+		Debug bool
+	}
+
+	fsys := fstest.MapFS{
+		"config.json":         &fstest.MapFile{Data: []byte(`{"host": "localhost", "port": 8080, "debug": true}`)},
+		"config.prod.json":    &fstest.MapFile{Data: []byte(`{"host": "example.com", "debug": false}`)},
+		"config.staging.json": &fstest.MapFile{Data: []byte(`{"host": "staging.example.com"}`)},
+	}
+
+	load := func(cfg Config) (TestConfig, string) {
+		t.Helper()
+
+		var res TestConfig
+		cfg.NewParser = newParser
+		cfg.SkipDefaults = true
+		cfg.Files = []string{"config.json"}
+		cfg.FileSystem = fsys
+		cfg.EnvPrefix = "APP"
+		cfg.ProfileEnv = "APP_PROFILE"
+		cfg.ProfileFlag = "profile"
+		if cfg.Envs == nil {
+			cfg.Envs = []string{}
+		}
+		if cfg.Args == nil {
+			cfg.Args = []string{}
+		}
+		loader := LoaderFor(&res, cfg)
+		failIfErr(t, loader.Load())
+		return res, loader.Profile()
+	}
+
+	cfg, profile := load(Config{})
+	mustEqual(t, profile, "")
+	mustEqual(t, cfg, TestConfig{Host: "localhost", Port: 8080, Debug: true})
+
+	cfg, profile = load(Config{Profile: "prod"})
+	mustEqual(t, profile, "prod")
+	mustEqual(t, cfg, TestConfig{Host: "example.com", Port: 8080, Debug: false})
+
+	cfg, profile = load(Config{Profile: "prod", Envs: []string{"APP_PROFILE=staging"}})
+	mustEqual(t, profile, "staging")
+	mustEqual(t, cfg, TestConfig{Host: "staging.example.com", Port: 8080, Debug: true})
+
+	cfg, profile = load(Config{
+		Profile: "staging",
+		Envs:    []string{"APP_PROFILE=staging"},
+		Args:    []string{"-profile=prod"},
+	})
+	mustEqual(t, profile, "prod")
+	mustEqual(t, cfg, TestConfig{Host: "example.com", Port: 8080, Debug: false})
+
+	// overlay is optional.
+	cfg, profile = load(Config{Profile: "dev"})
+	mustEqual(t, profile, "dev")
+	mustEqual(t, cfg, TestConfig{Host: "localhost", Port: 8080, Debug: true})
+}
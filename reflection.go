@@ -4,6 +4,7 @@ import (
 	"encoding"
 	"fmt"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -16,7 +17,17 @@ type fieldData struct {
 	value      reflect.Value
 	isSet      bool
 	isRequired bool
+	allowEmpty bool
 	tags       map[string]string
+	source     ValueSource
+
+	// typedDefault is the default value parsed into the field type, see DefaultValue.
+	typedDefault any
+
+	// index of the field in the destination struct, see reflect.Value.FieldByIndex.
+	index []int
+	// fullNames are precomputed names for env, flag and file formats. See fieldName.
+	fullNames map[string]string
 }
 
 func (f *fieldData) Name() string {
@@ -34,6 +45,123 @@ func (f *fieldData) Parent() (Field, bool) {
 	return f.parent, f.parent != nil
 }
 
+func (f *fieldData) Source() ValueSource {
+	return f.source
+}
+
+func (f *fieldData) DefaultValue() any {
+	return f.typedDefault
+}
+
+func (f *fieldData) EnumValues() []string {
+	return enumValues(f)
+}
+
+func (f *fieldData) AllTags() map[string]string {
+	return allTags(f.field.Tag, f.tags)
+}
+
+// enumValues returns values of `oneof` tag of the field.
+func enumValues(f Field) []string {
+	values := strings.Fields(f.Tag("oneof"))
+	if len(values) == 0 {
+		return nil
+	}
+	return values
+}
+
+// allTags returns struct tags with generated tags over them.
+func allTags(tag reflect.StructTag, generated map[string]string) map[string]string {
+	res := parseStructTag(tag)
+	for key, value := range generated {
+		res[key] = value
+	}
+	return res
+}
+
+// parseStructTag returns all key:"value" pairs of the tag, parsed the same way as reflect.StructTag.Lookup does.
+func parseStructTag(tag reflect.StructTag) map[string]string {
+	res := map[string]string{}
+	for tag != "" {
+		// skip leading space.
+		i := 0
+		for i < len(tag) && tag[i] == ' ' {
+			i++
+		}
+		tag = tag[i:]
+		if tag == "" {
+			break
+		}
+
+		// name is a sequence of non-control characters other than space, quote and colon.
+		i = 0
+		for i < len(tag) && tag[i] > ' ' && tag[i] != ':' && tag[i] != '"' && tag[i] != 0x7f {
+			i++
+		}
+		if i == 0 || i+1 >= len(tag) || tag[i] != ':' || tag[i+1] != '"' {
+			break
+		}
+		name := string(tag[:i])
+		tag = tag[i+1:]
+
+		// quoted string value.
+		i = 1
+		for i < len(tag) && tag[i] != '"' {
+			if tag[i] == '\\' {
+				i++
+			}
+			i++
+		}
+		if i >= len(tag) {
+			break
+		}
+		qvalue := string(tag[:i+1])
+		tag = tag[i+1:]
+
+		if _, ok := res[name]; ok {
+			continue // the first one wins, as in Lookup.
+		}
+		if value, err := strconv.Unquote(qvalue); err == nil {
+			res[name] = value
+		}
+	}
+	return res
+}
+
+// isProvided reports whether value should be treated as set for the field.
+// Empty string counts only when field has `aconfig:",allowempty"` tag.
+func (f *fieldData) isProvided(value any) bool {
+	return value != "" || f.allowEmpty
+}
+
+// markSet marks the field as set by the source and notifies Config.OnFieldSet.
+func (l *Loader) markSet(field *fieldData, source ValueSource, raw any) {
+	field.isSet = true
+	field.source = source
+	if l.config.OnFieldSet != nil {
+		l.config.OnFieldSet(field, source.Kind, raw)
+	}
+}
+
+// setFieldValue sets a single field from the source with any parser.
+func (l *Loader) setFieldValue(f Field, value any, source ValueSource) error {
+	switch f := f.(type) {
+	case *parsedField:
+		l.parser.set(f, value, source)
+		return nil
+	case *fieldData:
+		if err := l.setFieldData(f, value); err != nil {
+			return err
+		}
+		if f.isProvided(value) {
+			l.markSet(f, source, value)
+		}
+		return nil
+	default:
+		panic(fmt.Sprintf("unexpected field type %T", f))
+	}
+}
+
 func (l *Loader) newSimpleFieldData(value reflect.Value) *fieldData {
 	return l.newFieldData(reflect.StructField{}, value, nil)
 }
@@ -44,23 +172,30 @@ func (l *Loader) newFieldData(field reflect.StructField, value reflect.Value, pa
 		panic(fmt.Sprintf("aconfig: incorrect value for 'required' tag: %v", requiredTag))
 	}
 
+	_, opts := parseAconfigTag(field.Tag.Get("aconfig"))
+
 	fd := &fieldData{
-		name:       makeName(field.Name, parent),
 		parent:     parent,
 		value:      value,
 		field:      field,
 		isSet:      false,
 		isRequired: requiredTag == "true",
-		tags:       l.tagsForField(field),
+		allowEmpty: opts["allowempty"],
+	}
+
+	// helper fields for slice items and map entries do not need names.
+	if field.Name != "" {
+		fd.name = makeName(field.Name, parent)
+		fd.tags = l.tagsForField(field)
 	}
 	return fd
 }
 
 func (l *Loader) tagsForField(field reflect.StructField) map[string]string {
-	words := splitNameByWords(field.Name)
+	words := l.config.WordSplit.Split(field.Name)
 
 	tags := map[string]string{
-		"default": field.Tag.Get("default"),
+		"default": defaultTag(field, l.config.Environment),
 		"usage":   field.Tag.Get("usage"),
 
 		"env":  l.makeTagValue(field, "env", words),
@@ -73,6 +208,68 @@ func (l *Loader) tagsForField(field reflect.StructField) map[string]string {
 	return tags
 }
 
+// fieldName returns the full name of the field for the tag, with env or flag prefix if needed.
+// Names for env, flag and file formats are precomputed on init, see cacheNames.
+func (l *Loader) fieldName(f *fieldData, tag string) string {
+	if name, ok := f.fullNames[tag]; ok {
+		return name
+	}
+	return l.fullTag(l.tagPrefix(tag), f, tag)
+}
+
+func (l *Loader) tagPrefix(tag string) string {
+	switch tag {
+	case "env":
+		return l.config.EnvPrefix
+	case "flag":
+		return l.config.FlagPrefix
+	default:
+		return ""
+	}
+}
+
+// cacheNames precomputes full names of the fields, so they aren't rebuilt on every load.
+func (l *Loader) cacheNames(fields []*fieldData) {
+	tags := make([]string, 0, 2+len(l.config.FileDecoders))
+	tags = append(tags, "env", "flag")
+	for _, dec := range l.config.FileDecoders {
+		tags = append(tags, dec.Format())
+	}
+
+	for _, field := range fields {
+		field.fullNames = make(map[string]string, len(tags))
+		for _, tag := range tags {
+			field.fullNames[tag] = l.fullTag(l.tagPrefix(tag), field, tag)
+		}
+	}
+}
+
+// rebindFields returns fields with the same metadata but bound to another destination of the same type.
+func (l *Loader) rebindFields(dst any) []*fieldData {
+	root := reflect.ValueOf(dst).Elem()
+
+	fields := make([]*fieldData, len(l.fields))
+	for i, field := range l.fields {
+		value := root
+		for j, idx := range field.index {
+			if j > 0 && value.Kind() == reflect.Ptr {
+				if value.IsNil() {
+					value.Set(reflect.New(value.Type().Elem()))
+				}
+				value = value.Elem()
+			}
+			value = value.Field(idx)
+		}
+
+		fd := *field
+		fd.value = value
+		fd.isSet = false
+		fd.source = ValueSource{}
+		fields[i] = &fd
+	}
+	return fields
+}
+
 func (l *Loader) fullTag(prefix string, f *fieldData, tag string) string {
 	sep := "."
 	if tag == "flag" {
@@ -99,15 +296,279 @@ func (l *Loader) fullTag(prefix string, f *fieldData, tag string) string {
 	return prefix + res
 }
 
+// allFields returns leaf fields of the structure for both parsers.
+func (l *Loader) allFields() []Field {
+	if l.config.NewParser {
+		if l.parser == nil {
+			return nil
+		}
+		fields := make([]Field, len(l.parser.order))
+		for i, pfield := range l.parser.order {
+			fields[i] = pfield
+		}
+		return fields
+	}
+
+	fields := make([]Field, len(l.fields))
+	for i, field := range l.fields {
+		fields[i] = field
+	}
+	return fields
+}
+
+// sourceName returns the full name of the field for the tag, see fieldName.
+func (l *Loader) sourceName(f Field, tag string) string {
+	switch f := f.(type) {
+	case *fieldData:
+		return l.fieldName(f, tag)
+	case *parsedField:
+		return f.fullName(tag)
+	default:
+		return ""
+	}
+}
+
+func (l *Loader) findDuplicates() []Duplicate {
+	var sources []string
+	if !l.config.SkipEnv {
+		sources = append(sources, "env")
+	}
+	if !l.config.SkipFlags {
+		sources = append(sources, "flag")
+	}
+	if !l.config.SkipFiles {
+		seen := map[string]bool{}
+		for _, dec := range l.config.FileDecoders {
+			// decoders for .yaml and .yml have the same format.
+			if format := dec.Format(); !seen[format] {
+				seen[format] = true
+				sources = append(sources, format)
+			}
+		}
+	}
+
+	var res []Duplicate
+	for _, source := range sources {
+		names := map[string][]string{}
+		for _, field := range l.allFields() {
+			name := l.sourceName(field, source)
+			if name == "" {
+				continue
+			}
+			names[name] = append(names[name], field.Name())
+		}
+		for name, fields := range names {
+			if len(fields) < 2 {
+				continue
+			}
+			res = append(res, Duplicate{Source: source, Name: name, Fields: fields})
+		}
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Source != res[j].Source {
+			return res[i].Source < res[j].Source
+		}
+		return res[i].Name < res[j].Name
+	})
+	return res
+}
+
+// applyFactories replaces loaded data of interface fields with values created by Config.Factories.
+func (l *Loader) applyFactories() error {
+	root := reflect.ValueOf(l.dst).Elem()
+	for _, f := range l.allFields() {
+		field, index := fieldInfo(f)
+		sibling := field.Tag.Get("factory")
+		if sibling == "" || field.Type.Kind() != reflect.Interface {
+			continue
+		}
+
+		parent := settableByIndex(root, index[:len(index)-1])
+		kindValue := parent.FieldByName(sibling)
+		if !kindValue.IsValid() {
+			return fmt.Errorf("field %s: no sibling field %q for factory", f.Name(), sibling)
+		}
+		kind := fmt.Sprint(kindValue.Interface())
+		if kind == "" {
+			continue
+		}
+
+		factory, ok := l.config.Factories[field.Type]
+		if !ok {
+			return fmt.Errorf("field %s: no factory for %s", f.Name(), field.Type)
+		}
+		v, err := factory(kind)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", f.Name(), err)
+		}
+
+		rv := reflect.ValueOf(v)
+		if !rv.IsValid() || !rv.Type().Implements(field.Type) {
+			return fmt.Errorf("field %s: factory value %T doesn't implement %s", f.Name(), v, field.Type)
+		}
+
+		value := parent.Field(index[len(index)-1])
+		if data, ok := l.factoryData[f.Name()].(map[string]any); ok && rv.Kind() == reflect.Ptr {
+			if err := l.m2s(data, rv.Elem()); err != nil {
+				return fmt.Errorf("field %s: %w", f.Name(), err)
+			}
+		}
+		value.Set(rv)
+	}
+	return nil
+}
+
+func isFieldSet(f Field) bool {
+	switch f := f.(type) {
+	case *fieldData:
+		return f.isSet
+	case *parsedField:
+		return f.isSet
+	default:
+		return false
+	}
+}
+
+func isFieldRequired(f Field) bool {
+	switch f := f.(type) {
+	case *fieldData:
+		return f.isRequired
+	case *parsedField:
+		return f.isRequired
+	default:
+		return false
+	}
+}
+
+// fieldInfo returns struct field and its index in the destination for both parsers.
+func fieldInfo(f Field) (reflect.StructField, []int) {
+	switch f := f.(type) {
+	case *fieldData:
+		return f.field, f.index
+	case *parsedField:
+		return f.field, f.index
+	default:
+		return reflect.StructField{}, nil
+	}
+}
+
+// settableByIndex returns nested field allocating nil structs on the path.
+func settableByIndex(value reflect.Value, index []int) reflect.Value {
+	for _, idx := range index {
+		if value.Kind() == reflect.Ptr {
+			if value.IsNil() {
+				value.Set(reflect.New(value.Type().Elem()))
+			}
+			value = value.Elem()
+		}
+		value = value.Field(idx)
+	}
+	if value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			value.Set(reflect.New(value.Type().Elem()))
+		}
+		value = value.Elem()
+	}
+	return value
+}
+
+// validateFields calls Config.FieldValidator for every field with its final value.
+func (l *Loader) validateFields() error {
+	if l.config.FieldValidator == nil {
+		return nil
+	}
+
+	root := reflect.ValueOf(l.dst).Elem()
+	for _, field := range l.allFields() {
+		_, index := fieldInfo(field)
+		if err := l.config.FieldValidator(field, valueByIndex(root, index)); err != nil {
+			return fmt.Errorf("validate field %s: %w", field.Name(), err)
+		}
+	}
+	return nil
+}
+
+// valueByIndex returns value of the nested field or zero value if a struct on the path is nil.
+func valueByIndex(value reflect.Value, index []int) any {
+	for i, idx := range index {
+		if i > 0 && value.Kind() == reflect.Ptr {
+			if value.IsNil() {
+				typ, rest := value.Type().Elem(), index[i:]
+				for j, idx := range rest {
+					typ = typ.Field(idx).Type
+					if j < len(rest)-1 && typ.Kind() == reflect.Ptr {
+						typ = typ.Elem()
+					}
+				}
+				return reflect.Zero(typ).Interface()
+			}
+			value = value.Elem()
+		}
+		value = value.Field(idx)
+	}
+	return value.Interface()
+}
+
+// validateStruct calls Validate for nested structures first and then for the value itself.
+// Embedded structures are not validated on their own, their Validate is promoted to the parent.
+func validateStruct(value reflect.Value, path string, self bool) error {
+	typ := value.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if field.PkgPath != "" || isIgnored(field) {
+			continue
+		}
+
+		fieldValue := value.Field(i)
+		if fieldValue.Kind() == reflect.Ptr {
+			if fieldValue.IsNil() {
+				continue
+			}
+			fieldValue = fieldValue.Elem()
+		}
+		if fieldValue.Kind() != reflect.Struct {
+			continue
+		}
+
+		fieldPath := field.Name
+		if field.Anonymous {
+			fieldPath = path
+		} else if path != "" {
+			fieldPath = path + "." + field.Name
+		}
+		if err := validateStruct(fieldValue, fieldPath, !field.Anonymous); err != nil {
+			return err
+		}
+	}
+	if !self {
+		return nil
+	}
+
+	v, ok := value.Addr().Interface().(Validator)
+	if !ok {
+		return nil
+	}
+	if err := v.Validate(); err != nil {
+		if path == "" {
+			return fmt.Errorf("validate: %w", err)
+		}
+		return fmt.Errorf("validate %s: %w", path, err)
+	}
+	return nil
+}
+
 func (l *Loader) getFields(x interface{}) []*fieldData {
 	value := reflect.ValueOf(x)
 	for value.Type().Kind() == reflect.Ptr {
 		value = value.Elem()
 	}
-	return l.getFieldsHelper(value, nil)
+	fields := l.getFieldsHelper(value, nil, nil)
+	l.cacheNames(fields)
+	return fields
 }
 
-func (l *Loader) getFieldsHelper(valueObject reflect.Value, parent *fieldData) []*fieldData {
+func (l *Loader) getFieldsHelper(valueObject reflect.Value, parent *fieldData, index []int) []*fieldData {
 	typeObject := valueObject.Type()
 	count := valueObject.NumField()
 
@@ -116,18 +577,19 @@ func (l *Loader) getFieldsHelper(valueObject reflect.Value, parent *fieldData) [
 		value := valueObject.Field(i)
 		field := typeObject.Field(i)
 
-		if !value.CanSet() {
+		if !value.CanSet() || isIgnored(field) {
 			continue
 		}
 
 		fd := l.newFieldData(field, value, parent)
+		fd.index = append(index[:len(index):len(index)], i)
 
 		// if it's a struct - expand and process it's fields
-		kind := field.Type.Kind()
-		if kind == reflect.Ptr {
-			kind = field.Type.Elem().Kind()
+		typ := field.Type
+		if typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
 		}
-		if kind == reflect.Struct {
+		if typ.Kind() == reflect.Struct && !isDynamicType(typ) && typ != timeType && !hasAdapter(l.config.Adapters, typ) {
 			var subFieldParent *fieldData
 			if field.Anonymous {
 				subFieldParent = parent
@@ -135,10 +597,12 @@ func (l *Loader) getFieldsHelper(valueObject reflect.Value, parent *fieldData) [
 				subFieldParent = fd
 			}
 			if field.Type.Kind() == reflect.Ptr {
-				value.Set(reflect.New(field.Type.Elem()))
+				if value.IsNil() {
+					value.Set(reflect.New(field.Type.Elem()))
+				}
 				value = value.Elem()
 			}
-			fields = append(fields, l.getFieldsHelper(value, subFieldParent)...)
+			fields = append(fields, l.getFieldsHelper(value, subFieldParent, fd.index)...)
 			continue
 		}
 		fields = append(fields, fd)
@@ -150,6 +614,11 @@ func (l *Loader) setFieldData(field *fieldData, value interface{}) error {
 	if value == nil {
 		return nil
 	}
+	if value != "" {
+		if ok, err := l.setAdapted(field, value); ok {
+			return err
+		}
+	}
 
 	// unwrap pointers
 	for field.value.Type().Kind() == reflect.Ptr {
@@ -160,9 +629,16 @@ func (l *Loader) setFieldData(field *fieldData, value interface{}) error {
 	}
 
 	if value == "" {
+		if field.allowEmpty {
+			field.value.Set(reflect.Zero(field.value.Type()))
+		}
 		return nil
 	}
 
+	if field.value.Type() == timeType {
+		return l.setTime(field, value)
+	}
+
 	if field.value.CanAddr() {
 		pv := field.value.Addr().Interface()
 		if v, ok := pv.(encoding.TextUnmarshaler); ok {
@@ -170,6 +646,12 @@ func (l *Loader) setFieldData(field *fieldData, value interface{}) error {
 		}
 	}
 
+	if field.isSet {
+		if merge := sliceMergeOf(field.field, l.config.SliceMerge); merge != SliceReplace {
+			return l.mergeSlice(field, value, merge)
+		}
+	}
+
 	switch kind := field.value.Type().Kind(); kind {
 	case reflect.Bool:
 		return l.setBool(field, fmt.Sprint(value))
@@ -190,11 +672,20 @@ func (l *Loader) setFieldData(field *fieldData, value interface{}) error {
 		return l.setFloat(field, fmt.Sprint(value))
 
 	case reflect.Interface:
+		if field.field.Tag.Get("factory") != "" {
+			// value is decoded later into the value created by a factory.
+			l.factoryData[field.name] = value
+			return nil
+		}
 		return l.setInterface(field, value)
 
 	case reflect.Struct:
+		m, ok := asMap(value)
+		if !ok {
+			return fmt.Errorf("value must be an object, got %T", value)
+		}
 		fd := l.newFieldData(reflect.StructField{}, field.value, nil)
-		return l.m2s(mii(value), fd.value)
+		return l.m2s(m, fd.value)
 
 	case reflect.Slice:
 		if isPrimitive(field.field.Type.Elem()) {
@@ -240,6 +731,9 @@ func (l *Loader) setFieldData(field *fieldData, value interface{}) error {
 
 			mapp.SetMapIndex(fdk.value, fdv.value)
 		}
+		if l.config.Experimental.Has(ExperimentDeepMerge) {
+			mergeMapValue(field.value, mapp)
+		}
 		field.value.Set(mapp)
 		return nil
 
@@ -267,8 +761,8 @@ func (*Loader) setInt(field *fieldData, value string) error {
 }
 
 func (l *Loader) setInt64(field *fieldData, value string) error {
-	if field.field.Type == reflect.TypeOf(time.Second) {
-		val, err := time.ParseDuration(value)
+	if field.value.Type() == reflect.TypeOf(time.Second) {
+		val, err := parseDuration(value, field.field.Tag.Get("unit"))
 		if err != nil {
 			return err
 		}
@@ -278,6 +772,20 @@ func (l *Loader) setInt64(field *fieldData, value string) error {
 	return l.setInt(field, value)
 }
 
+// setTime sets time.Time field, values decoded by files as time are set as is.
+func (*Loader) setTime(field *fieldData, value interface{}) error {
+	if t, ok := value.(time.Time); ok {
+		field.value.Set(reflect.ValueOf(t))
+		return nil
+	}
+	val, err := parseTime(fmt.Sprint(value), field.field.Tag.Get("layout"))
+	if err != nil {
+		return err
+	}
+	field.value.Set(reflect.ValueOf(val))
+	return nil
+}
+
 func (*Loader) setUint(field *fieldData, value string) error {
 	val, err := strconv.ParseUint(value, 0, field.value.Type().Bits())
 	if err != nil {
@@ -353,6 +861,9 @@ func (l *Loader) setMap(field *fieldData, value string) error {
 		}
 		mapField.SetMapIndex(fdk.value, fdv.value)
 	}
+	if l.config.Experimental.Has(ExperimentDeepMerge) {
+		mergeMapValue(field.value, mapField)
+	}
 	field.value.Set(mapField)
 	return nil
 }
@@ -386,7 +897,8 @@ func mii(m interface{}) map[string]interface{} {
 	case map[interface{}]interface{}:
 		res := map[string]interface{}{}
 		for k, v := range m {
-			res[k.(string)] = v
+			// YAML allows keys of any type, like `1: x`.
+			res[fmt.Sprint(k)] = v
 		}
 		return res
 	default:
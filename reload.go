@@ -0,0 +1,364 @@
+package aconfig
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/signal"
+	"reflect"
+	"sync/atomic"
+	"time"
+)
+
+// Watch checks config files every Config.WatchInterval and reloads the configuration when any of them changes.
+// Files are Config.Files, Config.FileEntries, Config.FileGroups and a file passed via Config.FileFlag.
+// HTTP files and stdin aren't checked, use Reload or a Source with SourceWatcher for remote configs.
+// Sources from Config.Sources that implement SourceWatcher trigger a reload on their changes.
+// If watching a source fails, onChange is called with the error and the source isn't watched anymore,
+// unless Config.WatchBackoff is set. Config.OnWatchState reports states of the sources.
+// Fields loaded with a Lease are loaded again after 2/3 of TTL, other fields aren't reloaded for that,
+// onChange is called with the result. Leases are checked every Config.WatchInterval.
+//
+// Configuration is loaded into a fresh copy of the destination and copied into it only on success,
+// otherwise destination is left untouched. onChange is called after each reload with its result.
+// Read the destination inside of View to never observe a partially copied configuration.
+//
+// Watch blocks until ctx is done, so usually it's run in a separate goroutine.
+func (l *Loader) Watch(ctx context.Context, onChange func(error)) {
+	interval := l.config.WatchInterval
+	if interval <= 0 {
+		interval = time.Second
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	changed, failed := l.watchSources(ctx)
+
+	snapshot := l.filesSnapshot()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case err := <-failed:
+			if onChange != nil {
+				onChange(err)
+			}
+			continue
+		case <-changed:
+		case <-ticker.C:
+			if l.leasesDue(time.Now()) {
+				err := l.refreshLeases(ctx)
+				if onChange != nil {
+					onChange(err)
+				}
+			}
+			current := l.filesSnapshot()
+			if reflect.DeepEqual(snapshot, current) {
+				continue
+			}
+			snapshot = current
+		}
+
+		err := l.reload()
+		if onChange != nil {
+			onChange(err)
+		}
+	}
+}
+
+// ReloadOn reloads the configuration each time the process receives one of the given signals.
+// The usual one for Unix daemons is syscall.SIGHUP. Does nothing if no signals are given.
+//
+// Reload works the same way as in Watch: destination is updated only when the whole load succeeds.
+// onReload is called after each reload with its result.
+//
+// ReloadOn blocks until ctx is done, so usually it's run in a separate goroutine.
+func (l *Loader) ReloadOn(ctx context.Context, onReload func(error), sig ...os.Signal) {
+	if len(sig) == 0 {
+		return
+	}
+
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, sig...)
+	defer signal.Stop(ch)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ch:
+		}
+
+		err := l.reload()
+		if onReload != nil {
+			onReload(err)
+		}
+	}
+}
+
+// watchSources starts watching sources that implement SourceWatcher.
+// Changes are coalesced, so a burst of notifications results in a single reload.
+func (l *Loader) watchSources(ctx context.Context) (<-chan struct{}, <-chan error) {
+	changed := make(chan struct{}, 1)
+	failed := make(chan error)
+
+	for _, src := range l.config.Sources {
+		w, ok := src.(SourceWatcher)
+		if !ok {
+			continue
+		}
+		go l.watchSource(ctx, w, sourceName(src), changed, failed)
+	}
+	return changed, failed
+}
+
+// watchSource watches the source and restarts it after failures with a backoff. See Config.WatchBackoff.
+func (l *Loader) watchSource(ctx context.Context, w SourceWatcher, name string, changed chan<- struct{}, failed chan<- error) {
+	defer l.watchState(name, WatchStopped, nil)
+
+	maxBackoff := l.config.WatchMaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = time.Minute
+	}
+	backoff := l.config.WatchBackoff
+
+	for {
+		var notified int32
+		l.watchState(name, WatchStarted, nil)
+		err := w.Watch(ctx, func() {
+			atomic.StoreInt32(&notified, 1)
+			select {
+			case changed <- struct{}{}:
+			default:
+			}
+		})
+		if err == nil || ctx.Err() != nil {
+			return
+		}
+		l.watchState(name, WatchFailed, err)
+
+		select {
+		case failed <- fmt.Errorf("watch source %s: %w", name, err):
+		case <-ctx.Done():
+			return
+		}
+
+		if l.config.WatchBackoff <= 0 {
+			return
+		}
+		if atomic.LoadInt32(&notified) == 1 {
+			backoff = l.config.WatchBackoff
+		}
+		if !sleepContext(ctx, backoff) {
+			return
+		}
+		if backoff *= 2; backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+func (l *Loader) watchState(name string, state WatchState, err error) {
+	if l.config.OnWatchState != nil {
+		l.config.OnWatchState(name, state, err)
+	}
+}
+
+// sleepContext waits for d and reports whether it wasn't interrupted by ctx.
+// Returns false right away if ctx deadline is closer than d.
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < d {
+		return false
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// filesSnapshot returns hashes of the config files, missing files have empty hash.
+// HTTP files and stdin are skipped, otherwise they would be fetched on every tick.
+func (l *Loader) filesSnapshot() map[string][sha256.Size]byte {
+	files, err := l.fileEntries()
+	if err != nil {
+		return nil
+	}
+	for _, file := range files {
+		if overlay, ok := l.profileEntry(file); ok {
+			files = append(files, overlay)
+		}
+	}
+	dirFiles, _, err := l.dirFiles()
+	if err != nil {
+		return nil
+	}
+	for _, file := range dirFiles {
+		files = append(files, FileEntry{Path: file, FileSystem: l.fsys})
+	}
+	for _, group := range l.config.FileGroups {
+		for _, file := range group {
+			files = append(files, FileEntry{Path: file, FileSystem: l.fsys})
+		}
+	}
+	if l.config.OverrideFile != "" {
+		files = append(files, FileEntry{Path: l.config.OverrideFile, FileSystem: l.fsys})
+	}
+
+	res := make(map[string][sha256.Size]byte, len(files))
+	for _, file := range files {
+		if file.Path == stdinName || urlScheme(file.Path) != "" {
+			continue
+		}
+		data, err := fs.ReadFile(file.FileSystem, file.Path)
+		if err != nil {
+			res[file.Path] = [sha256.Size]byte{}
+			continue
+		}
+		res[file.Path] = sha256.Sum256(data)
+	}
+	return res
+}
+
+// View calls fn while the destination can't be changed by a reload.
+// A reload applies all the changes at once, so inside of fn the destination is either
+// the old or the new configuration, never a mix of both. Keep fn short, it blocks reloads.
+func (l *Loader) View(fn func()) {
+	l.swapMu.RLock()
+	defer l.swapMu.RUnlock()
+	fn()
+}
+
+// Reload loads the configuration again from all the sources, like Watch and ReloadOn do on changes.
+// Sources are loaded into a fresh copy of the destination, required fields, constraints and validators
+// are checked on it, only then it's copied into the destination. On error the destination is left untouched.
+// Fields with `reload:"false"` tag keep their values, see Config.OnRestartRequired.
+func (l *Loader) Reload() error {
+	if l.errInit != nil {
+		return fmt.Errorf("init loader: %w", l.errInit)
+	}
+	return l.reload()
+}
+
+// reload loads configuration into a fresh copy of the destination and on success copies it into the destination.
+// Reloads are serialized, so Watch and ReloadOn can be used together.
+func (l *Loader) reload() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	fresh := reflect.New(reflect.TypeOf(l.dst).Elem())
+
+	nl := l.clone(fresh.Interface())
+	if err := nl.Load(); err != nil {
+		return err
+	}
+
+	kept := l.keepRestartFields(fresh.Elem())
+	var changes []FieldChange
+	if l.config.OnChange != nil {
+		changes = l.diff(reflect.ValueOf(l.dst).Elem(), fresh.Elem(), nl.allFields())
+	}
+
+	// readers in View see either the old or the new configuration, never a mix.
+	l.swapMu.Lock()
+	setConfig(reflect.ValueOf(l.dst).Elem(), fresh.Elem())
+	l.swapMu.Unlock()
+	l.publish(fresh)
+	l.adopt(nl)
+
+	if len(kept) != 0 && l.config.OnRestartRequired != nil {
+		l.config.OnRestartRequired(kept)
+	}
+	l.notifyChanges(changes)
+	return nil
+}
+
+// keepRestartFields copies values of fields with `reload:"false"` tag from the destination into fresh.
+// Returns names of the fields whose new values weren't applied.
+func (l *Loader) keepRestartFields(fresh reflect.Value) []string {
+	root := reflect.ValueOf(l.dst).Elem()
+
+	var kept []string
+	for _, field := range l.allFields() {
+		if !hasTag(field, "reload", "false") {
+			continue
+		}
+		sf, index := fieldInfo(field)
+		curr := valueByIndex(root, index)
+		if l.equal(curr, valueByIndex(fresh, index)) {
+			continue
+		}
+
+		value := reflect.Zero(sf.Type)
+		if curr != nil {
+			value = reflect.ValueOf(curr)
+		}
+		settableByIndex(fresh, index[:len(index)-1]).Field(index[len(index)-1]).Set(value)
+		kept = append(kept, field.Name())
+	}
+	return kept
+}
+
+// clone returns a new loader for dst with the same configuration.
+// Flags are shared, so values parsed by the original loader are reused.
+func (l *Loader) clone(dst any) *Loader {
+	nl := &Loader{
+		dst:    dst,
+		config: l.base,
+		base:   l.base,
+	}
+	// envs aren't copied, so a reload sees the current environment.
+	if nl.config.Args == nil {
+		nl.config.Args = l.config.Args
+	}
+
+	l.stateMu.RLock()
+	defer l.stateMu.RUnlock()
+
+	if !l.config.NewParser {
+		// reuse fields metadata, only values are new.
+		nl.fields = l.rebindFields(dst)
+	}
+	nl.init()
+	nl.flagSet = l.flagSet
+	nl.urls = l.urls
+	nl.stdin = l.stdin
+	nl.added = l.added
+	return nl
+}
+
+// adopt takes the state of the last load from another loader.
+// Fields are re-bound to the destination of l.
+func (l *Loader) adopt(nl *Loader) {
+	l.stateMu.Lock()
+	defer l.stateMu.Unlock()
+
+	if !l.config.NewParser {
+		fields := l.rebindFields(l.dst)
+		for i, field := range fields {
+			field.isSet = nl.fields[i].isSet
+			field.source = nl.fields[i].source
+		}
+		l.fields = fields
+	} else {
+		// parser state isn't bound to dst, so it can be taken as is.
+		l.parser = nl.parser
+	}
+
+	l.inputs = nl.inputs
+	l.unknownKeys = nl.unknownKeys
+	l.fileFields = nl.fileFields
+	l.loadedFiles = nl.loadedFiles
+	l.missingFiles = nl.missingFiles
+	l.leases = nl.leases
+	l.timings = nl.timings
+	l.loadDuration = nl.loadDuration
+}
@@ -0,0 +1,431 @@
+package aconfig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWatch(t *testing.T) {
+	type TestConfig struct {
+		Str  string `default:"str-def"`
+		Port int
+		Sub  *struct {
+			Float float64
+		}
+	}
+
+	file := filepath.Join(t.TempDir(), "config.json")
+	writeFile(t, file, `{"port": 1111, "sub": {"float": 1.5}}`)
+
+	var cfg TestConfig
+	loader := LoaderFor(&cfg, Config{
+		SkipEnv:       true,
+		SkipFlags:     true,
+		Files:         []string{file},
+		WatchInterval: 10 * time.Millisecond,
+	})
+	failIfErr(t, loader.Load())
+	mustEqual(t, cfg.Port, 1111)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	changes := make(chan error, 1)
+	go loader.Watch(ctx, func(err error) {
+		changes <- err
+	})
+
+	// let the watcher take the first snapshot.
+	time.Sleep(50 * time.Millisecond)
+
+	writeFile(t, file, `{"port": 2222, "sub": {"float": 2.5}}`)
+	failIfErr(t, waitChange(t, changes))
+
+	mustEqual(t, cfg.Str, "str-def")
+	mustEqual(t, cfg.Port, 2222)
+	mustEqual(t, cfg.Sub.Float, 2.5)
+
+	writeFile(t, file, `{"port": "not a number"}`)
+	failIfOk(t, waitChange(t, changes))
+	mustEqual(t, cfg.Port, 2222)
+}
+
+func TestWatchSource(t *testing.T) {
+	type TestConfig struct {
+		Port int
+	}
+
+	src := &watchedSource{
+		values:  map[string]any{"port": 1111},
+		changes: make(chan struct{}),
+	}
+
+	var cfg TestConfig
+	loader := LoaderFor(&cfg, Config{
+		SkipFiles: true,
+		SkipEnv:   true,
+		SkipFlags: true,
+		Sources:   []Source{src},
+	})
+	failIfErr(t, loader.Load())
+	mustEqual(t, cfg.Port, 1111)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	changes := make(chan error, 1)
+	go loader.Watch(ctx, func(err error) {
+		changes <- err
+	})
+
+	src.set(map[string]any{"port": 2222})
+	failIfErr(t, waitChange(t, changes))
+	mustEqual(t, cfg.Port, 2222)
+
+	close(src.changes)
+	err := waitChange(t, changes)
+	mustEqual(t, err.Error(), "watch source *aconfig.watchedSource: watch stopped")
+}
+
+func TestWatchSourceBackoff(t *testing.T) {
+	type TestConfig struct {
+		Port int
+	}
+
+	var mu sync.Mutex
+	var states []string
+
+	var cfg TestConfig
+	loader := LoaderFor(&cfg, Config{
+		SkipFiles:       true,
+		SkipEnv:         true,
+		SkipFlags:       true,
+		Sources:         []Source{&flakySource{fails: 2}},
+		WatchBackoff:    time.Millisecond,
+		WatchMaxBackoff: 5 * time.Millisecond,
+		OnWatchState: func(source string, state WatchState, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			states = append(states, state.String())
+		},
+	})
+	failIfErr(t, loader.Load())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	changes := make(chan error, 1)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		loader.Watch(ctx, func(err error) {
+			changes <- err
+		})
+	}()
+
+	for i := 0; i < 2; i++ {
+		err := waitChange(t, changes)
+		mustEqual(t, err.Error(), "watch source *aconfig.flakySource: connection refused")
+	}
+	failIfErr(t, waitChange(t, changes))
+
+	cancel()
+	<-done
+
+	// the source goroutine reports the last state after Watch returns.
+	time.Sleep(20 * time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	mustEqual(t, states, []string{"started", "failed", "started", "failed", "started", "stopped"})
+}
+
+func TestSleepContext(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	mustEqual(t, sleepContext(ctx, time.Hour), false)
+	mustEqual(t, sleepContext(context.Background(), time.Millisecond), true)
+}
+
+type watchedSource struct {
+	mu      sync.Mutex
+	values  map[string]any
+	changes chan struct{}
+}
+
+func (s *watchedSource) set(values map[string]any) {
+	s.mu.Lock()
+	s.values = values
+	s.mu.Unlock()
+	s.changes <- struct{}{}
+}
+
+func (s *watchedSource) Load(ctx context.Context) (map[string]any, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.values, nil
+}
+
+func (s *watchedSource) Watch(ctx context.Context, notify func()) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case _, ok := <-s.changes:
+			if !ok {
+				return errors.New("watch stopped")
+			}
+			notify()
+		}
+	}
+}
+
+// flakySource fails to watch a few times, then notifies once and waits for ctx.
+type flakySource struct {
+	mu    sync.Mutex
+	fails int
+}
+
+func (s *flakySource) Load(ctx context.Context) (map[string]any, error) {
+	return map[string]any{"port": 1111}, nil
+}
+
+func (s *flakySource) Watch(ctx context.Context, notify func()) error {
+	s.mu.Lock()
+	if s.fails > 0 {
+		s.fails--
+		s.mu.Unlock()
+		return errors.New("connection refused")
+	}
+	s.mu.Unlock()
+
+	notify()
+	<-ctx.Done()
+	return nil
+}
+
+func writeFile(tb testing.TB, file, data string) {
+	tb.Helper()
+	if err := os.WriteFile(file, []byte(data), 0o600); err != nil {
+		tb.Fatal(err)
+	}
+}
+
+func waitChange(tb testing.TB, changes <-chan error) error {
+	tb.Helper()
+	select {
+	case err := <-changes:
+		return err
+	case <-time.After(5 * time.Second):
+		tb.Fatal("no reload")
+		return nil
+	}
+}
+
+func TestReloadRestartRequired(t *testing.T) {
+	type TestConfig struct {
+		Port     int `reload:"false"`
+		LogLevel string
+		Storage  struct {
+			Path string
+		} `reload:"false"`
+	}
+
+	file := filepath.Join(t.TempDir(), "config.json")
+	writeFile(t, file, `{"port": 80, "log_level": "info", "storage": {"path": "/a"}}`)
+
+	restart := make(chan []string, 1)
+
+	var cfg TestConfig
+	loader := LoaderFor(&cfg, Config{
+		NewParser: newParser,
+		SkipEnv:   true,
+		SkipFlags: true,
+		Files:     []string{file},
+		OnRestartRequired: func(fields []string) {
+			restart <- fields
+		},
+	})
+	failIfErr(t, loader.Load())
+
+	writeFile(t, file, `{"port": 81, "log_level": "debug", "storage": {"path": "/b"}}`)
+	failIfErr(t, loader.reload())
+
+	mustEqual(t, <-restart, []string{"Port", "Storage.Path"})
+	mustEqual(t, cfg.Port, 80)
+	mustEqual(t, cfg.LogLevel, "debug")
+	mustEqual(t, cfg.Storage.Path, "/a")
+
+	// no changes of restart-required fields, no callback.
+	writeFile(t, file, `{"port": 80, "log_level": "warn", "storage": {"path": "/a"}}`)
+	failIfErr(t, loader.reload())
+	mustEqual(t, cfg.LogLevel, "warn")
+	mustEqual(t, len(restart), 0)
+}
+
+func TestReloadView(t *testing.T) {
+	type TestConfig struct {
+		A, B, C int
+	}
+
+	file := filepath.Join(t.TempDir(), "config.json")
+	writeFile(t, file, `{"a": 0, "b": 0, "c": 0}`)
+
+	var cfg TestConfig
+	loader := LoaderFor(&cfg, Config{
+		NewParser: newParser,
+		SkipEnv:   true,
+		SkipFlags: true,
+		Files:     []string{file},
+	})
+	failIfErr(t, loader.Load())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for ctx.Err() == nil {
+			loader.View(func() {
+				if cfg.A != cfg.B || cfg.B != cfg.C {
+					t.Errorf("mixed config: %+v", cfg)
+				}
+			})
+		}
+	}()
+
+	for i := 1; i <= 20; i++ {
+		writeFile(t, file, fmt.Sprintf(`{"a": %d, "b": %d, "c": %d}`, i, i, i))
+		failIfErr(t, loader.reload())
+	}
+	cancel()
+	wg.Wait()
+
+	loader.View(func() {
+		mustEqual(t, cfg, TestConfig{A: 20, B: 20, C: 20})
+	})
+}
+
+func TestReloadRollback(t *testing.T) {
+	type TestConfig struct {
+		Port  int `required:"true" max:"65535"`
+		Hosts []string
+	}
+
+	file := filepath.Join(t.TempDir(), "config.json")
+	writeFile(t, file, `{"port": 80, "hosts": ["a", "b"]}`)
+
+	var cfg TestConfig
+	loader := LoaderFor(&cfg, Config{
+		NewParser: newParser,
+		SkipEnv:   true,
+		SkipFlags: true,
+		Files:     []string{file},
+	})
+	failIfErr(t, loader.Load())
+
+	want := TestConfig{Port: 80, Hosts: []string{"a", "b"}}
+	for _, broken := range []string{
+		`{"port": 70000, "hosts": ["c"]}`,
+		`{"hosts": ["c"]}`,
+		`{"port": "not a number", "hosts": ["c"]}`,
+		`{"port": 81, "hosts": ["c"]`,
+	} {
+		writeFile(t, file, broken)
+		failIfOk(t, loader.Reload())
+		mustEqual(t, cfg, want)
+	}
+
+	writeFile(t, file, `{"port": 81, "hosts": ["c"]}`)
+	failIfErr(t, loader.Reload())
+	mustEqual(t, cfg, TestConfig{Port: 81, Hosts: []string{"c"}})
+}
+
+func TestReloadOnChange(t *testing.T) {
+	type TestConfig struct {
+		Port     int
+		LogLevel string
+		Password string `secret:"true"`
+		Timeout  time.Duration
+	}
+
+	file := filepath.Join(t.TempDir(), "config.json")
+	writeFile(t, file, `{"port": 80, "log_level": "info", "password": "a", "timeout": "5s"}`)
+
+	var changes []FieldChange
+	var cfg TestConfig
+	loader := LoaderFor(&cfg, Config{
+		NewParser: newParser,
+		SkipEnv:   true,
+		SkipFlags: true,
+		Files:     []string{file},
+		OnChange: func(c []FieldChange) {
+			changes = c
+		},
+	})
+	failIfErr(t, loader.Load())
+
+	writeFile(t, file, `{"port": 80, "log_level": "debug", "password": "b", "timeout": "1m"}`)
+	failIfErr(t, loader.Reload())
+
+	mustEqual(t, changes, []FieldChange{
+		{Path: "LogLevel", Old: "info", New: "debug", Source: ValueSource{Kind: "file", Name: "log_level", File: file}},
+		{Path: "Password", Old: redacted, New: redacted, Source: ValueSource{Kind: "file", Name: "password", File: file}},
+		{Path: "Timeout", Old: "5s", New: "1m", Source: ValueSource{Kind: "file", Name: "timeout", File: file}},
+	})
+
+	// nothing has changed, no callback.
+	changes = nil
+	failIfErr(t, loader.Reload())
+	mustEqual(t, len(changes), 0)
+}
+
+func TestWatchSkipsRemoteFiles(t *testing.T) {
+	var fetches int32
+	fetcher := fetcherFunc(func(ctx context.Context, url string) ([]byte, error) {
+		atomic.AddInt32(&fetches, 1)
+		return []byte(`{"port": 1111}`), nil
+	})
+
+	var cfg struct{ Port int }
+	loader := LoaderFor(&cfg, Config{
+		SkipEnv:      true,
+		SkipFlags:    true,
+		Files:        []string{"s3://bucket/config.json"},
+		FileFetchers: map[string]FileFetcher{"s3": fetcher},
+	})
+	failIfErr(t, loader.Load())
+	mustEqual(t, cfg.Port, 1111)
+
+	loaded := atomic.LoadInt32(&fetches)
+	mustEqual(t, len(loader.filesSnapshot()), 0)
+	mustEqual(t, atomic.LoadInt32(&fetches), loaded)
+}
+
+type fetcherFunc func(ctx context.Context, url string) ([]byte, error)
+
+func (f fetcherFunc) Fetch(ctx context.Context, url string) ([]byte, error) {
+	return f(ctx, url)
+}
+
+func TestReloadEnvs(t *testing.T) {
+	t.Setenv("RELOAD_TEST_PORT", "1111")
+
+	var cfg struct{ Port int }
+	loader := LoaderFor(&cfg, Config{
+		SkipFlags: true,
+		EnvPrefix: "RELOAD_TEST",
+	})
+	failIfErr(t, loader.Load())
+	mustEqual(t, cfg.Port, 1111)
+
+	t.Setenv("RELOAD_TEST_PORT", "2222")
+	failIfErr(t, loader.Reload())
+	mustEqual(t, cfg.Port, 2222)
+}
@@ -0,0 +1,57 @@
+//go:build unix
+
+package aconfig
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestReloadOnSignal(t *testing.T) {
+	type TestConfig struct {
+		Port int
+	}
+
+	file := filepath.Join(t.TempDir(), "config.json")
+	writeFile(t, file, `{"port": 1111}`)
+
+	var cfg TestConfig
+	loader := LoaderFor(&cfg, Config{
+		SkipEnv:   true,
+		SkipFlags: true,
+		Files:     []string{file},
+	})
+	failIfErr(t, loader.Load())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// catch the signal before ReloadOn is ready, otherwise process is killed.
+	guard := make(chan os.Signal, 1)
+	signal.Notify(guard, syscall.SIGUSR1)
+	defer signal.Stop(guard)
+
+	reloads := make(chan error, 1)
+	go loader.ReloadOn(ctx, func(err error) {
+		reloads <- err
+	}, syscall.SIGUSR1)
+
+	writeFile(t, file, `{"port": 2222}`)
+
+	// ReloadOn might start listening a bit later, so retry.
+	for {
+		failIfErr(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR1))
+		select {
+		case err := <-reloads:
+			failIfErr(t, err)
+			mustEqual(t, cfg.Port, 2222)
+			return
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package aconfig
+
+import (
+	"flag"
+	"reflect"
+	"strings"
+)
+
+// repeatedValue is a flag value that accumulates repeated flags.
+type repeatedValue interface {
+	flag.Value
+
+	// items returns the value as a slice or a map for the new parser, which doesn't split strings.
+	items() any
+}
+
+var (
+	_ repeatedValue = (*sliceFlag)(nil)
+	_ repeatedValue = (*mapFlag)(nil)
+)
+
+// repeatedFlag returns a flag value for slice and map fields or nil for other fields.
+// []byte and TextUnmarshaler types are set as a whole.
+func repeatedFlag(field reflect.StructField, value, sep string) repeatedValue {
+	typ := field.Type
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if reflect.PtrTo(typ).Implements(textUnmarshalerType) {
+		return nil
+	}
+
+	switch {
+	case typ.Kind() == reflect.Slice && typ.Elem().Kind() != reflect.Uint8:
+		return &sliceFlag{value: value, sep: sep}
+	case typ.Kind() == reflect.Map:
+		return &mapFlag{value: value}
+	default:
+		return nil
+	}
+}
+
+// sliceFlag is a flag for slice fields where repeated flags accumulate:
+// -tag=a -tag=b is the same as -tag=a,b. The first passed flag replaces the default.
+type sliceFlag struct {
+	value string
+	sep   string
+	isSet bool
+}
+
+func (f *sliceFlag) String() string {
+	if f == nil {
+		return ""
+	}
+	return f.value
+}
+
+func (f *sliceFlag) Set(value string) error {
+	if f.isSet {
+		f.value += f.sep + value
+		return nil
+	}
+	f.value, f.isSet = value, true
+	return nil
+}
+
+func (f *sliceFlag) items() any {
+	return strings.Split(f.value, f.sep)
+}
+
+// mapFlag is a flag for map fields where repeated flags accumulate:
+// -label=a=1 -label=b=2 is the same as -label=a:1,b:2. The first passed flag replaces the default.
+type mapFlag struct {
+	value string
+	isSet bool
+}
+
+func (f *mapFlag) String() string {
+	if f == nil {
+		return ""
+	}
+	return f.value
+}
+
+func (f *mapFlag) Set(value string) error {
+	// key=value entry, otherwise the usual key:value,key:value syntax.
+	if key, val, ok := strings.Cut(value, "="); ok {
+		value = key + ":" + val
+	}
+	if f.isSet {
+		f.value += "," + value
+		return nil
+	}
+	f.value, f.isSet = value, true
+	return nil
+}
+
+func (f *mapFlag) items() any {
+	res := map[string]any{}
+	for _, entry := range strings.Split(f.value, ",") {
+		key, val, _ := strings.Cut(entry, ":")
+		res[strings.TrimSpace(key)] = strings.TrimSpace(val)
+	}
+	return res
+}
@@ -0,0 +1,83 @@
+package aconfig
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Report describes the last Load, usually it's logged once at startup. See Loader.Report.
+type Report struct {
+	Duration     time.Duration  // Duration of the whole load.
+	Sources      []SourceTiming // Sources are source kinds in the load order.
+	Fields       []FieldReport  // Fields in the structure order, set or not.
+	LoadedFiles  []string
+	MissingFiles []string
+	Warnings     []string // Warnings are missing files, shared names and likely typos, see Lint.
+}
+
+// SourceTiming is how long loading of a source kind took.
+// Kind is a SourceKind, "set" for Config.SetFlag or "override" for Config.OverrideFile.
+type SourceTiming struct {
+	Kind     string
+	Duration time.Duration
+}
+
+// FieldReport is a field and the source of its value.
+// Values aren't reported, so the report is safe to log.
+type FieldReport struct {
+	Name   string
+	Source ValueSource
+}
+
+// Report returns the report of the last Load. See Config.StrictReport.
+func (l *Loader) Report() Report {
+	l.stateMu.RLock()
+	defer l.stateMu.RUnlock()
+	return l.report()
+}
+
+func (l *Loader) report() Report {
+	report := Report{
+		Duration:     l.loadDuration,
+		Sources:      append([]SourceTiming{}, l.timings...),
+		LoadedFiles:  append([]string(nil), l.loadedFiles...),
+		MissingFiles: append([]string(nil), l.missingFiles...),
+	}
+	for _, field := range l.allFields() {
+		report.Fields = append(report.Fields, FieldReport{Name: field.Name(), Source: field.Source()})
+	}
+
+	for _, file := range report.MissingFiles {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("file %s not found", file))
+	}
+	for _, dupl := range l.Duplicates() {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("%s %s is shared by fields %s",
+			dupl.Source, dupl.Name, strings.Join(dupl.Fields, ", ")))
+	}
+	for _, miss := range l.lint() {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("%s: %s looks like %s", miss.Source, miss.Name, miss.Known))
+	}
+	return report
+}
+
+// timeSource records how long fn took for Report.
+func (l *Loader) timeSource(kind string, fn func() error) error {
+	start := time.Now()
+	err := fn()
+	l.timings = append(l.timings, SourceTiming{Kind: kind, Duration: time.Since(start)})
+	return err
+}
+
+// checkReport returns an error with report warnings. See Config.StrictReport.
+func (l *Loader) checkReport() error {
+	if !l.config.StrictReport {
+		return nil
+	}
+	warnings := l.report().Warnings
+	if len(warnings) == 0 {
+		return nil
+	}
+	return errors.New("report has warnings: " + strings.Join(warnings, "; "))
+}
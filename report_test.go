@@ -0,0 +1,59 @@
+package aconfig
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestReport(t *testing.T) {
+	type TestConfig struct {
+		Port int `default:"8080"`
+		Host string
+		Name string
+	}
+
+	cfg := Config{
+		NewParser:        newParser,
+		FileSystem:       fstest.MapFS{"config.json": {Data: []byte(`{"host": "file-host"}`)}},
+		Files:            []string{"config.json", "missing.json"},
+		MergeFiles:       true,
+		EnvPrefix:        "APP",
+		Envs:             []string{"APP_NAME=env", "APP_PROT=1"},
+		AllowUnknownEnvs: true,
+		SetFlag:          "set",
+		Args:             []string{"-set", "port=9090"},
+	}
+	loader := LoaderFor(&TestConfig{}, cfg)
+	failIfErr(t, loader.Load())
+
+	report := loader.Report()
+	mustEqual(t, report.Fields, []FieldReport{
+		{Name: "Port", Source: ValueSource{Kind: "flag", Name: "set port"}},
+		{Name: "Host", Source: ValueSource{Kind: "file", Name: "host", File: "config.json"}},
+		{Name: "Name", Source: ValueSource{Kind: "env", Name: "APP_NAME"}},
+	})
+	kinds := []string{}
+	for _, timing := range report.Sources {
+		kinds = append(kinds, timing.Kind)
+	}
+	mustEqual(t, kinds, []string{"default", "file", "source", "env", "flag", "set"})
+	mustEqual(t, report.LoadedFiles, []string{"config.json"})
+	mustEqual(t, report.MissingFiles, []string{"missing.json"})
+	report.LoadedFiles[0] = "changed.json"
+	mustEqual(t, loader.LoadedFiles(), []string{"config.json"})
+	mustEqual(t, report.Warnings, []string{
+		"file missing.json not found",
+		"env: APP_PROT looks like APP_PORT",
+	})
+	if report.Duration <= 0 {
+		t.Fatalf("got duration %v", report.Duration)
+	}
+
+	cfg.StrictReport = true
+	err := LoaderFor(&TestConfig{}, cfg).Load()
+	failIfOk(t, err)
+	if !strings.Contains(err.Error(), "report has warnings: file missing.json not found; env: APP_PROT looks like APP_PORT") {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,79 @@
+package aconfig
+
+import (
+	"errors"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ErrOutsideFileRoots is returned for a file that is outside of Config.FileRoots.
+var ErrOutsideFileRoots = errors.New("file is outside of file roots")
+
+// rootsFS allows opening only files inside of the roots.
+type rootsFS struct {
+	fs.FS
+	roots []string
+	isOS  bool
+}
+
+func newRootsFS(fsys fs.FS, roots []string, isOS bool) *rootsFS {
+	r := &rootsFS{FS: fsys, isOS: isOS}
+	for _, root := range roots {
+		if isOS {
+			root = resolvePath(root)
+		} else {
+			root = path.Clean(root)
+		}
+		r.roots = append(r.roots, root)
+	}
+	return r
+}
+
+func (r *rootsFS) Open(name string) (fs.File, error) {
+	if !r.allowed(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: ErrOutsideFileRoots}
+	}
+	return r.FS.Open(name)
+}
+
+// allowed reports whether the file is inside of one of the roots.
+// Symbolic links on OS are resolved, so a link can't point outside.
+func (r *rootsFS) allowed(name string) bool {
+	if !r.isOS {
+		name = path.Clean(name)
+		for _, root := range r.roots {
+			if root == "." && name != ".." && !strings.HasPrefix(name, "../") {
+				return true
+			}
+			if name == root || strings.HasPrefix(name, root+"/") {
+				return true
+			}
+		}
+		return false
+	}
+
+	name = resolvePath(name)
+	for _, root := range r.roots {
+		rel, err := filepath.Rel(root, name)
+		if err != nil {
+			continue
+		}
+		if rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolvePath returns absolute path with symbolic links resolved when possible.
+func resolvePath(p string) string {
+	if abs, err := filepath.Abs(p); err == nil {
+		p = abs
+	}
+	if resolved, err := filepath.EvalSymlinks(p); err == nil {
+		p = resolved
+	}
+	return p
+}
@@ -0,0 +1,94 @@
+package aconfig
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+func TestFileRoots(t *testing.T) {
+	type TestConfig struct {
+		Name string
+	}
+
+	fsys := fstest.MapFS{
+		"tenants/a/config.json": &fstest.MapFile{Data: []byte(`{"name": "a"}`)},
+		"tenants/b/config.json": &fstest.MapFile{Data: []byte(`{"name": "b"}`)},
+	}
+
+	f := func(file string) (TestConfig, error) {
+		t.Helper()
+
+		var cfg TestConfig
+		err := LoaderFor(&cfg, Config{
+			NewParser:          newParser,
+			SkipDefaults:       true,
+			SkipEnv:            true,
+			SkipFlags:          true,
+			FailOnFileNotFound: true,
+			FileRoots:          []string{"tenants/a"},
+			Files:              []string{file},
+			FileSystem:         fsys,
+		}).Load()
+		return cfg, err
+	}
+
+	cfg, err := f("tenants/a/config.json")
+	failIfErr(t, err)
+	mustEqual(t, cfg.Name, "a")
+
+	for _, file := range []string{"tenants/b/config.json", "tenants/a/../b/config.json"} {
+		_, err = f(file)
+		failIfOk(t, err)
+		if !errors.Is(err, ErrOutsideFileRoots) {
+			t.Fatalf("want ErrOutsideFileRoots, got %v", err)
+		}
+	}
+}
+
+func TestFileRootsOS(t *testing.T) {
+	type TestConfig struct {
+		Name string
+	}
+
+	dir := t.TempDir()
+	root := filepath.Join(dir, "root")
+	failIfErr(t, os.Mkdir(root, 0o755))
+	failIfErr(t, os.WriteFile(filepath.Join(root, "config.json"), []byte(`{"name": "inside"}`), 0o600))
+	failIfErr(t, os.WriteFile(filepath.Join(dir, "secret.json"), []byte(`{"name": "secret"}`), 0o600))
+	if err := os.Symlink(filepath.Join(dir, "secret.json"), filepath.Join(root, "link.json")); err != nil {
+		t.Skip(err)
+	}
+
+	f := func(file string) (TestConfig, error) {
+		t.Helper()
+
+		var cfg TestConfig
+		err := LoaderFor(&cfg, Config{
+			NewParser:    newParser,
+			SkipDefaults: true,
+			SkipEnv:      true,
+			SkipFlags:    true,
+			FileRoots:    []string{root},
+			Files:        []string{file},
+		}).Load()
+		return cfg, err
+	}
+
+	cfg, err := f(filepath.Join(root, "config.json"))
+	failIfErr(t, err)
+	mustEqual(t, cfg.Name, "inside")
+
+	for _, file := range []string{
+		filepath.Join(dir, "secret.json"),
+		filepath.Join(root, "..", "secret.json"),
+		filepath.Join(root, "link.json"),
+	} {
+		_, err = f(file)
+		if !errors.Is(err, ErrOutsideFileRoots) {
+			t.Fatalf("want ErrOutsideFileRoots for %s, got %v", file, err)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package aconfig
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// WriteExample writes an example configuration populated with default values.
+// Supported formats are "env", "json" and "yaml", usage of the fields is written as comments
+// except for JSON which has no comments. Names for "yaml" are generated only when
+// a decoder for this format is set in Config.FileDecoders.
+func (l *Loader) WriteExample(w io.Writer, format string) error {
+	l.stateMu.RLock()
+	defer l.stateMu.RUnlock()
+
+	fields, err := l.exampleFields()
+	if err != nil {
+		return err
+	}
+
+	var sb strings.Builder
+	switch format {
+	case "env":
+		err = l.writeEnvExample(&sb, fields)
+	case "json":
+		err = l.writeJSONExample(&sb, fields)
+	case "yaml":
+		err = l.writeYAMLExample(&sb, fields)
+	default:
+		return fmt.Errorf("example for format %q isn't supported", format)
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, sb.String())
+	return err
+}
+
+// exampleField is a field with its default value for examples and schemas.
+type exampleField struct {
+	Field
+	value reflect.Value // value is the default value or the zero value of the field type.
+}
+
+// exampleFields returns fields with values that have only defaults applied.
+// Defaults are parsed on init, see checkDefaults.
+func (l *Loader) exampleFields() ([]exampleField, error) {
+	if l.errInit != nil {
+		return nil, fmt.Errorf("init loader: %w", l.errInit)
+	}
+
+	fields := l.allFields()
+	res := make([]exampleField, 0, len(fields))
+	for _, field := range fields {
+		sf, _ := fieldInfo(field)
+		value := reflect.Zero(sf.Type)
+		if def := field.DefaultValue(); def != nil {
+			value = reflect.ValueOf(def)
+		}
+		res = append(res, exampleField{Field: field, value: value})
+	}
+	return res, nil
+}
+
+func (l *Loader) hasFormat(format string) bool {
+	for _, dec := range l.config.FileDecoders {
+		if dec.Format() == format {
+			return true
+		}
+	}
+	return false
+}
+
+func (l *Loader) writeEnvExample(sb *strings.Builder, fields []exampleField) error {
+	for _, field := range fields {
+		name := l.sourceName(field.Field, "env")
+		if name == "" {
+			continue
+		}
+		if sb.Len() > 0 {
+			sb.WriteString("\n")
+		}
+		writeExampleComment(sb, "", field)
+		sb.WriteString(name + "=" + field.Tag("default") + "\n")
+	}
+	return nil
+}
+
+func (l *Loader) writeJSONExample(sb *strings.Builder, fields []exampleField) error {
+	values := map[string]any{}
+	for _, field := range fields {
+		name := l.sourceName(field.Field, "json")
+		if name == "" {
+			continue
+		}
+		setNested(values, strings.Split(name, "."), l.render(field.value))
+	}
+
+	data, err := json.MarshalIndent(values, "", "  ")
+	if err != nil {
+		return err
+	}
+	sb.Write(data)
+	sb.WriteString("\n")
+	return nil
+}
+
+func (l *Loader) writeYAMLExample(sb *strings.Builder, fields []exampleField) error {
+	if !l.hasFormat("yaml") {
+		return errors.New("yaml decoder isn't set in FileDecoders")
+	}
+
+	var prev []string
+	for _, field := range fields {
+		name := l.sourceName(field.Field, "yaml")
+		if name == "" {
+			continue
+		}
+		keys := strings.Split(name, ".")
+
+		// write parent keys that differ from the previous field.
+		same := 0
+		for same < len(prev)-1 && same < len(keys)-1 && prev[same] == keys[same] {
+			same++
+		}
+		for i := same; i < len(keys)-1; i++ {
+			sb.WriteString(strings.Repeat("  ", i) + keys[i] + ":\n")
+		}
+		prev = keys
+
+		// JSON is a subset of YAML, so scalars, lists and maps are written as JSON.
+		value, err := json.Marshal(l.render(field.value))
+		if err != nil {
+			return fmt.Errorf("field %q: %w", field.Name(), err)
+		}
+
+		indent := strings.Repeat("  ", len(keys)-1)
+		writeExampleComment(sb, indent, field)
+		sb.WriteString(indent + keys[len(keys)-1] + ": " + string(value) + "\n")
+	}
+	return nil
+}
+
+func writeExampleComment(sb *strings.Builder, indent string, field exampleField) {
+	if usage := field.Tag("usage"); usage != "" {
+		sb.WriteString(indent + "# " + usage + "\n")
+	}
+	if isFieldRequired(field.Field) {
+		sb.WriteString(indent + "# required\n")
+	}
+}
+
+// setNested sets value in m by the path of keys, creating nested maps when needed.
+func setNested(m map[string]any, keys []string, value any) {
+	for _, key := range keys[:len(keys)-1] {
+		sub, ok := m[key].(map[string]any)
+		if !ok {
+			sub = map[string]any{}
+			m[key] = sub
+		}
+		m = sub
+	}
+	m[keys[len(keys)-1]] = value
+}
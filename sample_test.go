@@ -0,0 +1,93 @@
+package aconfig
+
+import (
+	"bytes"
+	"testing"
+)
+
+type yamlStub struct{}
+
+func (yamlStub) Format() string { return "yaml" }
+
+func (yamlStub) DecodeFile(string) (map[string]any, error) { return nil, nil }
+
+func TestWriteExample(t *testing.T) {
+	type SampleConfig struct {
+		HTTPPort int `default:"8080" usage:"port to listen"`
+		Auth     struct {
+			User  string `default:"root" usage:"user name"`
+			Token string `required:"true"`
+		}
+		Tags []string `default:"a,b"`
+	}
+
+	var cfg SampleConfig
+	loader := LoaderFor(&cfg, Config{
+		NewParser: newParser,
+		EnvPrefix: "APP",
+		Envs:      []string{"APP_HTTP_PORT=9000", "APP_AUTH_TOKEN=t0k3n"},
+		Args:      []string{},
+		FileDecoders: map[string]FileDecoder{
+			".yaml": yamlStub{},
+		},
+	})
+	failIfErr(t, loader.Load())
+
+	testCases := []struct {
+		format string
+		want   string
+	}{
+		{
+			format: "env",
+			want: `# port to listen
+APP_HTTP_PORT=8080
+
+# user name
+APP_AUTH_USER=root
+
+# required
+APP_AUTH_TOKEN=
+
+APP_TAGS=a,b
+`,
+		},
+		{
+			format: "json",
+			want: `{
+  "auth": {
+    "token": "",
+    "user": "root"
+  },
+  "http_port": 8080,
+  "tags": [
+    "a",
+    "b"
+  ]
+}
+`,
+		},
+		{
+			format: "yaml",
+			want: `# port to listen
+http_port: 8080
+auth:
+  # user name
+  user: "root"
+  # required
+  token: ""
+tags: ["a","b"]
+`,
+		},
+	}
+
+	for _, tc := range testCases {
+		var buf bytes.Buffer
+		failIfErr(t, loader.WriteExample(&buf, tc.format))
+		mustEqual(t, buf.String(), tc.want)
+	}
+
+	failIfOk(t, loader.WriteExample(&bytes.Buffer{}, "toml"))
+
+	// the loaded value isn't touched.
+	mustEqual(t, cfg.HTTPPort, 9000)
+}
@@ -0,0 +1,108 @@
+package aconfig
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// JSONSchema returns a JSON Schema document for JSON config files.
+// Field types, defaults, required fields and usage (as description) are taken from the structure.
+func (l *Loader) JSONSchema() ([]byte, error) {
+	l.stateMu.RLock()
+	defer l.stateMu.RUnlock()
+
+	fields, err := l.exampleFields()
+	if err != nil {
+		return nil, err
+	}
+
+	root := newObjectSchema()
+	root["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+
+	for _, field := range fields {
+		name := l.sourceName(field.Field, "json")
+		if name == "" {
+			continue
+		}
+		keys := strings.Split(name, ".")
+
+		obj := root
+		for _, key := range keys[:len(keys)-1] {
+			props := obj["properties"].(map[string]any)
+			sub, ok := props[key].(map[string]any)
+			if !ok {
+				sub = newObjectSchema()
+				props[key] = sub
+			}
+			obj = sub
+		}
+
+		schema := typeSchema(field.value.Type())
+		if hasAdapter(l.config.Adapters, field.value.Type()) {
+			schema = map[string]any{"type": "string"}
+		}
+		if usage := field.Tag("usage"); usage != "" {
+			schema["description"] = usage
+		}
+		if def := field.Tag("default"); def != "" {
+			if schema["type"] == "string" {
+				schema["default"] = def
+			} else {
+				schema["default"] = l.render(field.value)
+			}
+		}
+
+		key := keys[len(keys)-1]
+		obj["properties"].(map[string]any)[key] = schema
+		if isFieldRequired(field.Field) {
+			required, _ := obj["required"].([]string)
+			obj["required"] = append(required, key)
+		}
+	}
+	return json.MarshalIndent(root, "", "  ")
+}
+
+func newObjectSchema() map[string]any {
+	return map[string]any{
+		"type":       "object",
+		"properties": map[string]any{},
+	}
+}
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// typeSchema returns JSON Schema for the values that loader accepts for the type.
+func typeSchema(typ reflect.Type) map[string]any {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ == reflect.TypeOf(time.Duration(0)) || reflect.PtrTo(typ).Implements(textUnmarshalerType) {
+		return map[string]any{"type": "string"}
+	}
+
+	switch typ.Kind() {
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		if typ.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string"}
+		}
+		return map[string]any{"type": "array", "items": typeSchema(typ.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": typeSchema(typ.Elem())}
+	case reflect.Struct:
+		return map[string]any{"type": "object"}
+	default:
+		return map[string]any{}
+	}
+}
@@ -0,0 +1,66 @@
+package aconfig
+
+import (
+	"testing"
+	"time"
+)
+
+func TestJSONSchema(t *testing.T) {
+	type SchemaConfig struct {
+		HTTPPort int           `default:"8080" usage:"port to listen"`
+		Timeout  time.Duration `default:"5s"`
+		Auth     struct {
+			User string `required:"true"`
+			Pass *string
+		}
+		Tags   []string
+		Limits map[string]float64
+	}
+
+	loader := LoaderFor(&SchemaConfig{}, Config{NewParser: newParser, Args: []string{}})
+	schema, err := loader.JSONSchema()
+	failIfErr(t, err)
+
+	want := `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "properties": {
+    "auth": {
+      "properties": {
+        "pass": {
+          "type": "string"
+        },
+        "user": {
+          "type": "string"
+        }
+      },
+      "required": [
+        "user"
+      ],
+      "type": "object"
+    },
+    "http_port": {
+      "default": 8080,
+      "description": "port to listen",
+      "type": "integer"
+    },
+    "limits": {
+      "additionalProperties": {
+        "type": "number"
+      },
+      "type": "object"
+    },
+    "tags": {
+      "items": {
+        "type": "string"
+      },
+      "type": "array"
+    },
+    "timeout": {
+      "default": "5s",
+      "type": "string"
+    }
+  },
+  "type": "object"
+}`
+	mustEqual(t, string(schema), want)
+}
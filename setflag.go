@@ -0,0 +1,80 @@
+package aconfig
+
+import (
+	"fmt"
+	"strings"
+)
+
+// setFlag is a repeated flag of key=value overrides. See Config.SetFlag.
+type setFlag struct {
+	entries []string
+}
+
+func (f *setFlag) String() string {
+	if f == nil {
+		return ""
+	}
+	return strings.Join(f.entries, " ")
+}
+
+func (f *setFlag) Set(value string) error {
+	if _, _, ok := strings.Cut(value, "="); !ok {
+		return fmt.Errorf("want key=value, got %q", value)
+	}
+	f.entries = append(f.entries, value)
+	return nil
+}
+
+// loadSetFlag applies values of Config.SetFlag over all the other sources.
+func (l *Loader) loadSetFlag() error {
+	if l.config.SetFlag == "" || l.config.SkipFlags {
+		return nil
+	}
+	f := l.flagSet.Lookup(l.config.SetFlag)
+	if f == nil {
+		return nil
+	}
+	values, ok := f.Value.(*setFlag)
+	if !ok {
+		return nil
+	}
+
+	fields := map[string]Field{}
+	for _, field := range l.allFields() {
+		fields[strings.ToLower(field.Name())] = field
+		fields[l.setKey(field.Name())] = field
+	}
+
+	for _, entry := range values.entries {
+		key, value, _ := strings.Cut(entry, "=")
+		field, ok := fields[strings.ToLower(key)]
+		if !ok {
+			return fmt.Errorf("-%s %s: unknown field %q", l.config.SetFlag, entry, key)
+		}
+
+		// slices and maps are parsed like repeated flags: tags=a,b or labels=k=v.
+		var raw any = value
+		sf, _ := fieldInfo(field)
+		if rv := repeatedFlag(sf, "", l.config.SliceSeparator); rv != nil {
+			_ = rv.Set(value)
+			raw = rv.String()
+			if l.config.NewParser {
+				raw = rv.items()
+			}
+		}
+		source := ValueSource{Kind: "flag", Name: l.config.SetFlag + " " + key}
+		if err := l.setFieldValue(field, raw, source); err != nil {
+			return fmt.Errorf("-%s %s: %w", l.config.SetFlag, entry, err)
+		}
+	}
+	return nil
+}
+
+// setKey returns a key for Config.SetFlag from a field path: DB.UserName gives db.user_name.
+func (l *Loader) setKey(path string) string {
+	parts := strings.Split(path, ".")
+	for i, part := range parts {
+		parts[i] = strings.ToLower(strings.Join(l.config.WordSplit.Split(part), "_"))
+	}
+	return strings.Join(parts, ".")
+}
@@ -0,0 +1,57 @@
+package aconfig
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSetFlag(t *testing.T) {
+	type TestConfig struct {
+		Server struct {
+			Port int `default:"8080" flag:"-"`
+		}
+		DB struct {
+			UserName string `env:"DB_USER"`
+		}
+		Tags   []string `default:"a,b"`
+		Labels map[string]string
+	}
+
+	var cfg TestConfig
+	loader := LoaderFor(&cfg, Config{
+		NewParser: newParser,
+		SkipFiles: true,
+		Envs:      []string{"DB_USER=env"},
+		SetFlag:   "set",
+		Args: []string{
+			"-set", "server.port=9090",
+			"-set=DB.UserName=admin",
+			"-set", "tags=c,d",
+			"-set", "labels=x=1",
+		},
+	})
+	failIfErr(t, loader.Load())
+
+	mustEqual(t, cfg.Server.Port, 9090)
+	mustEqual(t, cfg.DB.UserName, "admin")
+	mustEqual(t, cfg.Tags, []string{"c", "d"})
+	mustEqual(t, cfg.Labels, map[string]string{"x": "1"})
+	mustEqual(t, loader.Explain("Server.Port"), "Server.Port: flag -set server.port")
+
+	f := func(args []string, want string) {
+		t.Helper()
+		err := LoaderFor(&TestConfig{}, Config{
+			NewParser: newParser,
+			SkipFiles: true,
+			SkipEnv:   true,
+			SetFlag:   "set",
+			Args:      args,
+		}).Load()
+		failIfOk(t, err)
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("got %v, want %q", err, want)
+		}
+	}
+	f([]string{"-set", "nope=1"}, `unknown field "nope"`)
+	f([]string{"-set", "server.port"}, `want key=value, got "server.port"`)
+}
@@ -0,0 +1,149 @@
+package aconfig
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// SliceMerge is a strategy for slice fields set by several sources. See Config.SliceMerge.
+type SliceMerge int
+
+const (
+	// SliceReplace replaces the slice with values from a later source. Default.
+	SliceReplace SliceMerge = iota
+
+	// SliceAppend appends values from a later source to the slice.
+	SliceAppend
+
+	// SliceUnion appends values from a later source that aren't in the slice yet.
+	SliceUnion
+)
+
+var sliceMerges = map[string]SliceMerge{
+	"replace": SliceReplace,
+	"append":  SliceAppend,
+	"union":   SliceUnion,
+}
+
+func (m SliceMerge) String() string {
+	for name, merge := range sliceMerges {
+		if merge == m {
+			return name
+		}
+	}
+	return fmt.Sprintf("SliceMerge(%d)", int(m))
+}
+
+// sliceMergeOf returns the strategy for the field: from `merge` tag or the default one.
+// []byte fields and non-slice fields are always replaced.
+func sliceMergeOf(field reflect.StructField, def SliceMerge) SliceMerge {
+	typ := field.Type
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Slice || typ.Elem().Kind() == reflect.Uint8 {
+		return SliceReplace
+	}
+	if merge, ok := sliceMerges[field.Tag.Get("merge")]; ok {
+		return merge
+	}
+	return def
+}
+
+// checkSliceMerge returns an error for an unknown strategy in `merge` tag.
+func (l *Loader) checkSliceMerge() error {
+	for _, field := range l.allFields() {
+		sf, _ := fieldInfo(field)
+		tag := sf.Tag.Get("merge")
+		if _, ok := sliceMerges[tag]; tag != "" && !ok {
+			return fmt.Errorf("field %s: merge tag must be replace, append or union, got %q", field.Name(), tag)
+		}
+	}
+	return nil
+}
+
+// mergeSlice sets the slice field that is already set by a previous source using the merge strategy.
+func (l *Loader) mergeSlice(field *fieldData, value interface{}, merge SliceMerge) error {
+	fresh := *field
+	fresh.isSet = false
+	fresh.value = reflect.New(field.value.Type()).Elem()
+	if err := l.setFieldData(&fresh, value); err != nil {
+		return err
+	}
+	field.value.Set(mergeSliceValues(field.value, fresh.value, merge))
+	return nil
+}
+
+func mergeSliceValues(old, new reflect.Value, merge SliceMerge) reflect.Value {
+	res := reflect.MakeSlice(old.Type(), 0, old.Len()+new.Len())
+	res = reflect.AppendSlice(res, old)
+	for i := 0; i < new.Len(); i++ {
+		item := new.Index(i)
+		if merge == SliceUnion && containsValue(res, item.Interface()) {
+			continue
+		}
+		res = reflect.Append(res, item)
+	}
+	return res
+}
+
+func containsValue(slice reflect.Value, v interface{}) bool {
+	for i := 0; i < slice.Len(); i++ {
+		if reflect.DeepEqual(slice.Index(i).Interface(), v) {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeRaw merges raw values of a slice field for the new parser. Strings are split by sep.
+func mergeRaw(old, new any, sep string, merge SliceMerge) []any {
+	res := rawItems(old, sep)
+	for _, item := range rawItems(new, sep) {
+		if merge == SliceUnion && containsRaw(res, item) {
+			continue
+		}
+		res = append(res, item)
+	}
+	return res
+}
+
+func rawItems(v any, sep string) []any {
+	switch v := v.(type) {
+	case nil:
+		return nil
+	case string:
+		if v == "" {
+			return nil
+		}
+		parts := strings.Split(v, sep)
+		res := make([]any, len(parts))
+		for i, part := range parts {
+			res[i] = strings.TrimSpace(part)
+		}
+		return res
+	case []any:
+		return append([]any{}, v...)
+	default:
+		rv := reflect.ValueOf(v)
+		if rv.Kind() != reflect.Slice {
+			return []any{v}
+		}
+		res := make([]any, rv.Len())
+		for i := range res {
+			res[i] = rv.Index(i).Interface()
+		}
+		return res
+	}
+}
+
+// containsRaw compares raw values by their text, so 1 and "1" are the same.
+func containsRaw(items []any, v any) bool {
+	for _, item := range items {
+		if fmt.Sprint(item) == fmt.Sprint(v) {
+			return true
+		}
+	}
+	return false
+}
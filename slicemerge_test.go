@@ -0,0 +1,69 @@
+package aconfig
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestSliceMerge(t *testing.T) {
+	type TestConfig struct {
+		Hosts    []string `default:"a,b"`
+		Ports    []int    `default:"1,2" merge:"union"`
+		Replaced []string `default:"x,y" merge:"replace"`
+	}
+
+	fsys := fstest.MapFS{
+		"config.json": &fstest.MapFile{Data: []byte(`{
+			"hosts": ["c", "a"],
+			"ports": [2, 3],
+			"replaced": ["z", "w"]
+		}`)},
+	}
+
+	load := func(merge SliceMerge) TestConfig {
+		t.Helper()
+
+		var cfg TestConfig
+		err := LoaderFor(&cfg, Config{
+			NewParser:  newParser,
+			SkipFlags:  true,
+			SliceMerge: merge,
+			Files:      []string{"config.json"},
+			FileSystem: fsys,
+			Envs:       []string{"HOSTS=d", "PORTS=4,1"},
+		}).Load()
+		failIfErr(t, err)
+		return cfg
+	}
+
+	mustEqual(t, load(SliceReplace), TestConfig{
+		Hosts:    []string{"d"},
+		Ports:    []int{1, 2, 3, 4},
+		Replaced: []string{"z", "w"},
+	})
+	mustEqual(t, load(SliceAppend), TestConfig{
+		Hosts:    []string{"a", "b", "c", "a", "d"},
+		Ports:    []int{1, 2, 3, 4},
+		Replaced: []string{"z", "w"},
+	})
+	mustEqual(t, load(SliceUnion), TestConfig{
+		Hosts:    []string{"a", "b", "c", "d"},
+		Ports:    []int{1, 2, 3, 4},
+		Replaced: []string{"z", "w"},
+	})
+}
+
+func TestSliceMergeBadTag(t *testing.T) {
+	type TestConfig struct {
+		Hosts []string `merge:"prepend"`
+	}
+
+	var cfg TestConfig
+	err := LoaderFor(&cfg, Config{
+		NewParser: newParser,
+		SkipFiles: true,
+		SkipEnv:   true,
+		SkipFlags: true,
+	}).Load()
+	failIfOk(t, err)
+}
@@ -0,0 +1,74 @@
+package aconfig
+
+import (
+	"bufio"
+	"io"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// sniffSize is how many bytes from the beginning of a file are used to detect its format.
+const sniffSize = 4 << 10
+
+var (
+	envLineRe   = regexp.MustCompile(`^(export\s+)?[A-Z_][A-Z0-9_]*=`)
+	tomlTableRe = regexp.MustCompile(`^\[\[?[A-Za-z0-9_.\-" ]+\]\]?$`)
+	yamlKeyRe   = regexp.MustCompile(`^[^\s=:]+:(\s|$)`)
+	assignRe    = regexp.MustCompile(`^[A-Za-z0-9_.\-]+\s*=`)
+)
+
+// sniffDecoder returns a registered decoder for the file format detected by its content.
+func (l *Loader) sniffDecoder(fsys fs.FS, name string) (FileDecoder, bool) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, false
+	}
+	defer f.Close()
+
+	head, err := io.ReadAll(io.LimitReader(f, sniffSize))
+	if err != nil {
+		return nil, false
+	}
+
+	exts := make([]string, 0, len(l.config.FileDecoders))
+	for ext := range l.config.FileDecoders {
+		exts = append(exts, ext)
+	}
+	sort.Strings(exts)
+
+	for _, format := range sniffFormats(string(head)) {
+		for _, ext := range exts {
+			if dec := l.config.FileDecoders[ext]; dec.Format() == format {
+				return dec, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// sniffFormats returns possible formats of the content by its first meaningful line, most likely first.
+func sniffFormats(content string) []string {
+	sc := bufio.NewScanner(strings.NewReader(content))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		switch {
+		case line == "", strings.HasPrefix(line, "#"), strings.HasPrefix(line, ";"), strings.HasPrefix(line, "//"):
+			continue
+		case strings.HasPrefix(line, "{"):
+			return []string{"json"}
+		case line == "---", strings.HasPrefix(line, "- "), yamlKeyRe.MatchString(line):
+			return []string{"yaml"}
+		case tomlTableRe.MatchString(line):
+			return []string{"toml", "ini"}
+		case envLineRe.MatchString(line):
+			return []string{"env", "toml", "properties"}
+		case assignRe.MatchString(line):
+			return []string{"toml", "ini", "properties"}
+		default:
+			return nil
+		}
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package aconfig
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestSniffFormat(t *testing.T) {
+	type TestConfig struct {
+		Host string
+	}
+
+	f := func(sniff bool) (TestConfig, error) {
+		t.Helper()
+
+		var cfg TestConfig
+		err := LoaderFor(&cfg, Config{
+			NewParser:    newParser,
+			SkipDefaults: true,
+			SkipEnv:      true,
+			SkipFlags:    true,
+			SniffFormat:  sniff,
+			Files:        []string{"etc/app/config"},
+			FileSystem: fstest.MapFS{
+				"etc/app/config": {Data: []byte("\n{\"host\": \"sniffed\"}")},
+			},
+		}).Load()
+		return cfg, err
+	}
+
+	cfg, err := f(true)
+	failIfErr(t, err)
+	mustEqual(t, cfg.Host, "sniffed")
+
+	_, err = f(false)
+	failIfOk(t, err)
+	mustEqual(t, err.Error(), `load config: load files: file format "" is not supported`)
+}
+
+func TestSniffFormatStdin(t *testing.T) {
+	type TestConfig struct {
+		Host string
+	}
+
+	var cfg TestConfig
+	err := LoaderFor(&cfg, Config{
+		NewParser:    newParser,
+		SkipDefaults: true,
+		SkipEnv:      true,
+		SkipFlags:    true,
+		SniffFormat:  true,
+		Files:        []string{"-"},
+		FileDecoders: map[string]FileDecoder{".yaml": yamlStub{}},
+		Stdin:        strings.NewReader(`{"host": "stdin"}`),
+	}).Load()
+	failIfErr(t, err)
+	mustEqual(t, cfg.Host, "stdin")
+}
+
+func TestSniffFormats(t *testing.T) {
+	f := func(content string, want ...string) {
+		t.Helper()
+		mustEqual(t, sniffFormats(content), want)
+	}
+
+	f(`{"a": 1}`, "json")
+	f("# comment\n\n  {\n}", "json")
+	f("---\na: 1", "yaml")
+	f("a: 1\nb: 2", "yaml")
+	f("- a\n", "yaml")
+	f("a:\n  b: 1", "yaml")
+	f("[server]\nport = 80", "toml", "ini")
+	f("[[servers]]\nport = 80", "toml", "ini")
+	f("APP_PORT=80\nAPP_HOST=x", "env", "toml", "properties")
+	f("export APP_PORT=80", "env", "toml", "properties")
+	f("port = 80", "toml", "ini", "properties")
+	f("db.host=localhost", "toml", "ini", "properties")
+	f("")
+	f("\x00\x01binary")
+}
@@ -0,0 +1,279 @@
+package aconfig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+type mapSource struct {
+	name   string
+	format string
+	values map[string]any
+	err    error
+}
+
+func (s *mapSource) Name() string { return s.name }
+
+func (s *mapSource) Format() string { return s.format }
+
+func (s *mapSource) Load(ctx context.Context) (map[string]any, error) {
+	return s.values, s.err
+}
+
+func TestSources(t *testing.T) {
+	type TestConfig struct {
+		HTTPPort int
+		Host     string
+		User     string
+		DB       struct {
+			Name string
+		}
+	}
+
+	var cfg TestConfig
+	loader := LoaderFor(&cfg, Config{
+		NewParser: newParser,
+		SkipFlags: true,
+		EnvPrefix: "APP",
+		Envs:      []string{"APP_USER=env"},
+		Files:     []string{"config.json"},
+		FileSystem: fstest.MapFS{
+			"config.json": {Data: []byte(`{"http_port": 80, "host": "file"}`)},
+		},
+		FileDecoders: map[string]FileDecoder{".yaml": yamlStub{}},
+		Sources: []Source{
+			&mapSource{name: "first", format: "json", values: map[string]any{
+				"host": "first",
+				"user": "first",
+			}},
+			&mapSource{name: "second", format: "yaml", values: map[string]any{
+				"host": "second",
+				"db":   map[string]any{"name": "second"},
+			}},
+		},
+	})
+	failIfErr(t, loader.Load())
+
+	want := TestConfig{HTTPPort: 80, Host: "second", User: "env"}
+	want.DB.Name = "second"
+	mustEqual(t, cfg, want)
+	mustEqual(t, loader.Explain("Host"), "Host: source second (key host)")
+
+	loader = LoaderFor(&TestConfig{}, Config{
+		NewParser: newParser,
+		SkipFiles: true,
+		SkipFlags: true,
+		Envs:      []string{},
+		Sources: []Source{
+			&mapSource{name: "broken", err: errors.New("connection refused")},
+		},
+	})
+	err := loader.Load()
+	failIfOk(t, err)
+	mustEqual(t, err.Error(), "load config: load sources: source broken: connection refused")
+}
+
+type secretSource struct {
+	secrets map[string]string
+}
+
+func (s *secretSource) Name() string { return "vault" }
+
+func (s *secretSource) Load(ctx context.Context) (map[string]any, error) {
+	return nil, nil
+}
+
+func (s *secretSource) FieldTag() string { return "secret" }
+
+func (s *secretSource) LoadField(ctx context.Context, ref string) (any, error) {
+	value, ok := s.secrets[ref]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return value, nil
+}
+
+func TestFieldSource(t *testing.T) {
+	type TestConfig struct {
+		User string
+		DB   struct {
+			Password string `secret:"db#password"`
+			Port     int    `secret:"db#port"`
+		}
+	}
+
+	src := &secretSource{secrets: map[string]string{
+		"db#password": "qwerty",
+		"db#port":     "5432",
+	}}
+
+	var cfg TestConfig
+	loader := LoaderFor(&cfg, Config{
+		NewParser: newParser,
+		SkipFiles: true,
+		SkipFlags: true,
+		Envs:      []string{"USER=env"},
+		Sources:   []Source{src},
+	})
+	failIfErr(t, loader.Load())
+
+	want := TestConfig{User: "env"}
+	want.DB.Password = "qwerty"
+	want.DB.Port = 5432
+	mustEqual(t, cfg, want)
+	mustEqual(t, loader.Explain("DB.Password"), "DB.Password: source vault (key db#password)")
+
+	delete(src.secrets, "db#port")
+	err := LoaderFor(&TestConfig{}, Config{
+		NewParser: newParser,
+		SkipFiles: true,
+		SkipFlags: true,
+		Envs:      []string{},
+		Sources:   []Source{src},
+	}).Load()
+	failIfOk(t, err)
+	mustEqual(t, err.Error(), "load config: load sources: source vault: field DB.Port: not found")
+}
+
+// blockingSource waits for ctx to be done.
+type blockingSource struct{}
+
+func (blockingSource) Load(ctx context.Context) (map[string]any, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestLoadContext(t *testing.T) {
+	type TestConfig struct {
+		Port int `default:"8080"`
+	}
+
+	var cfg TestConfig
+	loader := LoaderFor(&cfg, Config{
+		NewParser: newParser,
+		SkipFiles: true,
+		SkipFlags: true,
+		Sources:   []Source{blockingSource{}},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := loader.LoadContext(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v, want deadline exceeded", err)
+	}
+
+	loader = LoaderFor(&cfg, Config{
+		NewParser: newParser,
+		SkipFiles: true,
+		SkipFlags: true,
+		Sources:   []Source{&mapSource{format: "json", values: map[string]any{"port": 9090}}},
+	})
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	err = loader.LoadContext(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %v, want canceled", err)
+	}
+
+	failIfErr(t, loader.LoadContext(context.Background()))
+	mustEqual(t, cfg.Port, 9090)
+}
+
+// leaseSource returns a new token with TTL on every load.
+type leaseSource struct {
+	mu    sync.Mutex
+	count int
+}
+
+func (s *leaseSource) Load(ctx context.Context) (map[string]any, error) {
+	return nil, nil
+}
+
+func (s *leaseSource) FieldTag() string { return "lease" }
+
+func (s *leaseSource) LoadField(ctx context.Context, ref string) (any, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.count++
+	return Lease{Value: fmt.Sprintf("%s-%d", ref, s.count), TTL: 30 * time.Millisecond}, nil
+}
+
+func TestFieldSourceLease(t *testing.T) {
+	type TestConfig struct {
+		Token string `lease:"token"`
+		Host  string `default:"localhost"`
+	}
+
+	var cfg TestConfig
+	loader := LoaderFor(&cfg, Config{
+		NewParser:     newParser,
+		SkipFiles:     true,
+		SkipFlags:     true,
+		Envs:          []string{},
+		Sources:       []Source{&leaseSource{}},
+		WatchInterval: 5 * time.Millisecond,
+	})
+	failIfErr(t, loader.Load())
+	mustEqual(t, cfg, TestConfig{Token: "token-1", Host: "localhost"})
+
+	// only the leased field is loaded again, so the change survives.
+	cfg.Host = "changed"
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes := make(chan error)
+	go loader.Watch(ctx, func(err error) {
+		select {
+		case changes <- err:
+		case <-ctx.Done():
+		}
+	})
+
+	failIfErr(t, waitChange(t, changes))
+	loader.View(func() {
+		mustEqual(t, cfg, TestConfig{Token: "token-2", Host: "changed"})
+	})
+	failIfErr(t, waitChange(t, changes))
+	loader.View(func() {
+		mustEqual(t, cfg.Token, "token-3")
+	})
+}
+
+func TestFieldSourceLeaseConcurrentLoad(t *testing.T) {
+	type TestConfig struct {
+		Token string `lease:"token"`
+	}
+
+	var cfg TestConfig
+	loader := LoaderFor(&cfg, Config{
+		NewParser:     newParser,
+		SkipFiles:     true,
+		SkipFlags:     true,
+		Envs:          []string{},
+		Sources:       []Source{&leaseSource{}},
+		WatchInterval: time.Millisecond,
+	})
+	failIfErr(t, loader.Load())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		loader.Watch(ctx, nil)
+	}()
+
+	// leases are refreshed by Watch while Load resets them.
+	for i := 0; i < 20; i++ {
+		failIfErr(t, loader.Load())
+		time.Sleep(2 * time.Millisecond)
+	}
+	cancel()
+	<-done
+}
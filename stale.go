@@ -0,0 +1,137 @@
+package aconfig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// SourceHealth is implemented by sources that can report their health. See Loader.Health.
+type SourceHealth interface {
+	Health() error
+}
+
+// Health returns an error of the first unhealthy source from Config.Sources, see SourceHealth.
+// Usually it's used in readiness or liveness checks of a service.
+func (l *Loader) Health() error {
+	// sources are checked without the lock, a slow remote source doesn't block Load.
+	l.stateMu.RLock()
+	sources := append([]Source(nil), l.config.Sources...)
+	l.stateMu.RUnlock()
+
+	for _, src := range sources {
+		h, ok := src.(SourceHealth)
+		if !ok {
+			continue
+		}
+		if err := h.Health(); err != nil {
+			return fmt.Errorf("source %s: %w", sourceName(src), err)
+		}
+	}
+	return nil
+}
+
+// StaleSource is a Source with a staleness budget, usually for a remote source.
+// When the source fails to load, values of its last successful load are used while they are younger than max age.
+// After that values are loaded from the fallback, like a file snapshot, or the load fails.
+// Health reports an error when the last load failed, values are older than max age and there is no fallback.
+// Without reloads values are never stale, so a loaded once config stays healthy.
+//
+// Values of the fallback are matched with the same format as values of the source.
+type StaleSource struct {
+	src      Source
+	maxAge   time.Duration
+	fallback Source
+
+	mu       sync.Mutex
+	values   map[string]any
+	loadedAt time.Time
+	failed   bool
+}
+
+var (
+	_ Source        = (*StaleSource)(nil)
+	_ SourceWatcher = (*StaleSource)(nil)
+	_ SourceHealth  = (*StaleSource)(nil)
+)
+
+// NewStaleSource returns a source that accepts values of src not older than maxAge.
+// fallback can be nil, then the load fails when values are too old.
+func NewStaleSource(src Source, maxAge time.Duration, fallback Source) *StaleSource {
+	return &StaleSource{
+		src:      src,
+		maxAge:   maxAge,
+		fallback: fallback,
+	}
+}
+
+// Name of the wrapped source.
+func (s *StaleSource) Name() string {
+	return sourceName(s.src)
+}
+
+// Format of the wrapped source, "json" if it has no Format method.
+func (s *StaleSource) Format() string {
+	if f, ok := s.src.(interface{ Format() string }); ok {
+		return f.Format()
+	}
+	return "json"
+}
+
+// Load values from the source, falls back to its last values or to the fallback source on failure.
+func (s *StaleSource) Load(ctx context.Context) (map[string]any, error) {
+	values, err := s.src.Load(ctx)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// loader might modify values, so keep a copy.
+	if err == nil {
+		s.values, s.loadedAt, s.failed = copyMap(values), time.Now(), false
+		return values, nil
+	}
+	s.failed = true
+	if s.values != nil && time.Since(s.loadedAt) <= s.maxAge {
+		return copyMap(s.values), nil
+	}
+	if s.fallback == nil {
+		return nil, err
+	}
+
+	values, errFallback := s.fallback.Load(ctx)
+	if errFallback != nil {
+		return nil, fmt.Errorf("%w (fallback %s: %v)", err, sourceName(s.fallback), errFallback)
+	}
+	return values, nil
+}
+
+// Watch the wrapped source if it implements SourceWatcher, otherwise waits for ctx.
+func (s *StaleSource) Watch(ctx context.Context, notify func()) error {
+	if w, ok := s.src.(SourceWatcher); ok {
+		return w.Watch(ctx, notify)
+	}
+	<-ctx.Done()
+	return nil
+}
+
+// Health returns an error when the last load of the source failed,
+// its values are older than max age and there is no fallback.
+func (s *StaleSource) Health() error {
+	if s.fallback != nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.loadedAt.IsZero() {
+		return errors.New("never loaded")
+	}
+	// values are stale only when they can't be refreshed.
+	if age := time.Since(s.loadedAt); s.failed && age > s.maxAge {
+		return fmt.Errorf("values are stale: loaded %s ago, max age is %s", age.Round(time.Millisecond), s.maxAge)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package aconfig
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestStaleSource(t *testing.T) {
+	type TestConfig struct {
+		Port int
+	}
+
+	remote := &mapSource{name: "remote", format: "json", values: map[string]any{"port": 1111}}
+	src := NewStaleSource(remote, 50*time.Millisecond, nil)
+
+	var cfg TestConfig
+	loader := LoaderFor(&cfg, Config{
+		NewParser:    newParser,
+		SkipDefaults: true,
+		SkipFiles:    true,
+		SkipEnv:      true,
+		SkipFlags:    true,
+		Sources:      []Source{src},
+	})
+	failIfOk(t, loader.Health())
+
+	failIfErr(t, loader.Load())
+	mustEqual(t, cfg.Port, 1111)
+	failIfErr(t, loader.Health())
+
+	// remote is down, but values are fresh enough.
+	remote.err = errors.New("connection refused")
+	cfg.Port = 0
+	failIfErr(t, loader.Load())
+	mustEqual(t, cfg.Port, 1111)
+
+	time.Sleep(60 * time.Millisecond)
+	failIfOk(t, loader.Load())
+	failIfOk(t, loader.Health())
+
+	remote.err = nil
+	remote.values = map[string]any{"port": 2222}
+	failIfErr(t, loader.Load())
+	mustEqual(t, cfg.Port, 2222)
+	failIfErr(t, loader.Health())
+}
+
+func TestStaleSourceNoReload(t *testing.T) {
+	type TestConfig struct {
+		Port int
+	}
+
+	remote := &mapSource{name: "remote", format: "json", values: map[string]any{"port": 1111}}
+	src := NewStaleSource(remote, 10*time.Millisecond, nil)
+
+	var cfg TestConfig
+	loader := LoaderFor(&cfg, Config{
+		NewParser:    newParser,
+		SkipDefaults: true,
+		SkipFiles:    true,
+		SkipEnv:      true,
+		SkipFlags:    true,
+		Sources:      []Source{src},
+	})
+	failIfErr(t, loader.Load())
+
+	// values are old, but nothing failed to refresh them.
+	time.Sleep(20 * time.Millisecond)
+	failIfErr(t, loader.Health())
+}
+
+func TestStaleSourceFallback(t *testing.T) {
+	type TestConfig struct {
+		Port int
+	}
+
+	remote := &mapSource{name: "remote", format: "json", err: errors.New("connection refused")}
+	snapshot := &mapSource{name: "snapshot", format: "json", values: map[string]any{"port": 3333}}
+
+	var cfg TestConfig
+	loader := LoaderFor(&cfg, Config{
+		NewParser:    newParser,
+		SkipDefaults: true,
+		SkipFiles:    true,
+		SkipEnv:      true,
+		SkipFlags:    true,
+		Sources:      []Source{NewStaleSource(remote, time.Minute, snapshot)},
+	})
+	failIfErr(t, loader.Load())
+	mustEqual(t, cfg.Port, 3333)
+	failIfErr(t, loader.Health())
+
+	snapshot.err = errors.New("no such file")
+	err := loader.Load()
+	failIfOk(t, err)
+	mustEqual(t, errors.Is(err, remote.err), true)
+}
@@ -0,0 +1,49 @@
+package aconfig
+
+import (
+	"io"
+	"io/fs"
+	"strings"
+	"sync"
+)
+
+// stdinName is a file name in Config.Files or a FileFlag value that means stdin.
+const stdinName = "-"
+
+// stdinFS is a file system with a single file "-" that is read from stdin once.
+// Later opens (like on reload) return the same content.
+type stdinFS struct {
+	r    io.Reader
+	once sync.Once
+	data []byte
+	err  error
+}
+
+func (s *stdinFS) Open(name string) (fs.File, error) {
+	if name != stdinName {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	s.once.Do(func() {
+		s.data, s.err = io.ReadAll(s.r)
+	})
+	if s.err != nil {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: s.err}
+	}
+	return memFS{name: s.data}.Open(name)
+}
+
+// stdinExt returns a file extension for Config.StdinFormat.
+// Default is JSON, or nothing with Config.SniffFormat, so the format is detected.
+func (l *Loader) stdinExt() string {
+	format := strings.ToLower(l.config.StdinFormat)
+	switch {
+	case format == "" && l.config.SniffFormat:
+		return ""
+	case format == "":
+		return ".json"
+	case strings.HasPrefix(format, "."):
+		return format
+	default:
+		return "." + format
+	}
+}
@@ -0,0 +1,64 @@
+package aconfig
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStdinFile(t *testing.T) {
+	type TestConfig struct {
+		Host string
+		Port int
+	}
+
+	var cfg TestConfig
+	loader := LoaderFor(&cfg, Config{
+		NewParser:          newParser,
+		SkipDefaults:       true,
+		SkipEnv:            true,
+		FailOnFileNotFound: true,
+		FileFlag:           "config",
+		Args:               []string{"-config", "-"},
+		Stdin:              strings.NewReader(`{"host": "stdin", "port": 8080}`),
+	})
+	failIfErr(t, loader.Load())
+	mustEqual(t, cfg, TestConfig{Host: "stdin", Port: 8080})
+	mustEqual(t, loader.LoadedFiles(), []string{"-"})
+
+	// stdin is read once, reload gets the same content.
+	failIfErr(t, loader.reload())
+	mustEqual(t, cfg, TestConfig{Host: "stdin", Port: 8080})
+}
+
+func TestStdinFormat(t *testing.T) {
+	type TestConfig struct {
+		Host string
+	}
+
+	var cfg TestConfig
+	loader := LoaderFor(&cfg, Config{
+		NewParser:    newParser,
+		SkipDefaults: true,
+		SkipEnv:      true,
+		SkipFlags:    true,
+		Files:        []string{"-"},
+		StdinFormat:  "lines",
+		FileDecoders: map[string]FileDecoder{".lines": lineDecoder{}},
+		Stdin:        strings.NewReader("host=stdin\n"),
+	})
+	failIfErr(t, loader.Load())
+	mustEqual(t, cfg.Host, "stdin")
+
+	loader = LoaderFor(&cfg, Config{
+		NewParser:    newParser,
+		SkipDefaults: true,
+		SkipEnv:      true,
+		SkipFlags:    true,
+		Files:        []string{"-"},
+		StdinFormat:  "yaml",
+		Stdin:        strings.NewReader("host: stdin\n"),
+	})
+	err := loader.Load()
+	failIfOk(t, err)
+	mustEqual(t, err.Error(), `load config: load files: file format ".yaml" is not supported`)
+}
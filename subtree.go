@@ -0,0 +1,81 @@
+package aconfig
+
+import (
+	"strings"
+)
+
+// LoadInto loads a part of the configuration under the prefix like "redis" into dst,
+// so a library or a plugin can own a section of the application config in its own structure.
+// Files and sources are loaded with the same Config as the loader uses, but only keys under the prefix
+// are taken: "redis.host" (nested or dotted) is "host" for dst. Env vars get the prefix too:
+// with Config.EnvPrefix "APP" field Host of dst is loaded from APP_REDIS_HOST.
+//
+// Flags aren't loaded for dst, use a separate loader and MergeFlagSets for them.
+// The loader itself doesn't know about the section: its structure must have a field for it
+// or unknown fields and envs must be allowed (see AllowUnknownFields and AllowUnknownEnvs).
+func (l *Loader) LoadInto(prefix string, dst any) error {
+	cfg := l.base
+	cfg.SkipFlags = true
+	cfg.SetFlag = ""
+	if cfg.Envs == nil {
+		cfg.Envs = l.config.Envs
+	}
+	if cfg.Args == nil {
+		cfg.Args = l.config.Args
+	}
+
+	envPrefix := strings.ToUpper(strings.ReplaceAll(prefix, ".", "_"))
+	if cfg.EnvPrefix != "" {
+		envPrefix = cfg.EnvPrefix + "_" + envPrefix
+	}
+	cfg.EnvPrefix = envPrefix
+
+	sub := LoaderFor(dst, cfg)
+	sub.subtree = prefix
+	if l.flagSet != nil && l.flagSet.Parsed() {
+		// a file from Config.FileFlag and a profile from Config.ProfileFlag are the same.
+		sub.flagSet = l.flagSet
+	}
+	return sub.Load()
+}
+
+// subtreeValues returns values under the prefix, see LoadInto.
+// Values can be nested maps or have dotted keys like "redis.host", nested maps are merged.
+func subtreeValues(values map[string]any, prefix string) map[string]any {
+	res := map[string]any{}
+	add := func(key string, value any) {
+		if m, ok := asMap(value); ok {
+			value = copyMap(m)
+		}
+		mergeMaps(res, map[string]any{key: value})
+	}
+
+	head, rest, nested := strings.Cut(prefix, ".")
+	for key, value := range values {
+		switch {
+		case key == prefix:
+			if m, ok := asMap(value); ok {
+				for k, v := range m {
+					add(k, v)
+				}
+			}
+		case strings.HasPrefix(key, prefix+"."):
+			add(key[len(prefix)+1:], value)
+		case nested && key == head:
+			if m, ok := asMap(value); ok {
+				for k, v := range subtreeValues(m, rest) {
+					add(k, v)
+				}
+			}
+		}
+	}
+	return res
+}
+
+// applySubtree returns values for the loader, only under the prefix for LoadInto.
+func (l *Loader) applySubtree(values map[string]any) map[string]any {
+	if l.subtree == "" {
+		return values
+	}
+	return subtreeValues(values, l.subtree)
+}
@@ -0,0 +1,71 @@
+package aconfig
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadInto(t *testing.T) {
+	type AppConfig struct {
+		Port int `default:"8080"`
+	}
+	type RedisConfig struct {
+		Host string `default:"localhost"`
+		DB   int
+		Pool struct {
+			Size int `default:"10"`
+		}
+	}
+
+	fsys := fstest.MapFS{
+		"config.json": {Data: []byte(`{"port": 9090, "plugins": {"redis": {"host": "redis.local", "pool": {"size": 20}}}}`)},
+		"flat.json":   {Data: []byte(`{"plugins.redis.db": 3}`)},
+	}
+
+	var app AppConfig
+	loader := LoaderFor(&app, Config{
+		NewParser:          newParser,
+		FileSystem:         fsys,
+		Files:              []string{"config.json", "flat.json"},
+		MergeFiles:         true,
+		EnvPrefix:          "APP",
+		Envs:               []string{"APP_PLUGINS_REDIS_POOL_SIZE=30"},
+		Args:               []string{},
+		AllowUnknownFields: true,
+		AllowUnknownEnvs:   true,
+	})
+	failIfErr(t, loader.Load())
+	mustEqual(t, app.Port, 9090)
+
+	var redis RedisConfig
+	failIfErr(t, loader.LoadInto("plugins.redis", &redis))
+
+	want := RedisConfig{Host: "redis.local", DB: 3}
+	want.Pool.Size = 30
+	mustEqual(t, redis, want)
+
+	// unknown keys under the prefix fail as usual.
+	loader = LoaderFor(&app, Config{
+		NewParser:  newParser,
+		SkipFlags:  true,
+		FileSystem: fstest.MapFS{"config.json": {Data: []byte(`{"redis": {"nope": 1}}`)}},
+		Files:      []string{"config.json"},
+	})
+	var strict struct{ Host string }
+	failIfOk(t, loader.LoadInto("redis", &strict))
+}
+
+func TestSubtreeValues(t *testing.T) {
+	values := map[string]any{
+		"a":       map[string]any{"b": map[string]any{"c": 1}, "d": 2},
+		"a.b.e":   3,
+		"a.bc":    4,
+		"other":   5,
+		"a.b":     map[string]any{"f": 6},
+		"ab.b.zz": 7,
+	}
+	mustEqual(t, subtreeValues(values, "a.b"), map[string]any{"c": 1, "e": 3, "f": 6})
+	mustEqual(t, subtreeValues(values, "a"), map[string]any{
+		"b": map[string]any{"c": 1, "f": 6}, "d": 2, "b.e": 3, "bc": 4,
+	})
+}
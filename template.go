@@ -0,0 +1,68 @@
+package aconfig
+
+import (
+	"reflect"
+)
+
+// LoaderTemplate parses a configuration structure once and creates loaders that share its metadata.
+// Useful to load many configurations of the same structure, like one per tenant.
+// With Config.NewParser every loader parses the structure as usual.
+type LoaderTemplate struct {
+	config Config
+	typ    reflect.Type
+	proto  *Loader
+}
+
+// LoaderInputs are settings of a single loader created by LoaderTemplate.
+// Nil fields are taken from the template config.
+type LoaderInputs struct {
+	Files []string
+	Envs  []string
+	Args  []string
+}
+
+// NewLoaderTemplate creates a template for structures of the same type as dst.
+// Only the type of dst is used, dst isn't modified.
+func NewLoaderTemplate(dst any, cfg Config) *LoaderTemplate {
+	assertStruct(dst)
+
+	typ := reflect.TypeOf(dst).Elem()
+	return &LoaderTemplate{
+		config: cfg,
+		typ:    typ,
+		proto:  LoaderFor(reflect.New(typ).Interface(), cfg),
+	}
+}
+
+// LoaderFor creates a new Loader for dst with the template config and the given inputs.
+// Destination must have the same type as the template structure.
+// Safe for concurrent use.
+func (t *LoaderTemplate) LoaderFor(dst any, in LoaderInputs) *Loader {
+	assertStruct(dst)
+	if typ := reflect.TypeOf(dst).Elem(); typ != t.typ {
+		panic("aconfig: destination must be " + t.typ.String() + ", got " + typ.String())
+	}
+
+	cfg := t.config
+	if in.Files != nil {
+		cfg.Files = in.Files
+	}
+	if in.Envs != nil {
+		cfg.Envs = in.Envs
+	}
+	if in.Args != nil {
+		cfg.Args = in.Args
+	}
+
+	l := &Loader{
+		dst:    dst,
+		config: cfg,
+		base:   cfg,
+	}
+	if !cfg.NewParser {
+		// reuse fields metadata, only values are new.
+		l.fields = t.proto.rebindFields(dst)
+	}
+	l.init()
+	return l
+}
@@ -0,0 +1,87 @@
+package aconfig
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoaderTemplate(t *testing.T) {
+	type TestConfig struct {
+		Name  string `default:"none"`
+		Port  int    `default:"8080"`
+		Debug bool
+	}
+
+	fsys := fstest.MapFS{
+		"a.json": &fstest.MapFile{Data: []byte(`{"name": "a", "port": 1}`)},
+		"b.json": &fstest.MapFile{Data: []byte(`{"name": "b"}`)},
+	}
+	tmpl := NewLoaderTemplate(&TestConfig{}, Config{
+		NewParser:  newParser,
+		EnvPrefix:  "APP",
+		FileSystem: fsys,
+		Envs:       []string{},
+		Args:       []string{},
+	})
+
+	var cfgA, cfgB, cfgC TestConfig
+	failIfErr(t, tmpl.LoaderFor(&cfgA, LoaderInputs{Files: []string{"a.json"}}).Load())
+	failIfErr(t, tmpl.LoaderFor(&cfgB, LoaderInputs{
+		Files: []string{"b.json"},
+		Envs:  []string{"APP_PORT=2"},
+		Args:  []string{"-debug=true"},
+	}).Load())
+	failIfErr(t, tmpl.LoaderFor(&cfgC, LoaderInputs{}).Load())
+
+	mustEqual(t, cfgA, TestConfig{Name: "a", Port: 1})
+	mustEqual(t, cfgB, TestConfig{Name: "b", Port: 2, Debug: true})
+	mustEqual(t, cfgC, TestConfig{Name: "none", Port: 8080})
+}
+
+func TestLoaderTemplateConcurrent(t *testing.T) {
+	type TestConfig struct {
+		Tenant string
+		Limit  int
+	}
+
+	tmpl := NewLoaderTemplate(&TestConfig{}, Config{
+		NewParser:    newParser,
+		SkipDefaults: true,
+		SkipFiles:    true,
+		SkipFlags:    true,
+	})
+
+	var wg sync.WaitGroup
+	cfgs := make([]TestConfig, 20)
+	errs := make([]error, len(cfgs))
+	for i := range cfgs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			envs := []string{fmt.Sprintf("TENANT=t%d", i), fmt.Sprintf("LIMIT=%d", i)}
+			errs[i] = tmpl.LoaderFor(&cfgs[i], LoaderInputs{Envs: envs}).Load()
+		}(i)
+	}
+	wg.Wait()
+
+	for i, cfg := range cfgs {
+		failIfErr(t, errs[i])
+		mustEqual(t, cfg.Tenant, fmt.Sprintf("t%d", i))
+		mustEqual(t, cfg.Limit, i)
+	}
+}
+
+func TestLoaderTemplateWrongType(t *testing.T) {
+	type TestConfig struct{ Name string }
+	type OtherConfig struct{ Name string }
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatal("must panic")
+		}
+	}()
+	tmpl := NewLoaderTemplate(&TestConfig{}, Config{})
+	tmpl.LoaderFor(&OtherConfig{}, LoaderInputs{})
+}
@@ -0,0 +1,30 @@
+package aconfig
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// LoaderForT creates a new Loader for a new value of type T and returns both.
+// Unlike LoaderFor it doesn't panic: if T isn't a struct, Load returns an error.
+func LoaderForT[T any](cfg Config) (*T, *Loader) {
+	dst := new(T)
+	if typ := reflect.TypeOf(dst).Elem(); typ.Kind() != reflect.Struct {
+		return dst, &Loader{
+			dst:     dst,
+			config:  cfg,
+			base:    cfg,
+			errInit: fmt.Errorf("destination must be struct, got %s", typ),
+		}
+	}
+	return dst, LoaderFor(dst, cfg)
+}
+
+// LoadFor loads configuration of type T. See LoaderForT.
+func LoadFor[T any](cfg Config) (*T, error) {
+	dst, loader := LoaderForT[T](cfg)
+	if err := loader.Load(); err != nil {
+		return nil, err
+	}
+	return dst, nil
+}
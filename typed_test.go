@@ -0,0 +1,42 @@
+package aconfig
+
+import (
+	"testing"
+)
+
+func TestLoadFor(t *testing.T) {
+	type TestConfig struct {
+		Port int    `default:"8080"`
+		Host string `default:"localhost"`
+	}
+
+	cfg, err := LoadFor[TestConfig](Config{
+		NewParser: newParser,
+		SkipFiles: true,
+		SkipFlags: true,
+		Envs:      []string{"HOST=example.com"},
+	})
+	failIfErr(t, err)
+	mustEqual(t, *cfg, TestConfig{Port: 8080, Host: "example.com"})
+
+	dst, loader := LoaderForT[TestConfig](Config{
+		NewParser: newParser,
+		SkipFiles: true,
+		SkipFlags: true,
+		Envs:      []string{"PORT=9090"},
+	})
+	failIfErr(t, loader.Load())
+	mustEqual(t, dst.Port, 9090)
+
+	_, err = LoadFor[int](Config{})
+	failIfOk(t, err)
+	mustEqual(t, err.Error(), "init loader: destination must be struct, got int")
+
+	_, err = LoadFor[TestConfig](Config{
+		NewParser: newParser,
+		SkipFiles: true,
+		SkipFlags: true,
+		Envs:      []string{"PORT=abc"},
+	})
+	failIfOk(t, err)
+}
@@ -4,10 +4,14 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
 	"io/fs"
 	"os"
 	"reflect"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 	"unicode"
 )
 
@@ -80,6 +84,9 @@ func (l *Loader) makeTagValue(field reflect.StructField, tag string, words []str
 		}
 	}
 
+	if tag == "flag" {
+		return l.config.FlagStyle.join(words)
+	}
 	name := strings.Join(words, "_")
 	if tag == "env" {
 		return strings.ToUpper(name)
@@ -131,6 +138,164 @@ func splitNameByWords(src string) []string {
 	return words
 }
 
+var durationUnits = map[string]time.Duration{
+	"ns": time.Nanosecond,
+	"us": time.Microsecond,
+	"ms": time.Millisecond,
+	"s":  time.Second,
+	"m":  time.Minute,
+	"h":  time.Hour,
+}
+
+// parseDuration parses duration like time.ParseDuration, plain numbers are treated
+// as a number of units from `unit` tag (ns, us, ms, s, m or h) when the unit is set.
+func parseDuration(value, unit string) (time.Duration, error) {
+	if unit == "" {
+		return time.ParseDuration(value)
+	}
+	mult, ok := durationUnits[unit]
+	if !ok {
+		return 0, fmt.Errorf("unknown duration unit %q", unit)
+	}
+
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return time.ParseDuration(value)
+	}
+	return time.Duration(n * float64(mult)), nil
+}
+
+// parseTime parses time with the layout from `layout` tag, time.RFC3339 is used when the layout is empty.
+func parseTime(value, layout string) (time.Time, error) {
+	if layout == "" {
+		layout = time.RFC3339
+	}
+	return time.Parse(layout, value)
+}
+
+// isIgnored reports whether the field has `aconfig:"-"` tag and must be skipped by the loader.
+func isIgnored(field reflect.StructField) bool {
+	name, _ := parseAconfigTag(field.Tag.Get("aconfig"))
+	return name == "-"
+}
+
+// defaultTag returns `default.<env>` tag of the field if env is set and the field has it,
+// otherwise `default` tag.
+func defaultTag(field reflect.StructField, env string) string {
+	if env != "" {
+		if def, ok := field.Tag.Lookup("default." + env); ok {
+			return def
+		}
+	}
+	return field.Tag.Get("default")
+}
+
+// parseAconfigTag splits `aconfig` tag into a name and a set of options.
+// Example: `aconfig:",allowempty"` gives "" and {"allowempty": true}.
+func parseAconfigTag(tag string) (string, map[string]bool) {
+	name, rest, _ := cut(tag, ",")
+	opts := map[string]bool{}
+	for rest != "" {
+		var opt string
+		opt, rest, _ = cut(rest, ",")
+		if opt = strings.TrimSpace(opt); opt != "" {
+			opts[opt] = true
+		}
+	}
+	return name, opts
+}
+
+// profilesKey is a top-level key in config files with named profiles, see Config.Profile.
+const profilesKey = "profiles"
+
+// mergeMaps merges src into dst recursively, values from src win.
+func mergeMaps(dst, src map[string]interface{}) map[string]interface{} {
+	for k, v := range src {
+		if sub, ok := asMap(v); ok {
+			if curr, ok := asMap(dst[k]); ok {
+				dst[k] = mergeMaps(curr, sub)
+				continue
+			}
+		}
+		dst[k] = v
+	}
+	return dst
+}
+
+// asMap returns nested values as map[string]interface{} if they are a map.
+func asMap(v interface{}) (map[string]interface{}, bool) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		return v, true
+	case map[interface{}]interface{}:
+		return mii(v), true
+	default:
+		return nil, false
+	}
+}
+
+// copyMap returns a copy of the map with nested maps copied too.
+func copyMap(m map[string]interface{}) map[string]interface{} {
+	res := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		if sub, ok := asMap(v); ok {
+			v = copyMap(sub)
+		}
+		res[k] = v
+	}
+	return res
+}
+
+// flattenKeys returns all the keys of nested maps joined with a dot.
+func flattenKeys(prefix string, m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k, v := range m {
+		key := prefix + k
+		switch v := v.(type) {
+		case map[string]interface{}:
+			keys = append(keys, flattenKeys(key+".", v)...)
+		case map[interface{}]interface{}:
+			keys = append(keys, flattenKeys(key+".", mii(v))...)
+		default:
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// levenshtein distance between 2 strings.
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min3(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
+
 // copy-paste until https://github.com/golang/go/issues/46336 is fixed
 // returns: before, after, isFound
 func cut(s, sep string) (_, _ string, _ bool) {
@@ -152,7 +317,8 @@ func (f *fsOrOS) Open(name string) (fs.File, error) {
 }
 
 type jsonDecoder struct {
-	fsys fs.FS
+	fsys   fs.FS
+	stream bool
 }
 
 func (d *jsonDecoder) Init(fsys fs.FS) {
@@ -164,6 +330,15 @@ func (d *jsonDecoder) Format() string {
 	return "json"
 }
 
+// Encode implements FileEncoder.
+func (d *jsonDecoder) Encode(w io.Writer, values map[string]any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	// values are written as is, the output isn't embedded into HTML.
+	enc.SetEscapeHTML(false)
+	return enc.Encode(values)
+}
+
 // DecodeFile implements FileDecoder.
 func (d *jsonDecoder) DecodeFile(filename string) (map[string]interface{}, error) {
 	f, err := d.fsys.Open(filename)
@@ -172,13 +347,95 @@ func (d *jsonDecoder) DecodeFile(filename string) (map[string]interface{}, error
 	}
 	defer f.Close()
 
+	return d.DecodeReader(f)
+}
+
+// DecodeReader implements ReaderDecoder.
+func (d *jsonDecoder) DecodeReader(r io.Reader) (map[string]interface{}, error) {
+	if d.stream {
+		return decodeJSONStream(json.NewDecoder(r))
+	}
+
 	var raw map[string]interface{}
-	if err := json.NewDecoder(f).Decode(&raw); err != nil {
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
 		return nil, err
 	}
 	return raw, nil
 }
 
+// decodeJSONStream decodes JSON object token by token.
+// Unlike json.Decoder.Decode it doesn't buffer the whole document before decoding,
+// but the result is still a complete map of the document.
+func decodeJSONStream(dec *json.Decoder) (map[string]interface{}, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '{' {
+		return nil, fmt.Errorf("expected JSON object, got %v", tok)
+	}
+	return decodeJSONObject(dec)
+}
+
+func decodeJSONValue(dec *json.Decoder) (interface{}, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch tok {
+	case json.Delim('{'):
+		return decodeJSONObject(dec)
+	case json.Delim('['):
+		return decodeJSONArray(dec)
+	default:
+		return tok, nil
+	}
+}
+
+func decodeJSONObject(dec *json.Decoder) (map[string]interface{}, error) {
+	res := map[string]interface{}{}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, ok := tok.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected JSON object key, got %v", tok)
+		}
+
+		value, err := decodeJSONValue(dec)
+		if err != nil {
+			return nil, err
+		}
+		res[key] = value
+	}
+
+	// closing '}'
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func decodeJSONArray(dec *json.Decoder) ([]interface{}, error) {
+	res := []interface{}{}
+	for dec.More() {
+		value, err := decodeJSONValue(dec)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, value)
+	}
+
+	// closing ']'
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
 func (l *Loader) sliceToString(curr interface{}) string {
 	switch curr := curr.(type) {
 	case []interface{}:
@@ -33,3 +33,28 @@ func Test_splitNameByWords(t *testing.T) {
 		})
 	}
 }
+
+func Test_levenshtein(t *testing.T) {
+	f := func(a, b string, want int) {
+		t.Helper()
+		if got := levenshtein(a, b); got != want {
+			t.Errorf("levenshtein(%q, %q) = %v, want %v", a, b, got, want)
+		}
+	}
+
+	f("", "", 0)
+	f("abc", "", 3)
+	f("", "abc", 3)
+	f("HOST", "HOST", 0)
+	f("HSOT", "HOST", 2)
+	f("kitten", "sitting", 3)
+	f("∆x", "∆y", 1)
+}
+
+func Test_flattenKeys(t *testing.T) {
+	m := map[string]interface{}{
+		"a": map[interface{}]interface{}{1: "x", "b": true},
+		"c": "d",
+	}
+	mustEqual(t, flattenKeys("", m), []string{"a.1", "a.b", "c"})
+}
@@ -0,0 +1,121 @@
+package aconfig
+
+import (
+	"reflect"
+	"sync/atomic"
+)
+
+// Value holds a configuration of type T that is safe to read from any goroutine while it's reloaded.
+// Every successful Load, reload by Loader.Watch or Loader.ReloadOn and lease refresh
+// stores a new copy of the configuration, the previous one is never changed.
+// Load copies nested pointers, slices and maps too, so they aren't shared with the destination.
+//
+//	cfg := aconfig.NewValue[MyConfig](aconfig.Config{Files: []string{"config.yaml"}})
+//	if err := cfg.Loader().Load(); err != nil { ... }
+//	go cfg.Loader().Watch(ctx, nil)
+//	...
+//	port := cfg.Get().Port
+//
+// Structure returned by Get must not be modified.
+type Value[T any] struct {
+	v      atomic.Value // of *T
+	loader *Loader
+}
+
+// valueHolder is implemented by Value, loader stores configurations into it.
+type valueHolder interface {
+	store(cfg any)
+}
+
+// NewValue returns a Value with a loader for T configured with cfg.
+func NewValue[T any](cfg Config) *Value[T] {
+	v := &Value[T]{}
+	_, v.loader = LoaderForT[T](cfg)
+	v.loader.holder = v
+	v.v.Store(new(T))
+	return v
+}
+
+// Loader returns the loader of the value.
+func (v *Value[T]) Loader() *Loader {
+	return v.loader
+}
+
+// Get returns the current configuration or zero value before the first load.
+func (v *Value[T]) Get() *T {
+	return v.v.Load().(*T)
+}
+
+func (v *Value[T]) store(cfg any) {
+	v.v.Store(cfg.(*T))
+}
+
+// publish stores a copy of the destination into Value. See NewValue.
+// cfg is a pointer to a configuration that isn't changed by the loader afterwards.
+func (l *Loader) publish(cfg reflect.Value) {
+	if l.holder == nil {
+		return
+	}
+	if cfg.Pointer() == reflect.ValueOf(l.dst).Pointer() {
+		// the destination is changed in place by the next Load.
+		cfg = deepCopy(cfg)
+	}
+	l.holder.store(cfg.Interface())
+}
+
+// deepCopy returns a copy of the value, pointers, slices and maps are copied too.
+// Unexported fields are copied as is.
+func deepCopy(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.New(v.Type().Elem())
+		copied.Elem().Set(deepCopy(v.Elem()))
+		return copied
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.New(v.Type()).Elem()
+		copied.Set(deepCopy(v.Elem()))
+		return copied
+	case reflect.Struct:
+		copied := reflect.New(v.Type()).Elem()
+		copied.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if copied.Field(i).CanSet() {
+				copied.Field(i).Set(deepCopy(v.Field(i)))
+			}
+		}
+		return copied
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			copied.Index(i).Set(deepCopy(v.Index(i)))
+		}
+		return copied
+	case reflect.Array:
+		copied := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			copied.Index(i).Set(deepCopy(v.Index(i)))
+		}
+		return copied
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			copied.SetMapIndex(iter.Key(), deepCopy(iter.Value()))
+		}
+		return copied
+	default:
+		return v
+	}
+}
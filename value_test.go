@@ -0,0 +1,112 @@
+package aconfig
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestValue(t *testing.T) {
+	type TestConfig struct {
+		Port int `default:"8080"`
+		Sub  *struct {
+			Name string
+		}
+	}
+
+	file := filepath.Join(t.TempDir(), "config.json")
+	writeFile(t, file, `{"port": 1111, "sub": {"name": "first"}}`)
+
+	cfg := NewValue[TestConfig](Config{
+		NewParser:     newParser,
+		SkipEnv:       true,
+		SkipFlags:     true,
+		Files:         []string{file},
+		WatchInterval: 10 * time.Millisecond,
+	})
+	mustEqual(t, *cfg.Get(), TestConfig{})
+
+	failIfErr(t, cfg.Loader().Load())
+	first := cfg.Get()
+	mustEqual(t, first.Port, 1111)
+	mustEqual(t, first.Sub.Name, "first")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	changes := make(chan error, 1)
+	go cfg.Loader().Watch(ctx, func(err error) {
+		changes <- err
+	})
+
+	// readers never race with reloads.
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for ctx.Err() == nil {
+			if c := cfg.Get(); c.Port == 0 || c.Sub == nil {
+				t.Error("partial config")
+				return
+			}
+		}
+	}()
+	defer wg.Wait()
+	defer cancel()
+
+	// let the watcher take the first snapshot.
+	time.Sleep(50 * time.Millisecond)
+
+	writeFile(t, file, `{"port": 2222, "sub": {"name": "second"}}`)
+	failIfErr(t, waitChange(t, changes))
+
+	mustEqual(t, cfg.Get().Port, 2222)
+	mustEqual(t, cfg.Get().Sub.Name, "second")
+	mustEqual(t, first.Port, 1111)
+	mustEqual(t, first.Sub.Name, "first")
+}
+
+func TestValueLoadAgain(t *testing.T) {
+	type Nested struct {
+		Name string
+		Tags []string
+	}
+	type TestConfig struct {
+		Sub *Nested
+	}
+
+	file := filepath.Join(t.TempDir(), "config.json")
+	writeFile(t, file, `{"sub": {"name": "first", "tags": ["a"]}}`)
+
+	cfg := NewValue[TestConfig](Config{
+		NewParser: newParser,
+		SkipEnv:   true,
+		SkipFlags: true,
+		Files:     []string{file},
+	})
+	failIfErr(t, cfg.Loader().Load())
+	first := cfg.Get()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for ctx.Err() == nil {
+			if first.Sub.Name != "first" || first.Sub.Tags[0] != "a" {
+				t.Error("snapshot changed")
+				return
+			}
+		}
+	}()
+
+	writeFile(t, file, `{"sub": {"name": "second", "tags": ["b"]}}`)
+	failIfErr(t, cfg.Loader().Load())
+	cancel()
+	wg.Wait()
+
+	mustEqual(t, *first.Sub, Nested{Name: "first", Tags: []string{"a"}})
+	mustEqual(t, *cfg.Get().Sub, Nested{Name: "second", Tags: []string{"b"}})
+}
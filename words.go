@@ -0,0 +1,144 @@
+package aconfig
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// WordSplit configures how field names are split into words for generated env, flag and file names.
+// Words are joined with "_": APIKey gives API_KEY env var and api_key flag. See Config.WordSplit.
+// Zero value splits names like before: by case changes and digits.
+type WordSplit struct {
+	// SplitAcronyms splits acronyms into letters: HTTPServer gives H_T_T_P_SERVER instead of HTTP_SERVER.
+	SplitAcronyms bool
+
+	// JoinDigits keeps digits with the previous word: Type2 gives TYPE2 instead of TYPE_2.
+	JoinDigits bool
+
+	// Initialisms are kept as single words even if they mix cases or have digits,
+	// like "OAuth" or "IPv4": OAuthToken gives OAUTH_TOKEN instead of O_AUTH_TOKEN.
+	// Matched with case, an initialism must not be followed by a lower case letter.
+	Initialisms []string
+}
+
+// Split returns words of the name.
+func (w WordSplit) Split(name string) []string {
+	if !w.SplitAcronyms && !w.JoinDigits && len(w.Initialisms) == 0 {
+		return splitNameByWords(name)
+	}
+
+	// longest initialisms first, so IPv4 wins over IP.
+	initialisms := append([]string{}, w.Initialisms...)
+	sort.Slice(initialisms, func(i, j int) bool {
+		return len(initialisms[i]) > len(initialisms[j])
+	})
+
+	words := []string{}
+	fixed := map[int]bool{} // indexes of initialisms in words.
+	start := 0
+	for i := 0; i < len(name); {
+		initialism := matchInitialism(name[i:], initialisms)
+		if initialism == "" {
+			_, size := utf8.DecodeRuneInString(name[i:])
+			i += size
+			continue
+		}
+		words = append(words, w.splitPart(name[start:i])...)
+		fixed[len(words)] = true
+		words = append(words, initialism)
+		i += len(initialism)
+		start = i
+	}
+	words = append(words, w.splitPart(name[start:])...)
+
+	if !w.JoinDigits {
+		return words
+	}
+	res := make([]string, 0, len(words))
+	for i, word := range words {
+		if i > 0 && !fixed[i] && isDigits(word) {
+			res[len(res)-1] += word
+			continue
+		}
+		res = append(res, word)
+	}
+	return res
+}
+
+func (w WordSplit) splitPart(part string) []string {
+	words := splitNameByWords(part)
+	if !w.SplitAcronyms {
+		return words
+	}
+	res := make([]string, 0, len(words))
+	for _, word := range words {
+		if utf8.RuneCountInString(word) > 1 && strings.ToUpper(word) == word && !isDigits(word) {
+			for _, r := range word {
+				res = append(res, string(r))
+			}
+			continue
+		}
+		res = append(res, word)
+	}
+	return res
+}
+
+// matchInitialism returns an initialism that s starts with and which isn't followed by a lower case letter.
+func matchInitialism(s string, initialisms []string) string {
+	for _, initialism := range initialisms {
+		if initialism == "" || !strings.HasPrefix(s, initialism) {
+			continue
+		}
+		next, _ := utf8.DecodeRuneInString(s[len(initialism):])
+		if !unicode.IsLower(next) {
+			return initialism
+		}
+	}
+	return ""
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// FlagStyle is a casing of generated flag names. See Config.FlagStyle.
+type FlagStyle int
+
+const (
+	// FlagSnake joins words with "_": max_conns. Default.
+	FlagSnake FlagStyle = iota
+
+	// FlagKebab joins words with "-": max-conns.
+	FlagKebab
+
+	// FlagCamel gives lowerCamel names: maxConns, nested names are dotted like db.maxConns.
+	FlagCamel
+)
+
+// join returns a flag name from the words of a field name.
+func (s FlagStyle) join(words []string) string {
+	switch s {
+	case FlagKebab:
+		return strings.ToLower(strings.Join(words, "-"))
+	case FlagCamel:
+		var sb strings.Builder
+		for i, word := range words {
+			word = strings.ToLower(word)
+			if i > 0 {
+				r, size := utf8.DecodeRuneInString(word)
+				word = string(unicode.ToUpper(r)) + word[size:]
+			}
+			sb.WriteString(word)
+		}
+		return sb.String()
+	default:
+		return strings.ToLower(strings.Join(words, "_"))
+	}
+}
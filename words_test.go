@@ -0,0 +1,102 @@
+package aconfig
+
+import (
+	"testing"
+)
+
+func TestWordSplit(t *testing.T) {
+	f := func(w WordSplit, name string, want []string) {
+		t.Helper()
+		mustEqual(t, w.Split(name), want)
+	}
+
+	f(WordSplit{}, "HTTPServer", []string{"HTTP", "Server"})
+	f(WordSplit{}, "OAuth2Token", []string{"O", "Auth", "2", "Token"})
+
+	f(WordSplit{SplitAcronyms: true}, "HTTPServer", []string{"H", "T", "T", "P", "Server"})
+	f(WordSplit{SplitAcronyms: true}, "Port8080", []string{"Port", "8080"})
+
+	f(WordSplit{JoinDigits: true}, "Type2", []string{"Type2"})
+	f(WordSplit{JoinDigits: true}, "S3Bucket", []string{"S3", "Bucket"})
+	f(WordSplit{JoinDigits: true}, "2FA", []string{"2", "FA"})
+
+	initialisms := WordSplit{Initialisms: []string{"OAuth", "IPv4", "IP", "gRPC"}}
+	f(initialisms, "OAuth2Token", []string{"OAuth", "2", "Token"})
+	f(initialisms, "ServerIPv4Addr", []string{"Server", "IPv4", "Addr"})
+	f(initialisms, "IPAddr", []string{"IP", "Addr"})
+	f(initialisms, "gRPCPort", []string{"gRPC", "Port"})
+	// followed by a lower case letter, so it's not the initialism.
+	f(initialisms, "IPhone", []string{"I", "Phone"})
+
+	f(WordSplit{JoinDigits: true, Initialisms: []string{"OAuth"}}, "OAuth2Token", []string{"OAuth2", "Token"})
+}
+
+func TestWordSplitNames(t *testing.T) {
+	type TestConfig struct {
+		OAuth2Token string
+		HTTPPort    int
+	}
+
+	var cfg TestConfig
+	loader := LoaderFor(&cfg, Config{
+		NewParser:    newParser,
+		SkipDefaults: true,
+		SkipFiles:    true,
+		SkipFlags:    true,
+		EnvPrefix:    "APP",
+		Envs:         []string{"APP_OAUTH2_TOKEN=token", "APP_H_T_T_P_PORT=8080"},
+		WordSplit: WordSplit{
+			SplitAcronyms: true,
+			JoinDigits:    true,
+			Initialisms:   []string{"OAuth"},
+		},
+	})
+	failIfErr(t, loader.Load())
+	mustEqual(t, cfg, TestConfig{OAuth2Token: "token", HTTPPort: 8080})
+
+	cfgNames := Config{EnvPrefix: "APP", WordSplit: WordSplit{Initialisms: []string{"OAuth"}}}
+	mustEqual(t, EnvNameFor(cfgNames, "OAuth2Token"), "APP_OAUTH_2_TOKEN")
+}
+
+func TestFlagStyle(t *testing.T) {
+	type TestConfig struct {
+		MaxConns int
+		DB       struct {
+			UserName string
+		}
+		Debug bool `flag:"verbose_debug"`
+	}
+
+	f := func(style FlagStyle, args []string, wantNames []string) {
+		t.Helper()
+		var cfg TestConfig
+		loader := LoaderFor(&cfg, Config{
+			NewParser:    newParser,
+			SkipDefaults: true,
+			SkipFiles:    true,
+			SkipEnv:      true,
+			FlagStyle:    style,
+			Args:         args,
+		})
+		failIfErr(t, loader.Load())
+		mustEqual(t, cfg.MaxConns, 10)
+		mustEqual(t, cfg.DB.UserName, "root")
+		mustEqual(t, cfg.Debug, true)
+
+		for _, name := range wantNames {
+			if loader.Flags().Lookup(name) == nil {
+				t.Fatalf("flag %q isn't defined", name)
+			}
+		}
+	}
+
+	f(FlagSnake, []string{"-max_conns=10", "-db.user_name=root", "-verbose_debug=true"},
+		[]string{"db.user_name", "max_conns", "verbose_debug"})
+	f(FlagKebab, []string{"--max-conns=10", "--db.user-name=root", "-verbose_debug=true"},
+		[]string{"db.user-name", "max-conns", "verbose_debug"})
+	f(FlagCamel, []string{"-maxConns=10", "-db.userName=root", "-verbose_debug=true"},
+		[]string{"db.userName", "maxConns", "verbose_debug"})
+
+	cfg := Config{FlagPrefix: "app", FlagStyle: FlagKebab}
+	mustEqual(t, FlagNameFor(cfg, "DB.UserName"), "app.db.user-name")
+}